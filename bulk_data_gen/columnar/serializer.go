@@ -0,0 +1,339 @@
+// Package columnar writes generated points into Apache Parquet or Arrow IPC
+// files. It is kept apart from the common package so that only the commands
+// emitting these formats depend on the Arrow libraries.
+package columnar
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/compress"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
+)
+
+const (
+	FormatParquet  = "parquet"
+	FormatArrowIPC = "arrow-ipc"
+)
+
+// FormatChoices lists the columnar output data formats.
+var FormatChoices = []string{FormatParquet, FormatArrowIPC}
+
+// DefaultRowGroupSize is the default number of rows buffered per measurement
+// before they are written out as one row group (or one IPC record batch).
+const DefaultRowGroupSize = 100000
+
+var timestampType = &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}
+
+// recordWriter is implemented by both pqarrow.FileWriter and ipc.Writer.
+type recordWriter interface {
+	Write(rec arrow.Record) error
+	Close() error
+}
+
+type columnarTable struct {
+	tagKeys   []string
+	fieldKeys []string
+	columns   map[string]int // of the tag and field keys
+	schema    *arrow.Schema
+	builder   *array.RecordBuilder
+	rows      int
+	file      *os.File
+	writer    recordWriter
+}
+
+// Serializer buffers points per measurement and writes them into
+// typed columnar files, one file per measurement, into an output directory.
+// Each measurement gets a `time` column, a string column per tag and
+// a typed column per field. Schema of a measurement is fixed by the first
+// point seen, a later point with a tag or field missing from it, or with
+// a field of another type, is rejected with an error. Tags and fields missing
+// from a point are written as nulls.
+//
+// Nothing is written to the writer passed to SerializePoint.
+type Serializer struct {
+	format       string
+	dir          string
+	rowGroupSize int
+	alloc        memory.Allocator
+	tables       map[string]*columnarTable
+	order        []string
+}
+
+// NewSerializerForFormat creates the serializer for the given columnar format
+// name, writing its files into dir.
+func NewSerializerForFormat(format, dir string, rowGroupSize int) (*Serializer, error) {
+	switch format {
+	case FormatParquet:
+		return NewSerializerParquet(dir, rowGroupSize), nil
+	case FormatArrowIPC:
+		return NewSerializerArrowIPC(dir, rowGroupSize), nil
+	default:
+		return nil, fmt.Errorf("invalid columnar format specifier: %v", format)
+	}
+}
+
+// IsFormat reports whether format is one of the columnar formats.
+func IsFormat(format string) bool {
+	for _, f := range FormatChoices {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSerializerParquet creates a serializer writing <measurement>.parquet
+// files split into row groups of rowGroupSize rows.
+func NewSerializerParquet(dir string, rowGroupSize int) *Serializer {
+	return newSerializer(FormatParquet, dir, rowGroupSize)
+}
+
+// NewSerializerArrowIPC creates a serializer writing <measurement>.arrows
+// files in the Arrow IPC streaming format with record batches of
+// rowGroupSize rows.
+func NewSerializerArrowIPC(dir string, rowGroupSize int) *Serializer {
+	return newSerializer(FormatArrowIPC, dir, rowGroupSize)
+}
+
+func newSerializer(format, dir string, rowGroupSize int) *Serializer {
+	if rowGroupSize <= 0 {
+		rowGroupSize = DefaultRowGroupSize
+	}
+	return &Serializer{
+		format:       format,
+		dir:          dir,
+		rowGroupSize: rowGroupSize,
+		alloc:        memory.NewGoAllocator(),
+		tables:       make(map[string]*columnarTable),
+	}
+}
+
+// SerializePoint appends the Point to the columns of its measurement, flushing
+// a row group when it is full.
+func (s *Serializer) SerializePoint(_ io.Writer, p *common.Point) error {
+	name := string(p.MeasurementName)
+	t, ok := s.tables[name]
+	if !ok {
+		var err error
+		t, err = s.newTable(name, p)
+		if err != nil {
+			return err
+		}
+		s.tables[name] = t
+		s.order = append(s.order, name)
+	}
+	if err := t.check(p); err != nil {
+		return fmt.Errorf("measurement %s: %v", name, err)
+	}
+
+	t.builder.Field(0).(*array.TimestampBuilder).Append(arrow.Timestamp(p.Timestamp.UTC().UnixNano()))
+	c := 1
+	for _, k := range t.tagKeys {
+		b := t.builder.Field(c).(*array.StringBuilder)
+		if v, ok := findTagValue(p, k); ok {
+			b.Append(string(v))
+		} else {
+			b.AppendNull()
+		}
+		c++
+	}
+	for _, k := range t.fieldKeys {
+		if v, ok := findFieldValue(p, k); ok {
+			if err := appendColumnValue(t.builder.Field(c), v); err != nil {
+				return err
+			}
+		} else {
+			t.builder.Field(c).AppendNull()
+		}
+		c++
+	}
+
+	t.rows++
+	if t.rows >= s.rowGroupSize {
+		return t.flush()
+	}
+	return nil
+}
+
+// SerializeSize flushes all buffered rows and closes the output files.
+func (s *Serializer) SerializeSize(_ io.Writer, points int64, values int64) error {
+	for _, name := range s.order {
+		t := s.tables[name]
+		if err := t.flush(); err != nil {
+			return err
+		}
+		if err := t.writer.Close(); err != nil {
+			return err
+		}
+		t.builder.Release()
+		// the parquet writer may have closed the file already
+		if err := t.file.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Serializer) newTable(name string, p *common.Point) (*columnarTable, error) {
+	t := &columnarTable{
+		tagKeys:   make([]string, len(p.TagKeys)),
+		fieldKeys: make([]string, len(p.FieldKeys)),
+		columns:   make(map[string]int, len(p.TagKeys)+len(p.FieldKeys)),
+	}
+	fields := make([]arrow.Field, 0, len(p.TagKeys)+len(p.FieldKeys)+1)
+	fields = append(fields, arrow.Field{Name: "time", Type: timestampType})
+	for i, k := range p.TagKeys {
+		t.tagKeys[i] = string(k)
+		t.columns[t.tagKeys[i]] = len(fields)
+		fields = append(fields, arrow.Field{Name: t.tagKeys[i], Type: arrow.BinaryTypes.String, Nullable: true})
+	}
+	for i, k := range p.FieldKeys {
+		t.fieldKeys[i] = string(k)
+		typ, err := arrowTypeFor(p.FieldValues[i])
+		if err != nil {
+			return nil, fmt.Errorf("measurement %s: %v", name, err)
+		}
+		t.columns[t.fieldKeys[i]] = len(fields)
+		fields = append(fields, arrow.Field{Name: t.fieldKeys[i], Type: typ, Nullable: true})
+	}
+	t.schema = arrow.NewSchema(fields, nil)
+	t.builder = array.NewRecordBuilder(s.alloc, t.schema)
+
+	ext := ".parquet"
+	if s.format == FormatArrowIPC {
+		ext = ".arrows"
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(s.dir, name+ext))
+	if err != nil {
+		return nil, err
+	}
+	t.file = f
+
+	switch s.format {
+	case FormatParquet:
+		props := parquet.NewWriterProperties(
+			parquet.WithMaxRowGroupLength(int64(s.rowGroupSize)),
+			parquet.WithCompression(compress.Codecs.Snappy),
+			parquet.WithAllocator(s.alloc),
+		)
+		w, err := pqarrow.NewFileWriter(t.schema, f, props, pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		t.writer = w
+	case FormatArrowIPC:
+		t.writer = ipc.NewWriter(f, ipc.WithSchema(t.schema), ipc.WithAllocator(s.alloc))
+	default:
+		panic(fmt.Sprintf("unknown columnar format %s", s.format))
+	}
+	return t, nil
+}
+
+// check verifies that the tags and fields of the Point have columns of the
+// right types, so that a row is either appended completely or not at all.
+func (t *columnarTable) check(p *common.Point) error {
+	for _, k := range p.TagKeys {
+		c, ok := t.columns[string(k)]
+		if !ok || c > len(t.tagKeys) {
+			return fmt.Errorf("tag %s is not in the schema of the first point", k)
+		}
+	}
+	for i, k := range p.FieldKeys {
+		c, ok := t.columns[string(k)]
+		if !ok || c <= len(t.tagKeys) {
+			return fmt.Errorf("field %s is not in the schema of the first point", k)
+		}
+		typ, err := arrowTypeFor(p.FieldValues[i])
+		if err != nil {
+			return err
+		}
+		if expected := t.schema.Field(c).Type; !arrow.TypeEqual(typ, expected) {
+			return fmt.Errorf("field %s is of type %s, the schema of the first point has %s", k, typ, expected)
+		}
+	}
+	return nil
+}
+
+// flush writes the buffered rows as one row group.
+func (t *columnarTable) flush() error {
+	if t.rows == 0 {
+		return nil
+	}
+	rec := t.builder.NewRecord()
+	defer rec.Release()
+	t.rows = 0
+	return t.writer.Write(rec)
+}
+
+func arrowTypeFor(v interface{}) (arrow.DataType, error) {
+	switch v.(type) {
+	case int, int64:
+		return arrow.PrimitiveTypes.Int64, nil
+	case float64:
+		return arrow.PrimitiveTypes.Float64, nil
+	case float32:
+		return arrow.PrimitiveTypes.Float32, nil
+	case bool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case []byte, string:
+		return arrow.BinaryTypes.String, nil
+	default:
+		return nil, fmt.Errorf("unknown field type for %#v", v)
+	}
+}
+
+// appendColumnValue appends v to the builder of its column, whose type was
+// checked by columnarTable.check.
+func appendColumnValue(b array.Builder, v interface{}) error {
+	switch x := v.(type) {
+	case int:
+		b.(*array.Int64Builder).Append(int64(x))
+	case int64:
+		b.(*array.Int64Builder).Append(x)
+	case float64:
+		b.(*array.Float64Builder).Append(x)
+	case float32:
+		b.(*array.Float32Builder).Append(x)
+	case bool:
+		b.(*array.BooleanBuilder).Append(x)
+	case []byte:
+		b.(*array.StringBuilder).Append(string(x))
+	case string:
+		b.(*array.StringBuilder).Append(x)
+	default:
+		return fmt.Errorf("unknown field type for %#v", v)
+	}
+	return nil
+}
+
+func findTagValue(p *common.Point, key string) ([]byte, bool) {
+	for i, k := range p.TagKeys {
+		if string(k) == key {
+			return p.TagValues[i], true
+		}
+	}
+	return nil, false
+}
+
+func findFieldValue(p *common.Point, key string) (interface{}, bool) {
+	for i, k := range p.FieldKeys {
+		if string(k) == key {
+			return p.FieldValues[i], true
+		}
+	}
+	return nil, false
+}
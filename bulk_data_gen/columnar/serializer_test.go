@@ -0,0 +1,200 @@
+package columnar
+
+import (
+	"context"
+	"github.com/apache/arrow/go/v12/arrow"
+	"github.com/apache/arrow/go/v12/arrow/array"
+	"github.com/apache/arrow/go/v12/arrow/ipc"
+	"github.com/apache/arrow/go/v12/arrow/memory"
+	"github.com/apache/arrow/go/v12/parquet"
+	"github.com/apache/arrow/go/v12/parquet/pqarrow"
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newPoint(ts time.Time, tags [][2]string, fields []string, values []interface{}) *common.Point {
+	p := &common.Point{}
+	p.SetMeasurementName([]byte("cpu"))
+	p.SetTimestamp(&ts)
+	for _, tag := range tags {
+		p.AppendTag([]byte(tag[0]), []byte(tag[1]))
+	}
+	for i, k := range fields {
+		p.AppendField([]byte(k), values[i])
+	}
+	return p
+}
+
+// readColumns reads the records of a written file back into its columns,
+// nulls are read as nil.
+func readColumns(t *testing.T, format, path string) map[string][]interface{} {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []arrow.Record
+	switch format {
+	case FormatParquet:
+		table, err := pqarrow.ReadTable(context.Background(), f, parquet.NewReaderProperties(nil), pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+		require.NoError(t, err)
+		defer table.Release()
+		tr := array.NewTableReader(table, 0)
+		defer tr.Release()
+		for tr.Next() {
+			rec := tr.Record()
+			rec.Retain()
+			records = append(records, rec)
+		}
+	case FormatArrowIPC:
+		r, err := ipc.NewReader(f)
+		require.NoError(t, err)
+		defer r.Release()
+		for r.Next() {
+			rec := r.Record()
+			rec.Retain()
+			records = append(records, rec)
+		}
+		require.NoError(t, r.Err())
+	}
+
+	columns := make(map[string][]interface{})
+	for _, rec := range records {
+		for i, field := range rec.Schema().Fields() {
+			col := rec.Column(i)
+			for row := 0; row < col.Len(); row++ {
+				var v interface{}
+				if !col.IsNull(row) {
+					switch a := col.(type) {
+					case *array.Timestamp:
+						v = int64(a.Value(row))
+					case *array.Int64:
+						v = a.Value(row)
+					case *array.Float64:
+						v = a.Value(row)
+					case *array.Float32:
+						v = a.Value(row)
+					case *array.Boolean:
+						v = a.Value(row)
+					case *array.String:
+						v = a.Value(row)
+					default:
+						t.Fatalf("unexpected column type %s", col.DataType())
+					}
+				}
+				columns[field.Name] = append(columns[field.Name], v)
+			}
+		}
+		rec.Release()
+	}
+	return columns
+}
+
+func TestSerializerRoundTrip(t *testing.T) {
+	t0 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := []*common.Point{
+		newPoint(t0, [][2]string{{"hostname", "host_0"}, {"region", "eu-west-1"}},
+			[]string{"usage", "count", "up", "ratio", "message"},
+			[]interface{}{58.13, 42, true, float32(0.5), "a string"}),
+		// a missing tag and field, keys in another order
+		newPoint(t0.Add(time.Second), [][2]string{{"hostname", "host_1"}},
+			[]string{"message", "count", "usage", "ratio"},
+			[]interface{}{[]byte("bytes"), int64(-7), -1.5, float32(2)}),
+		newPoint(t0.Add(time.Hour), [][2]string{{"region", "us-west-1"}, {"hostname", "host_2"}},
+			[]string{"up"}, []interface{}{false}),
+	}
+	expected := map[string][]interface{}{
+		"time":     {t0.UnixNano(), t0.Add(time.Second).UnixNano(), t0.Add(time.Hour).UnixNano()},
+		"hostname": {"host_0", "host_1", "host_2"},
+		"region":   {"eu-west-1", nil, "us-west-1"},
+		"usage":    {58.13, -1.5, nil},
+		"count":    {int64(42), int64(-7), nil},
+		"up":       {true, nil, false},
+		"ratio":    {float32(0.5), float32(2), nil},
+		"message":  {"a string", "bytes", nil},
+	}
+
+	cases := []struct {
+		format string
+		file   string
+	}{
+		{format: FormatParquet, file: "cpu.parquet"},
+		{format: FormatArrowIPC, file: "cpu.arrows"},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "columnar")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			// row groups of 2 rows, the last one is flushed by SerializeSize
+			s, err := NewSerializerForFormat(c.format, dir, 2)
+			require.NoError(t, err)
+			for _, p := range points {
+				require.NoError(t, s.SerializePoint(nil, p))
+			}
+			require.NoError(t, s.SerializeSize(nil, 3, 10))
+
+			require.Equal(t, expected, readColumns(t, c.format, filepath.Join(dir, c.file)))
+		})
+	}
+}
+
+func TestSerializerSchemaMismatch(t *testing.T) {
+	t0 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := newPoint(t0, [][2]string{{"hostname", "host_0"}}, []string{"usage"}, []interface{}{1.5})
+	cases := []struct {
+		name  string
+		point *common.Point
+		err   string
+	}{
+		{
+			name:  "unknown tag",
+			point: newPoint(t0, [][2]string{{"hostname", "host_1"}, {"region", "eu-west-1"}}, []string{"usage"}, []interface{}{1.5}),
+			err:   "tag region is not in the schema",
+		},
+		{
+			name:  "unknown field",
+			point: newPoint(t0, [][2]string{{"hostname", "host_1"}}, []string{"usage", "count"}, []interface{}{1.5, 1}),
+			err:   "field count is not in the schema",
+		},
+		{
+			name:  "tag as field",
+			point: newPoint(t0, nil, []string{"hostname"}, []interface{}{"host_1"}),
+			err:   "field hostname is not in the schema",
+		},
+		{
+			name:  "type mismatch",
+			point: newPoint(t0, [][2]string{{"hostname", "host_1"}}, []string{"usage"}, []interface{}{int64(1)}),
+			err:   "field usage is of type int64",
+		},
+		{
+			name:  "unknown type",
+			point: newPoint(t0, [][2]string{{"hostname", "host_1"}}, []string{"usage"}, []interface{}{uint8(1)}),
+			err:   "unknown field type",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "columnar")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			s := NewSerializerArrowIPC(dir, 10)
+			require.NoError(t, s.SerializePoint(nil, first))
+			err = s.SerializePoint(nil, c.point)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.err)
+			require.NoError(t, s.SerializeSize(nil, 1, 1))
+
+			// the rejected point left no partial row behind
+			columns := readColumns(t, FormatArrowIPC, filepath.Join(dir, "cpu.arrows"))
+			require.Equal(t, []interface{}{"host_0"}, columns["hostname"])
+			require.Equal(t, []interface{}{1.5}, columns["usage"])
+		})
+	}
+}
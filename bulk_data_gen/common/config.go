@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"github.com/pelletier/go-toml"
 	"log"
-	"math/rand"
 	"net/http"
 	"reflect"
 	"strings"
@@ -25,7 +24,7 @@ func getSourceValue(s *Source, measurementName, itemKey string, itemDefaultValue
 	switch reflect.Indirect(reflect.ValueOf(s)).Elem().Kind() {
 	case reflect.Array:
 		array := (*s).([]interface{})
-		return array[rand.Int63n(int64(len(array)))]
+		return array[Rand.Int63n(int64(len(array)))]
 	case reflect.Map:
 		m := (*s).(map[string]interface{})
 		if reflect.DeepEqual(m, DefaultValueGenerator) {
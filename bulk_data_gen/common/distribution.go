@@ -23,18 +23,20 @@ func ND(mean, stddev float64) *NormalDistribution {
 	return &NormalDistribution{Mean: mean, StdDev: stddev}
 }
 
-// Unsynchronized random source
-var localRand = rand.New(rand.NewSource(1))
+// Rand is the unsynchronized random source of the simulators. Unlike the
+// global source, it is seeded by Seed, so a dataset is reproducible from its
+// seed.
+var Rand = rand.New(rand.NewSource(1))
 
 // Seed uses the provided seed value to initialize the generator to a deterministic state.
 func Seed (seed int64) {
-	localRand.Seed(seed)
+	Rand.Seed(seed)
 }
 
 // Advance advances this distribution. Since a normal distribution is
 // stateless, this is just overwrites the internal cache value.
 func (d *NormalDistribution) Advance() {
-	d.value = localRand.NormFloat64()*d.StdDev + d.Mean
+	d.value = Rand.NormFloat64()*d.StdDev + d.Mean
 }
 
 // Get returns the last computed value for this distribution.
@@ -57,7 +59,7 @@ func UD(low, high float64) *UniformDistribution {
 // Advance advances this distribution. Since a uniform distribution is
 // stateless, this is just overwrites the internal cache value.
 func (d *UniformDistribution) Advance() {
-	x := rand.Float64() // uniform
+	x := Rand.Float64() // uniform
 	x *= d.High - d.Low
 	x += d.Low
 	d.value = x
@@ -208,7 +210,7 @@ type TwoStateDistribution struct {
 
 func (d *TwoStateDistribution) Advance() {
 	d.State = d.Low
-	if rand.Float64() > 0.5 {
+	if Rand.Float64() > 0.5 {
 		d.State = d.High
 	}
 }
@@ -222,6 +224,6 @@ func TSD(low float64, high float64, state float64) *TwoStateDistribution {
 }
 
 func RandChoice(choices [][]byte) []byte {
-	idx := rand.Int63n(int64(len(choices)))
+	idx := Rand.Int63n(int64(len(choices)))
 	return choices[idx]
 }
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// FormatChoices lists the supported output data formats. The columnar formats
+// are provided by the bulk_data_gen/columnar package.
+var FormatChoices = []string{"influx-bulk", "es-bulk", "es-bulk6x", "es-bulk7x", "cassandra", "mongo", "opentsdb", "timescaledb-sql", "timescaledb-copyFrom", "graphite-line", "splunk-json", "csv", "influx-annotated-csv", "binary"}
+
+// SerializerOptions holds the format specific serializer settings.
+type SerializerOptions struct {
+	// TimestampPrecision is the unit of written timestamps, if the format
+	// allows to choose it (influx-bulk). Zero means nanoseconds.
+	TimestampPrecision time.Duration
+}
+
+// NewSerializerForFormat creates the serializer for the given format name.
+func NewSerializerForFormat(format string, opts SerializerOptions) (Serializer, error) {
+	switch format {
+	case "influx-bulk":
+		if opts.TimestampPrecision > 0 {
+			return NewSerializerInfluxWithPrecision(opts.TimestampPrecision), nil
+		}
+		return NewSerializerInflux(), nil
+	case "es-bulk":
+		return NewSerializerElastic("5x"), nil
+	case "es-bulk6x":
+		return NewSerializerElastic("6x"), nil
+	case "es-bulk7x":
+		return NewSerializerElastic("7x"), nil
+	case "cassandra":
+		return NewSerializerCassandra(), nil
+	case "mongo":
+		return NewSerializerMongo(), nil
+	case "opentsdb":
+		return NewSerializerOpenTSDB(), nil
+	case "timescaledb-sql":
+		return NewSerializerTimescaleSql(), nil
+	case "timescaledb-copyFrom":
+		return NewSerializerTimescaleBin(), nil
+	case "graphite-line":
+		return NewSerializerGraphiteLine(), nil
+	case "splunk-json":
+		return NewSerializerSplunkJson(), nil
+	case "csv":
+		return NewSerializerCSV(), nil
+	case "influx-annotated-csv":
+		return NewSerializerInfluxAnnotatedCSV(), nil
+	case "binary":
+		return NewSerializerBinary(), nil
+	default:
+		return nil, fmt.Errorf("invalid format specifier: %v", format)
+	}
+}
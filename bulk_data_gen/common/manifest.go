@@ -0,0 +1,156 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+)
+
+const ManifestVersion = 1
+
+// ManifestHashPrefix prefixes the hex encoded hash of the generated output.
+const ManifestHashPrefix = "sha256:"
+
+// MeasurementStats holds the number of points and values serialized for one
+// measurement.
+type MeasurementStats struct {
+	Points int64 `json:"points"`
+	Values int64 `json:"values"`
+}
+
+// Manifest describes a generated dataset: the parameters it was generated
+// with and what was written. Points and Values are the totals of the whole
+// dataset (as in the dataset size marker), Measurements and SeriesCount
+// describe only the serialized interleaved group. ContentHash is empty for
+// the columnar formats, which are written into files instead of the output.
+type Manifest struct {
+	Version                      int                          `json:"version"`
+	Seed                         int64                        `json:"seed"`
+	UseCase                      string                       `json:"use_case"`
+	ConfigFile                   string                       `json:"config_file,omitempty"`
+	ScaleVar                     int64                        `json:"scale_var"`
+	ScaleVarOffset               int64                        `json:"scale_var_offset"`
+	SamplingInterval             string                       `json:"sampling_interval"`
+	SamplingJitter               string                       `json:"sampling_jitter"`
+	TimestampPrecision           string                       `json:"timestamp_precision"`
+	TimestampStart               string                       `json:"timestamp_start"`
+	TimestampEnd                 string                       `json:"timestamp_end"`
+	Format                       string                       `json:"format"`
+	InterleavedGenerationGroupID uint                         `json:"interleaved_generation_group_id"`
+	InterleavedGenerationGroups  uint                         `json:"interleaved_generation_groups"`
+	Points                       int64                        `json:"points"`
+	Values                       int64                        `json:"values"`
+	Measurements                 map[string]*MeasurementStats `json:"measurements"`
+	SeriesCount                  int64                        `json:"series_count"`
+	ContentHash                  string                       `json:"content_hash"`
+}
+
+// ManifestBuilder collects dataset statistics while points are serialized.
+type ManifestBuilder struct {
+	Manifest Manifest
+
+	hash   hash.Hash
+	series map[uint64]struct{}
+	key    hash.Hash64
+}
+
+func NewManifestBuilder() *ManifestBuilder {
+	return &ManifestBuilder{
+		Manifest: Manifest{
+			Version:      ManifestVersion,
+			Measurements: make(map[string]*MeasurementStats),
+		},
+		hash:   sha256.New(),
+		series: make(map[uint64]struct{}),
+		key:    fnv.New64a(),
+	}
+}
+
+// Write implements io.Writer, hashing the serialized output.
+func (b *ManifestBuilder) Write(p []byte) (int, error) {
+	return b.hash.Write(p)
+}
+
+// AddPoint records the Point in the per-measurement and series statistics.
+// Series are identified by a 64-bit hash of the measurement name and tags.
+func (b *ManifestBuilder) AddPoint(p *Point) {
+	name := string(p.MeasurementName)
+	m, ok := b.Manifest.Measurements[name]
+	if !ok {
+		m = &MeasurementStats{}
+		b.Manifest.Measurements[name] = m
+	}
+	m.Points++
+	m.Values += int64(len(p.FieldValues))
+
+	b.key.Reset()
+	b.key.Write(p.MeasurementName)
+	for i := range p.TagKeys {
+		b.key.Write([]byte{','})
+		b.key.Write(p.TagKeys[i])
+		b.key.Write([]byte{'='})
+		b.key.Write(p.TagValues[i])
+	}
+	b.series[b.key.Sum64()] = struct{}{}
+}
+
+// Finish sets the totals and the content hash and returns the manifest.
+func (b *ManifestBuilder) Finish(points, values int64) *Manifest {
+	b.Manifest.Points = points
+	b.Manifest.Values = values
+	b.Manifest.SeriesCount = int64(len(b.series))
+	b.Manifest.ContentHash = ManifestHashPrefix + hex.EncodeToString(b.hash.Sum(nil))
+	return &b.Manifest
+}
+
+// WriteFile writes the manifest as indented JSON.
+func (m *Manifest) WriteFile(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadManifest reads a manifest written by bulk_data_gen.
+func ReadManifest(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m := &Manifest{}
+	if err := json.NewDecoder(f).Decode(m); err != nil {
+		return nil, fmt.Errorf("cannot parse manifest %s: %v", path, err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return m, nil
+}
+
+// ReportTags returns the manifest parameters as report tags.
+func (m *Manifest) ReportTags() [][2]string {
+	tags := [][2]string{
+		{"dataset_use_case", m.UseCase},
+		{"dataset_format", m.Format},
+		{"dataset_seed", fmt.Sprintf("%d", m.Seed)},
+		{"dataset_scale_var", fmt.Sprintf("%d", m.ScaleVar)},
+		{"dataset_scale_var_offset", fmt.Sprintf("%d", m.ScaleVarOffset)},
+		{"dataset_sampling_interval", m.SamplingInterval},
+		{"dataset_timestamp_precision", m.TimestampPrecision},
+		{"dataset_series", fmt.Sprintf("%d", m.SeriesCount)},
+	}
+	if m.ContentHash != "" {
+		tags = append(tags, [2]string{"dataset_hash", m.ContentHash})
+	}
+	if m.InterleavedGenerationGroups > 1 {
+		tags = append(tags, [2]string{"dataset_group", fmt.Sprintf("%d/%d", m.InterleavedGenerationGroupID, m.InterleavedGenerationGroups)})
+	}
+	return tags
+}
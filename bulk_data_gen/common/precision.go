@@ -0,0 +1,100 @@
+package common
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// TimestampPrecisionChoices lists the supported timestamp precisions.
+var TimestampPrecisionChoices = []string{"ns", "us", "ms", "s"}
+
+// ParseTimestampPrecision converts a precision name to its duration.
+func ParseTimestampPrecision(s string) (time.Duration, error) {
+	switch s {
+	case "ns":
+		return time.Nanosecond, nil
+	case "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	default:
+		return 0, fmt.Errorf("invalid timestamp precision: %s", s)
+	}
+}
+
+// formatTimestampUnits are the units of the timestamps written by the formats
+// having a fixed unit coarser than nanoseconds. influx-bulk writes timestamps
+// in the chosen precision, all other formats in nanoseconds.
+var formatTimestampUnits = map[string]string{
+	"es-bulk":       "ms",
+	"es-bulk6x":     "ms",
+	"es-bulk7x":     "ms",
+	"opentsdb":      "ms",
+	"graphite-line": "s",
+	"splunk-json":   "s",
+}
+
+// FormatTimestampPrecision returns the timestamp precision to generate data of
+// the format with: precision, or the unit of the format's timestamps if
+// precision is empty. A precision finer than the unit of the format's
+// timestamps cannot be honoured and is an error.
+func FormatTimestampPrecision(format, precision string) (string, error) {
+	unit, ok := formatTimestampUnits[format]
+	if !ok {
+		unit = "ns"
+	}
+	if precision == "" {
+		return unit, nil
+	}
+	d, err := ParseTimestampPrecision(precision)
+	if err != nil {
+		return "", err
+	}
+	if format == "influx-bulk" {
+		return precision, nil
+	}
+	unitDuration, _ := ParseTimestampPrecision(unit)
+	if d < unitDuration {
+		return "", fmt.Errorf("format %s writes timestamps in %s, it cannot honour timestamp precision %s", format, unit, precision)
+	}
+	return precision, nil
+}
+
+// TimestampAdjuster applies sampling jitter and precision to point
+// timestamps, so that every format is serialized with the same timestamps.
+// Jitter uses its own PRNG, the simulated values are therefore the same with
+// and without jitter.
+type TimestampAdjuster struct {
+	precision time.Duration
+	jitter    time.Duration
+	rand      *rand.Rand
+	timestamp time.Time
+}
+
+func NewTimestampAdjuster(precision, jitter time.Duration, seed int64) *TimestampAdjuster {
+	return &TimestampAdjuster{
+		precision: precision,
+		jitter:    jitter,
+		rand:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Adjust shifts the timestamp by a random jitter from [0, jitter) and
+// truncates it to the precision. The simulator owned timestamp is not
+// modified, the Point gets a copy valid until the next call.
+func (a *TimestampAdjuster) Adjust(p *Point) {
+	if a.precision <= time.Nanosecond && a.jitter <= 0 {
+		return
+	}
+	a.timestamp = *p.Timestamp
+	if a.jitter > 0 {
+		a.timestamp = a.timestamp.Add(time.Duration(a.rand.Int63n(int64(a.jitter))))
+	}
+	if a.precision > time.Nanosecond {
+		a.timestamp = a.timestamp.Truncate(a.precision)
+	}
+	p.SetTimestamp(&a.timestamp)
+}
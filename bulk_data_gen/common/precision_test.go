@@ -0,0 +1,38 @@
+package common
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFormatTimestampPrecision(t *testing.T) {
+	cases := []struct {
+		format    string
+		precision string
+		expected  string
+		err       bool
+	}{
+		{format: "influx-bulk", precision: "", expected: "ns"},
+		{format: "influx-bulk", precision: "s", expected: "s"},
+		{format: "cassandra", precision: "", expected: "ns"},
+		{format: "cassandra", precision: "us", expected: "us"},
+		{format: "es-bulk7x", precision: "", expected: "ms"},
+		{format: "es-bulk7x", precision: "s", expected: "s"},
+		{format: "es-bulk7x", precision: "us", err: true},
+		{format: "opentsdb", precision: "ns", err: true},
+		{format: "graphite-line", precision: "", expected: "s"},
+		{format: "graphite-line", precision: "ms", err: true},
+		{format: "csv", precision: "m", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.format+"/"+c.precision, func(t *testing.T) {
+			precision, err := FormatTimestampPrecision(c.format, c.precision)
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, precision)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package common
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Binary intermediate format.
+//
+// The stream starts with BinaryMagic followed by records, each starting with
+// a record kind byte. A point record holds the measurement name, tags, fields
+// and the timestamp as a zig-zag varint delta to the previous point. All
+// names and tag values are dictionary encoded: a uvarint 0 is followed by
+// a new length-prefixed literal, which gets the next dictionary index, any
+// other value n refers to the dictionary entry n-1. Field values are prefixed
+// by a type byte so that the exact Go type is restored on decoding. The stream
+// ends with a size record carrying the total points and values of the dataset,
+// followed by the points and values in the stream, which are fewer when only
+// an interleaved generation group was serialized.
+const BinaryMagic = "BDG\x02"
+
+// BinaryMaxLength is the longest name, tag value or string field value
+// accepted by BinaryPointReader, a longer length prefix means the input is
+// corrupted.
+const BinaryMaxLength = 16 << 20
+
+const (
+	binaryRecordPoint byte = 'p'
+	binaryRecordSize  byte = 'z'
+)
+
+const (
+	binaryTypeInt     byte = 'i'
+	binaryTypeInt64   byte = 'I'
+	binaryTypeFloat64 byte = 'd'
+	binaryTypeFloat32 byte = 'f'
+	binaryTypeTrue    byte = 't'
+	binaryTypeFalse   byte = 'F'
+	binaryTypeBytes   byte = 'b'
+	binaryTypeString  byte = 's'
+)
+
+// SerializerBinary writes points in the binary intermediate format, which can
+// be converted to any other format by bulk_data_convert.
+type SerializerBinary struct {
+	dict          map[string]uint64
+	lastTimestamp int64
+	headerWritten bool
+	buf           []byte
+
+	points int64
+	values int64
+}
+
+func NewSerializerBinary() *SerializerBinary {
+	return &SerializerBinary{dict: make(map[string]uint64)}
+}
+
+// SerializePoint writes Point data to the given writer, conforming to the
+// binary intermediate format.
+func (s *SerializerBinary) SerializePoint(w io.Writer, p *Point) error {
+	buf := s.header(s.buf[:0])
+	buf = append(buf, binaryRecordPoint)
+	buf = s.appendDict(buf, p.MeasurementName)
+	buf = binary.AppendUvarint(buf, uint64(len(p.TagKeys)))
+	for i := range p.TagKeys {
+		buf = s.appendDict(buf, p.TagKeys[i])
+		buf = s.appendDict(buf, p.TagValues[i])
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(p.FieldKeys)))
+	for i := range p.FieldKeys {
+		buf = s.appendDict(buf, p.FieldKeys[i])
+		buf = appendBinaryValue(buf, p.FieldValues[i])
+	}
+	ts := p.Timestamp.UnixNano()
+	buf = binary.AppendVarint(buf, ts-s.lastTimestamp)
+	s.lastTimestamp = ts
+	s.buf = buf
+	s.points++
+	s.values += int64(len(p.FieldValues))
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// SerializeSize writes the closing size record with the dataset totals and
+// the counts of the points serialized.
+func (s *SerializerBinary) SerializeSize(w io.Writer, points int64, values int64) error {
+	buf := s.header(s.buf[:0])
+	buf = append(buf, binaryRecordSize)
+	buf = binary.AppendUvarint(buf, uint64(points))
+	buf = binary.AppendUvarint(buf, uint64(values))
+	buf = binary.AppendUvarint(buf, uint64(s.points))
+	buf = binary.AppendUvarint(buf, uint64(s.values))
+	s.buf = buf
+	_, err := w.Write(buf)
+	return err
+}
+
+func (s *SerializerBinary) header(buf []byte) []byte {
+	if !s.headerWritten {
+		s.headerWritten = true
+		buf = append(buf, BinaryMagic...)
+	}
+	return buf
+}
+
+func (s *SerializerBinary) appendDict(buf []byte, b []byte) []byte {
+	if idx, ok := s.dict[string(b)]; ok {
+		return binary.AppendUvarint(buf, idx+1)
+	}
+	s.dict[string(b)] = uint64(len(s.dict))
+	buf = binary.AppendUvarint(buf, 0)
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendBinaryValue(buf []byte, v interface{}) []byte {
+	switch x := v.(type) {
+	case int:
+		buf = append(buf, binaryTypeInt)
+		return binary.AppendVarint(buf, int64(x))
+	case int64:
+		buf = append(buf, binaryTypeInt64)
+		return binary.AppendVarint(buf, x)
+	case float64:
+		buf = append(buf, binaryTypeFloat64)
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(x))
+	case float32:
+		buf = append(buf, binaryTypeFloat32)
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(x))
+	case bool:
+		if x {
+			return append(buf, binaryTypeTrue)
+		}
+		return append(buf, binaryTypeFalse)
+	case []byte:
+		buf = append(buf, binaryTypeBytes)
+		buf = binary.AppendUvarint(buf, uint64(len(x)))
+		return append(buf, x...)
+	case string:
+		buf = append(buf, binaryTypeString)
+		buf = binary.AppendUvarint(buf, uint64(len(x)))
+		return append(buf, x...)
+	default:
+		panic(fmt.Sprintf("unknown field type for %#v", v))
+	}
+}
+
+// BinaryPointReader decodes points written by SerializerBinary.
+type BinaryPointReader struct {
+	r             *bufio.Reader
+	dict          [][]byte
+	lastTimestamp int64
+	timestamp     time.Time
+
+	sizeSeen      bool
+	points        int64
+	values        int64
+	writtenPoints int64
+	writtenValues int64
+}
+
+// NewBinaryPointReader checks the stream header and returns a reader
+// positioned at the first record.
+func NewBinaryPointReader(r io.Reader) (*BinaryPointReader, error) {
+	br := bufio.NewReaderSize(r, 4<<20)
+	magic := make([]byte, len(BinaryMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("cannot read header: %v", err)
+	}
+	if string(magic) != BinaryMagic {
+		return nil, errors.New("input is not in the binary intermediate format")
+	}
+	return &BinaryPointReader{r: br}, nil
+}
+
+// Next decodes the next point into p, which must be reset by the caller. It
+// returns io.EOF after the size record has been read. Byte slices and the
+// timestamp set in p are only valid until the next call.
+func (r *BinaryPointReader) Next(p *Point) error {
+	if r.sizeSeen {
+		return io.EOF
+	}
+	kind, err := r.r.ReadByte()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case binaryRecordSize:
+		if r.points, err = r.readUvarintInt64(); err != nil {
+			return unexpectedEOF(err)
+		}
+		if r.values, err = r.readUvarintInt64(); err != nil {
+			return unexpectedEOF(err)
+		}
+		if r.writtenPoints, err = r.readUvarintInt64(); err != nil {
+			return unexpectedEOF(err)
+		}
+		if r.writtenValues, err = r.readUvarintInt64(); err != nil {
+			return unexpectedEOF(err)
+		}
+		r.sizeSeen = true
+		return io.EOF
+	case binaryRecordPoint:
+		return unexpectedEOF(r.readPoint(p))
+	default:
+		return fmt.Errorf("unknown record kind %q", kind)
+	}
+}
+
+// unexpectedEOF reports the end of the input within a record as an error, so
+// that a truncated stream is not mistaken for its end.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (r *BinaryPointReader) readPoint(p *Point) error {
+	name, err := r.readDict()
+	if err != nil {
+		return err
+	}
+	p.SetMeasurementName(name)
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < n; i++ {
+		k, err := r.readDict()
+		if err != nil {
+			return err
+		}
+		v, err := r.readDict()
+		if err != nil {
+			return err
+		}
+		p.AppendTag(k, v)
+	}
+	if n, err = binary.ReadUvarint(r.r); err != nil {
+		return err
+	}
+	for i := uint64(0); i < n; i++ {
+		k, err := r.readDict()
+		if err != nil {
+			return err
+		}
+		v, err := r.readValue()
+		if err != nil {
+			return err
+		}
+		p.AppendField(k, v)
+	}
+	delta, err := binary.ReadVarint(r.r)
+	if err != nil {
+		return err
+	}
+	r.lastTimestamp += delta
+	r.timestamp = time.Unix(0, r.lastTimestamp).UTC()
+	p.SetTimestamp(&r.timestamp)
+	return nil
+}
+
+// Size returns the total points and values of the dataset from the closing
+// size record; ok is false until the record has been read.
+func (r *BinaryPointReader) Size() (points, values int64, ok bool) {
+	return r.points, r.values, r.sizeSeen
+}
+
+// Written returns the points and values in the stream from the closing size
+// record, for an interleaved generation group these are the group's counts;
+// ok is false until the record has been read.
+func (r *BinaryPointReader) Written() (points, values int64, ok bool) {
+	return r.writtenPoints, r.writtenValues, r.sizeSeen
+}
+
+func (r *BinaryPointReader) readUvarintInt64() (int64, error) {
+	v, err := binary.ReadUvarint(r.r)
+	return int64(v), err
+}
+
+func (r *BinaryPointReader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if n > BinaryMaxLength {
+		return nil, fmt.Errorf("length %d exceeds the maximum of %d bytes", n, BinaryMaxLength)
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r.r, b)
+	return b, err
+}
+
+func (r *BinaryPointReader) readDict() ([]byte, error) {
+	idx, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if idx > 0 {
+		if idx > uint64(len(r.dict)) {
+			return nil, fmt.Errorf("dictionary index %d out of range", idx-1)
+		}
+		return r.dict[idx-1], nil
+	}
+	b, err := r.readBytes()
+	if err != nil {
+		return nil, err
+	}
+	r.dict = append(r.dict, b)
+	return b, nil
+}
+
+func (r *BinaryPointReader) readValue() (interface{}, error) {
+	typ, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case binaryTypeInt:
+		v, err := binary.ReadVarint(r.r)
+		return int(v), err
+	case binaryTypeInt64:
+		return binary.ReadVarint(r.r)
+	case binaryTypeFloat64:
+		var b [8]byte
+		_, err := io.ReadFull(r.r, b[:])
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), err
+	case binaryTypeFloat32:
+		var b [4]byte
+		_, err := io.ReadFull(r.r, b[:])
+		return math.Float32frombits(binary.LittleEndian.Uint32(b[:])), err
+	case binaryTypeTrue:
+		return true, nil
+	case binaryTypeFalse:
+		return false, nil
+	case binaryTypeBytes:
+		return r.readBytes()
+	case binaryTypeString:
+		b, err := r.readBytes()
+		return string(b), err
+	default:
+		return nil, fmt.Errorf("unknown field type %q", typ)
+	}
+}
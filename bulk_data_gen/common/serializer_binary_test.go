@@ -0,0 +1,150 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSerializerBinaryRoundTrip(t *testing.T) {
+	t0 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPoint := func(ts time.Time, name string, tags [][2]string, fields []string, values []interface{}) *Point {
+		p := &Point{}
+		p.SetMeasurementName([]byte(name))
+		p.SetTimestamp(&ts)
+		for _, tag := range tags {
+			p.AppendTag([]byte(tag[0]), []byte(tag[1]))
+		}
+		for i, k := range fields {
+			p.AppendField([]byte(k), values[i])
+		}
+		return p
+	}
+	points := []*Point{
+		newPoint(t0, "cpu", [][2]string{{"hostname", "host_0"}, {"region", "eu-west-1"}},
+			[]string{"usage_user", "usage_guest"}, []interface{}{58.13, float32(0.5)}),
+		// dictionary references and a negative timestamp delta
+		newPoint(t0.Add(-time.Second), "cpu", [][2]string{{"hostname", "host_1"}, {"region", "eu-west-1"}},
+			[]string{"usage_user", "usage_guest"}, []interface{}{-1.5, float32(2)}),
+		newPoint(t0.Add(time.Hour), "status", nil,
+			[]string{"count", "total", "up", "down", "raw", "message"},
+			[]interface{}{42, int64(-7), true, false, []byte("bytes"), "a string"}),
+	}
+
+	var buf bytes.Buffer
+	s := NewSerializerBinary()
+	for _, p := range points {
+		require.NoError(t, s.SerializePoint(&buf, p))
+	}
+	require.NoError(t, s.SerializeSize(&buf, 3, 10))
+
+	r, err := NewBinaryPointReader(&buf)
+	require.NoError(t, err)
+	for _, expected := range points {
+		p := &Point{}
+		require.NoError(t, r.Next(p))
+		require.Equal(t, expected.MeasurementName, p.MeasurementName)
+		require.Equal(t, expected.TagKeys, p.TagKeys)
+		require.Equal(t, expected.TagValues, p.TagValues)
+		require.Equal(t, expected.FieldKeys, p.FieldKeys)
+		require.Equal(t, expected.FieldValues, p.FieldValues)
+		require.True(t, expected.Timestamp.Equal(*p.Timestamp))
+	}
+	_, _, ok := r.Size()
+	require.False(t, ok)
+	require.Equal(t, io.EOF, r.Next(&Point{}))
+	pointCount, valueCount, ok := r.Size()
+	require.True(t, ok)
+	require.Equal(t, int64(3), pointCount)
+	require.Equal(t, int64(10), valueCount)
+	pointCount, valueCount, ok = r.Written()
+	require.True(t, ok)
+	require.Equal(t, int64(3), pointCount)
+	require.Equal(t, int64(10), valueCount)
+}
+
+func TestSerializerBinaryInterleavedGroup(t *testing.T) {
+	t0 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	const points, groups, groupID = 10, 3, 1
+
+	// serialize the round-robin group 1 of 3, the size record gets the
+	// dataset totals as written by bulk_data_gen
+	var buf bytes.Buffer
+	s := NewSerializerBinary()
+	values := int64(0)
+	for i := 0; i < points; i++ {
+		ts := t0.Add(time.Duration(i) * time.Second)
+		p := &Point{}
+		p.SetMeasurementName([]byte("cpu"))
+		p.SetTimestamp(&ts)
+		p.AppendTag([]byte("hostname"), []byte(fmt.Sprintf("host_%d", i)))
+		for f := 0; f <= i%2; f++ {
+			p.AppendField([]byte(fmt.Sprintf("usage_%d", f)), float64(i))
+		}
+		values += int64(len(p.FieldValues))
+		if i%groups == groupID {
+			require.NoError(t, s.SerializePoint(&buf, p))
+		}
+	}
+	require.NoError(t, s.SerializeSize(&buf, points, values))
+
+	// convert it as bulk_data_convert does
+	r, err := NewBinaryPointReader(&buf)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	graphite := NewSerializerGraphiteLine()
+	n, nValues := int64(0), int64(0)
+	p := MakeUsablePoint()
+	for {
+		err := r.Next(p)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, graphite.SerializePoint(&out, p))
+		nValues += int64(len(p.FieldValues))
+		p.Reset()
+		n++
+	}
+	writtenPoints, writtenValues, ok := r.Written()
+	require.True(t, ok)
+	require.Equal(t, int64(3), n)
+	require.Equal(t, n, writtenPoints)
+	require.Equal(t, int64(5), nValues)
+	require.Equal(t, nValues, writtenValues)
+
+	datasetPoints, datasetValues, ok := r.Size()
+	require.True(t, ok)
+	require.Equal(t, int64(points), datasetPoints)
+	require.Equal(t, values, datasetValues)
+	require.NoError(t, graphite.SerializeSize(&out, datasetPoints, datasetValues))
+	require.True(t, strings.HasSuffix(out.String(), fmt.Sprintf("%s%d,%d\n", DatasetSizeMarker, points, values)))
+}
+
+func TestBinaryPointReaderErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input []byte
+	}{
+		{"bad magic", []byte("XXXX")},
+		{"unknown record", []byte(BinaryMagic + "x")},
+		{"truncated", []byte(BinaryMagic + "p")},
+		{"dictionary index", append([]byte(BinaryMagic+"p"), binary.AppendUvarint(nil, 5)...)},
+		{"length", append([]byte(BinaryMagic+"p\x00"), binary.AppendUvarint(nil, BinaryMaxLength+1)...)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := NewBinaryPointReader(bytes.NewReader(c.input))
+			if err == nil {
+				err = r.Next(&Point{})
+			}
+			require.Error(t, err)
+			require.NotEqual(t, io.EOF, err)
+		})
+	}
+}
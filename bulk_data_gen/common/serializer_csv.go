@@ -0,0 +1,255 @@
+package common
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+type csvColumn struct {
+	name string
+	tag  bool
+	typ  string // annotated CSV data type
+}
+
+// SerializerCSV writes points as a single CSV table with one header. Columns
+// are the union of the tag and field keys of all the measurements, the cells
+// of the columns a point does not have are empty. As the columns are known
+// only once all the points were seen, rows are spilled to an unlinked
+// temporary file and the table is written out by SerializeSize.
+type SerializerCSV struct {
+	annotated bool
+	columns   []csvColumn
+	index     map[string]int
+	spill     *os.File
+	spillBuf  *bufio.Writer
+	spillW    *csv.Writer
+	record    []string
+}
+
+// NewSerializerCSV creates a plain CSV serializer. Columns are:
+// measurement,time,<tag keys>,<field keys>; the time is in RFC3339 format.
+func NewSerializerCSV() *SerializerCSV {
+	return &SerializerCSV{index: make(map[string]int)}
+}
+
+// NewSerializerInfluxAnnotatedCSV creates a serializer producing InfluxDB
+// annotated CSV that can be imported using `influx write --format csv`.
+// A field having integer values in one measurement and float values in
+// another one is written as double.
+func NewSerializerInfluxAnnotatedCSV() *SerializerCSV {
+	return &SerializerCSV{annotated: true, index: make(map[string]int)}
+}
+
+// SerializePoint writes Point data to the given writer, conforming to the
+// CSV format.
+//
+// For plain CSV this function writes output that looks like:
+// measurement,time,hostname,region,usage_user,usage_system,free
+// cpu,2018-01-01T00:00:00Z,host_0,eu-west-1,58.13,2.61,
+// mem,2018-01-01T00:00:00Z,host_0,eu-west-1,,,1024
+//
+// For annotated CSV this function writes output that looks like:
+// #datatype measurement,tag,tag,double,double,long,dateTime:number
+// measurement,hostname,region,usage_user,usage_system,free,time
+// cpu,host_0,eu-west-1,58.13,2.61,,1514764800000000000
+func (s *SerializerCSV) SerializePoint(w io.Writer, p *Point) error {
+	if s.spill == nil {
+		f, err := ioutil.TempFile("", "bulk_data_gen-csv")
+		if err != nil {
+			return err
+		}
+		// the open file stays usable, and is left behind by no exit path
+		os.Remove(f.Name())
+		s.spill = f
+		s.spillBuf = bufio.NewWriterSize(f, 4<<20)
+		s.spillW = csv.NewWriter(s.spillBuf)
+	}
+
+	// spilled record: measurement,time,<column index>,<value>,...
+	record := append(s.record[:0], string(p.MeasurementName), strconv.FormatInt(p.Timestamp.UnixNano(), 10))
+	for i, k := range p.TagKeys {
+		c, err := s.column(string(k), true, "tag")
+		if err != nil {
+			return err
+		}
+		record = append(record, strconv.Itoa(c), string(p.TagValues[i]))
+	}
+	for i, k := range p.FieldKeys {
+		c, err := s.column(string(k), false, csvAnnotatedType(p.FieldValues[i]))
+		if err != nil {
+			return err
+		}
+		record = append(record, strconv.Itoa(c), csvFormatValue(p.FieldValues[i]))
+	}
+	s.record = record
+	return s.spillW.Write(record)
+}
+
+// column returns the index of the column of a tag or field key, adding it if
+// new.
+func (s *SerializerCSV) column(name string, tag bool, typ string) (int, error) {
+	i, ok := s.index[name]
+	if !ok {
+		s.index[name] = len(s.columns)
+		s.columns = append(s.columns, csvColumn{name: name, tag: tag, typ: typ})
+		return len(s.columns) - 1, nil
+	}
+	c := &s.columns[i]
+	if c.tag != tag {
+		return 0, fmt.Errorf("csv: %s is both a tag and a field key", name)
+	}
+	if c.typ != typ {
+		switch {
+		case c.typ == "long" && typ == "double":
+			c.typ = "double"
+		case c.typ == "double" && typ == "long":
+		default:
+			if s.annotated {
+				return 0, fmt.Errorf("csv: field %s has both %s and %s values", name, c.typ, typ)
+			}
+		}
+	}
+	return i, nil
+}
+
+// SerializeSize writes out the table. The dataset size marker is not
+// written, as it would break CSV importers.
+func (s *SerializerCSV) SerializeSize(w io.Writer, points int64, values int64) error {
+	if s.spill == nil {
+		return nil
+	}
+	defer func() {
+		s.spill.Close()
+		s.spill = nil
+	}()
+	s.spillW.Flush()
+	if err := s.spillW.Error(); err != nil {
+		return err
+	}
+	if err := s.spillBuf.Flush(); err != nil {
+		return err
+	}
+	if _, err := s.spill.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	// tag columns first, then field columns, in the order they were seen
+	order := make([]int, 0, len(s.columns))
+	for i, c := range s.columns {
+		if c.tag {
+			order = append(order, i)
+		}
+	}
+	for i, c := range s.columns {
+		if !c.tag {
+			order = append(order, i)
+		}
+	}
+	position := make([]int, len(s.columns))
+	for p, i := range order {
+		position[i] = p
+	}
+
+	bw := bufio.NewWriterSize(w, 4<<20)
+	out := csv.NewWriter(bw)
+	header := make([]string, 0, len(order)+2)
+	if s.annotated {
+		// annotation rows cannot be quoted, column types never need to be
+		bw.WriteString("#datatype measurement")
+		for _, i := range order {
+			bw.WriteByte(',')
+			bw.WriteString(s.columns[i].typ)
+		}
+		bw.WriteString(",dateTime:number\n")
+		header = append(header, "measurement")
+	} else {
+		header = append(header, "measurement", "time")
+	}
+	for _, i := range order {
+		header = append(header, s.columns[i].name)
+	}
+	if s.annotated {
+		header = append(header, "time")
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(header))
+	offset := 2 // measurement,time
+	if s.annotated {
+		offset = 1
+	}
+	r := csv.NewReader(bufio.NewReaderSize(s.spill, 4<<20))
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for i := range row {
+			row[i] = ""
+		}
+		row[0] = record[0]
+		nanos, err := strconv.ParseInt(record[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		if s.annotated {
+			row[len(row)-1] = record[1]
+		} else {
+			row[1] = time.Unix(0, nanos).UTC().Format(time.RFC3339Nano)
+		}
+		for j := 2; j+1 < len(record); j += 2 {
+			c, err := strconv.Atoi(record[j])
+			if err != nil {
+				return err
+			}
+			row[offset+position[c]] = record[j+1]
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func csvFormatValue(v interface{}) string {
+	switch x := v.(type) {
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	default:
+		return string(fastFormatAppend(v, nil, false))
+	}
+}
+
+func csvAnnotatedType(v interface{}) string {
+	switch v.(type) {
+	case int, int64:
+		return "long"
+	case float64, float32:
+		return "double"
+	case bool:
+		return "boolean"
+	case []byte, string:
+		return "string"
+	default:
+		panic(fmt.Sprintf("unknown field type for %#v", v))
+	}
+}
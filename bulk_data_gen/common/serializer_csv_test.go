@@ -0,0 +1,100 @@
+package common
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func testCSVPoints() []*Point {
+	t0 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(10 * time.Second)
+	cpu := &Point{}
+	cpu.SetMeasurementName([]byte("cpu"))
+	cpu.SetTimestamp(&t0)
+	cpu.AppendTag([]byte("hostname"), []byte("host_0"))
+	cpu.AppendField([]byte("usage_user"), 0.5)
+	mem := &Point{}
+	mem.SetMeasurementName([]byte("mem"))
+	mem.SetTimestamp(&t0)
+	mem.AppendTag([]byte("hostname"), []byte("host_0"))
+	mem.AppendField([]byte("free"), int64(1024))
+	// a field which was not in the first point of the measurement
+	cpu2 := &Point{}
+	cpu2.SetMeasurementName([]byte("cpu"))
+	cpu2.SetTimestamp(&t1)
+	cpu2.AppendTag([]byte("hostname"), []byte("host_0"))
+	cpu2.AppendTag([]byte("region"), []byte("eu-west-1"))
+	cpu2.AppendField([]byte("usage_user"), 0.25)
+	cpu2.AppendField([]byte("usage_system"), 2.5)
+	return []*Point{cpu, mem, cpu2}
+}
+
+func TestSerializerCSV(t *testing.T) {
+	cases := []struct {
+		name       string
+		serializer *SerializerCSV
+		expected   string
+	}{
+		{
+			name:       "plain",
+			serializer: NewSerializerCSV(),
+			expected: "measurement,time,hostname,region,usage_user,free,usage_system\n" +
+				"cpu,2018-01-01T00:00:00Z,host_0,,0.5000000000000000,,\n" +
+				"mem,2018-01-01T00:00:00Z,host_0,,,1024,\n" +
+				"cpu,2018-01-01T00:00:10Z,host_0,eu-west-1,0.2500000000000000,,2.5000000000000000\n",
+		},
+		{
+			name:       "annotated",
+			serializer: NewSerializerInfluxAnnotatedCSV(),
+			expected: "#datatype measurement,tag,tag,double,long,double,dateTime:number\n" +
+				"measurement,hostname,region,usage_user,free,usage_system,time\n" +
+				"cpu,host_0,,0.5000000000000000,,,1514764800000000000\n" +
+				"mem,host_0,,,1024,,1514764800000000000\n" +
+				"cpu,host_0,eu-west-1,0.2500000000000000,,2.5000000000000000,1514764810000000000\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			for _, p := range testCSVPoints() {
+				require.NoError(t, c.serializer.SerializePoint(&out, p))
+			}
+			require.Equal(t, 0, out.Len(), "rows are written once all the columns are known")
+			require.NoError(t, c.serializer.SerializeSize(&out, 3, 4))
+			require.Equal(t, c.expected, out.String())
+		})
+	}
+}
+
+func TestSerializerCSVColumnConflicts(t *testing.T) {
+	t0 := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	newPoint := func(name string, value interface{}) *Point {
+		p := &Point{}
+		p.SetMeasurementName([]byte(name))
+		p.SetTimestamp(&t0)
+		p.AppendField([]byte("used_percent"), value)
+		return p
+	}
+
+	// integer and float values of a field are written as double
+	s := NewSerializerInfluxAnnotatedCSV()
+	var out bytes.Buffer
+	require.NoError(t, s.SerializePoint(&out, newPoint("disk", int64(50))))
+	require.NoError(t, s.SerializePoint(&out, newPoint("mem", 50.5)))
+	require.NoError(t, s.SerializeSize(&out, 2, 2))
+	require.Contains(t, out.String(), "#datatype measurement,double,dateTime:number\n")
+
+	s = NewSerializerInfluxAnnotatedCSV()
+	require.NoError(t, s.SerializePoint(&out, newPoint("disk", int64(50))))
+	require.Error(t, s.SerializePoint(&out, newPoint("mem", "full")))
+
+	s = NewSerializerCSV()
+	tagged := newPoint("disk", int64(50))
+	tagged.FieldKeys, tagged.FieldValues = nil, nil
+	tagged.AppendTag([]byte("used_percent"), []byte("50"))
+	require.NoError(t, s.SerializePoint(&out, tagged))
+	require.Error(t, s.SerializePoint(&out, newPoint("mem", int64(50))))
+	require.NoError(t, s.SerializeSize(&out, 1, 0))
+}
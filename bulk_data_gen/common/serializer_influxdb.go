@@ -2,13 +2,22 @@ package common
 
 import (
 	"io"
+	"time"
 )
 
 type serializerInflux struct {
+	precision int64
 }
 
 func NewSerializerInflux() *serializerInflux {
-	return &serializerInflux{}
+	return NewSerializerInfluxWithPrecision(time.Nanosecond)
+}
+
+// NewSerializerInfluxWithPrecision creates serializer writing timestamps in
+// units of the given precision, which must match the precision parameter
+// used when writing.
+func NewSerializerInfluxWithPrecision(precision time.Duration) *serializerInflux {
+	return &serializerInflux{precision: int64(precision)}
 }
 
 // SerializeInfluxBulk writes Point data to the given writer, conforming to the
@@ -55,7 +64,7 @@ func (s *serializerInflux) SerializePoint(w io.Writer, p *Point) (err error) {
 	}
 
 	buf = append(buf, ' ')
-	buf = fastFormatAppend(p.Timestamp.UTC().UnixNano(), buf, true)
+	buf = fastFormatAppend(p.Timestamp.UTC().UnixNano()/s.precision, buf, true)
 	buf = append(buf, '\n')
 	_, err = w.Write(buf)
 
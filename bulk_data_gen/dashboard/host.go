@@ -4,7 +4,6 @@ import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
 	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/devops"
-	"math/rand"
 	"time"
 )
 
@@ -70,10 +69,10 @@ func NewHost(i int, offset int, start time.Time) Host {
 	}
 	sm := NewHostMeasurements(start)
 
-	region := &devops.Regions[rand.Intn(len(devops.Regions))]
-	rackId := rand.Int63n(devops.MachineRackChoicesPerDatacenter)
-	serviceId := rand.Int63n(devops.MachineServiceChoices)
-	serviceVersionId := rand.Int63n(devops.MachineServiceVersionChoices)
+	region := &devops.Regions[Rand.Intn(len(devops.Regions))]
+	rackId := Rand.Int63n(devops.MachineRackChoicesPerDatacenter)
+	serviceId := Rand.Int63n(devops.MachineServiceChoices)
+	serviceVersionId := Rand.Int63n(devops.MachineServiceVersionChoices)
 	serviceEnvironment := RandChoice(devops.MachineServiceEnvironmentChoices)
 	clusterOffset := offset / ClusterSize
 
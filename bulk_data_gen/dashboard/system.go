@@ -2,7 +2,6 @@ package dashboard
 
 import (
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -30,12 +29,12 @@ type SystemMeasurement struct {
 
 func NewSystemMeasurement(start time.Time) *SystemMeasurement {
 	distributions := make([]Distribution, len(LoadFieldKeys))
-	ncpus := CPUsCount[rand.Intn(len(CPUsCount))]
+	ncpus := CPUsCount[Rand.Intn(len(CPUsCount))]
 	for i := range distributions {
 		distributions[i] = &ClampedRandomWalkDistribution{
-			State: rand.Float64() * 100.0 * float64(ncpus),
+			State: Rand.Float64() * 100.0 * float64(ncpus),
 			Min:   0.0,
-			Max:   float64(ncpus) * (1 + rand.Float64()),
+			Max:   float64(ncpus) * (1 + Rand.Float64()),
 			Step: &NormalDistribution{
 				Mean:   0.0,
 				StdDev: 10.0,
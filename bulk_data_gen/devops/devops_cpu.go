@@ -2,7 +2,6 @@ package devops
 
 import (
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -36,7 +35,7 @@ func NewCPUMeasurement(start time.Time) *CPUMeasurement {
 	distributions := make([]Distribution, len(CPUFieldKeys))
 	for i := range distributions {
 		distributions[i] = &ClampedRandomWalkDistribution{
-			State: rand.Float64() * 100.0,
+			State: Rand.Float64() * 100.0,
 			Min:   0.0,
 			Max:   100.0,
 			Step: &NormalDistribution{
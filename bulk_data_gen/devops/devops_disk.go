@@ -3,7 +3,6 @@ package devops
 import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -40,10 +39,10 @@ type DiskMeasurement struct {
 
 func NewDiskMeasurement(start time.Time, sda int) *DiskMeasurement {
 	if sda == 0 {
-		sda = rand.Intn(10)
+		sda = Rand.Intn(10)
 	}
 	path := []byte(fmt.Sprintf("/dev/sda%d", sda))
-	fsType := DiskFSTypeChoices[rand.Intn(len(DiskFSTypeChoices))]
+	fsType := DiskFSTypeChoices[Rand.Intn(len(DiskFSTypeChoices))]
 	if Config != nil { // partial override from external config
 		path = Config.GetTagBytesValue(DiskByteString, DiskTags[0], true, path)
 		fsType = Config.GetTagBytesValue(DiskByteString, DiskTags[1], true, fsType)
@@ -3,7 +3,6 @@ package devops
 import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -35,7 +34,7 @@ func NewDiskIOMeasurement(start time.Time) *DiskIOMeasurement {
 		distributions[i] = DiskIOFields[i].DistributionMaker()
 	}
 
-	serial := []byte(fmt.Sprintf("%03d-%03d-%03d", rand.Intn(1000), rand.Intn(1000), rand.Intn(1000)))
+	serial := []byte(fmt.Sprintf("%03d-%03d-%03d", Rand.Intn(1000), Rand.Intn(1000), Rand.Intn(1000)))
 	if Config != nil { // partial override from external config
 		serial = Config.GetTagBytesValue(DiskIOByteString, SerialByteString, true, serial)
 	}
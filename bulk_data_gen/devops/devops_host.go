@@ -3,7 +3,6 @@ package devops
 import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -159,10 +158,10 @@ func NewHostMeasurements(start time.Time) []SimulatedMeasurement {
 func NewHost(i int, offset int, start time.Time) Host {
 	sm := NewHostMeasurements(start)
 
-	region := &Regions[rand.Intn(len(Regions))]
-	rackId := rand.Int63n(MachineRackChoicesPerDatacenter)
-	serviceId := rand.Int63n(MachineServiceChoices)
-	serviceVersionId := rand.Int63n(MachineServiceVersionChoices)
+	region := &Regions[Rand.Intn(len(Regions))]
+	rackId := Rand.Int63n(MachineRackChoicesPerDatacenter)
+	serviceId := Rand.Int63n(MachineServiceChoices)
+	serviceVersionId := Rand.Int63n(MachineServiceVersionChoices)
 	serviceEnvironment := RandChoice(MachineServiceEnvironmentChoices)
 
 	h := Host{
@@ -2,7 +2,6 @@ package devops
 
 import (
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -32,7 +31,7 @@ func NewKernelMeasurement(start time.Time) *KernelMeasurement {
 		distributions[i] = KernelFields[i].DistributionMaker()
 	}
 
-	bootTime := rand.Int63n(240)
+	bootTime := Rand.Int63n(240)
 	return &KernelMeasurement{
 		bootTime: bootTime,
 
@@ -3,7 +3,6 @@ package devops
 import (
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
 	"math"
-	"math/rand"
 	"time"
 )
 
@@ -37,9 +36,9 @@ type MemMeasurement struct {
 }
 
 func NewMemMeasurement(start time.Time) *MemMeasurement {
-	bytesTotal := MemoryMaxBytesChoices[rand.Intn(len(MemoryMaxBytesChoices))]
+	bytesTotal := MemoryMaxBytesChoices[Rand.Intn(len(MemoryMaxBytesChoices))]
 	bytesUsedDist := &ClampedRandomWalkDistribution{
-		State: rand.Float64() * float64(bytesTotal),
+		State: Rand.Float64() * float64(bytesTotal),
 		Min:   0.0,
 		Max:   float64(bytesTotal),
 		Step: &NormalDistribution{
@@ -48,7 +47,7 @@ func NewMemMeasurement(start time.Time) *MemMeasurement {
 		},
 	}
 	bytesCachedDist := &ClampedRandomWalkDistribution{
-		State: rand.Float64() * float64(bytesTotal),
+		State: Rand.Float64() * float64(bytesTotal),
 		Min:   0.0,
 		Max:   float64(bytesTotal),
 		Step: &NormalDistribution{
@@ -57,7 +56,7 @@ func NewMemMeasurement(start time.Time) *MemMeasurement {
 		},
 	}
 	bytesBufferedDist := &ClampedRandomWalkDistribution{
-		State: rand.Float64() * float64(bytesTotal),
+		State: Rand.Float64() * float64(bytesTotal),
 		Min:   0.0,
 		Max:   float64(bytesTotal),
 		Step: &NormalDistribution{
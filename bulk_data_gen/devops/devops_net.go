@@ -3,7 +3,6 @@ package devops
 import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -39,7 +38,7 @@ func NewNetMeasurement(start time.Time) *NetMeasurement {
 		distributions[i] = NetFields[i].DistributionMaker()
 	}
 
-	interfaceName := []byte(fmt.Sprintf("eth%d", rand.Intn(4)))
+	interfaceName := []byte(fmt.Sprintf("eth%d", Rand.Intn(4)))
 	if Config != nil { // partial override from external config
 		interfaceName = Config.GetTagBytesValue(NetByteString, NetTags[0], true, interfaceName)
 	}
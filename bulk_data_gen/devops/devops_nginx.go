@@ -3,7 +3,6 @@ package devops
 import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -39,8 +38,8 @@ func NewNginxMeasurement(start time.Time) *NginxMeasurement {
 		distributions[i] = NginxFields[i].DistributionMaker()
 	}
 
-	serverName := []byte(fmt.Sprintf("nginx_%d", rand.Intn(100000)))
-	port := []byte(fmt.Sprintf("%d", rand.Intn(20000)+1024))
+	serverName := []byte(fmt.Sprintf("nginx_%d", Rand.Intn(100000)))
+	port := []byte(fmt.Sprintf("%d", Rand.Intn(20000)+1024))
 	if Config != nil { // partial override from external config
 		serverName = Config.GetTagBytesValue(NginxByteString, NginxTags[1], true, serverName)
 		port = Config.GetTagBytesValue(NginxByteString, NginxTags[0], true, port)
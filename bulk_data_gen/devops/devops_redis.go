@@ -3,7 +3,6 @@ package devops
 import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -74,8 +73,8 @@ func NewRedisMeasurement(start time.Time) *RedisMeasurement {
 		distributions[i] = RedisFields[i].DistributionMaker()
 	}
 
-	serverName := []byte(fmt.Sprintf("redis_%d", rand.Intn(100000)))
-	port := []byte(fmt.Sprintf("%d", rand.Intn(20000)+1024))
+	serverName := []byte(fmt.Sprintf("redis_%d", Rand.Intn(100000)))
+	port := []byte(fmt.Sprintf("%d", Rand.Intn(20000)+1024))
 	if Config != nil { // partial override from external config
 		serverName = Config.GetTagBytesValue(RedisByteString, RedisTags[1], true, serverName)
 		port = Config.GetTagBytesValue(RedisByteString, RedisTags[0], true, port)
@@ -31,7 +31,7 @@ func NewAirConditionOutdoorMeasurement(start time.Time, id []byte) *AirCondition
 	//humidity
 	distributions[1] = MUDWD(ND(0, 1), 5, 95, 80)
 	//battery_voltage
-	distributions[2] = MUDWD(ND(0.01, 0.005), 1, 3.2, 3.2)
+	distributions[2] = MUDWD(ND(0.01, 0.005), BatteryVoltageMin, BatteryVoltageMax, BatteryVoltageMax)
 
 	return &AirConditionOutdoorMeasurement{
 		timestamp:     start,
@@ -2,7 +2,6 @@ package iot
 
 import (
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -60,7 +59,7 @@ type CameraDetectionMeasurement struct {
 func NewCameraDetectionMeasurement(start time.Time, id []byte) *CameraDetectionMeasurement {
 
 	//battery_voltage
-	batteryDist := MUDWD(ND(0.01, 0.005), 1, 3.2, 3.2)
+	batteryDist := MUDWD(ND(0.01, 0.005), BatteryVoltageMin, BatteryVoltageMax, BatteryVoltageMax)
 
 	cd := &CameraDetectionMeasurement{
 		timestamp:   start,
@@ -72,17 +71,17 @@ func NewCameraDetectionMeasurement(start time.Time, id []byte) *CameraDetectionM
 }
 
 func (m *CameraDetectionMeasurement) newDetection() {
-	object := rand.Int63n(int64(len(DetectionObjects)))
+	object := Rand.Int63n(int64(len(DetectionObjects)))
 	m.object = DetectionObjects[object]
 	switch object {
 	case 0: //animal
-		m.kind = Animals[rand.Int63n(int64(len(Animals)))]
+		m.kind = Animals[Rand.Int63n(int64(len(Animals)))]
 		break
 	case 1: //human
-		m.kind = Humans[rand.Int63n(int64(len(Humans)))]
+		m.kind = Humans[Rand.Int63n(int64(len(Humans)))]
 		break
 	case 2: //vehicle
-		m.kind = Vehicles[rand.Int63n(int64(len(Vehicles)))]
+		m.kind = Vehicles[Rand.Int63n(int64(len(Vehicles)))]
 		break
 	case 3: //uknown
 		m.kind = []byte("uknown")
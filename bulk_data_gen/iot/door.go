@@ -30,7 +30,7 @@ func NewDoorMeasurement(start time.Time, doorId []byte, sendorId []byte) *DoorMe
 	//state
 	distributions[0] = TSD(0, 1, 0)
 	//battery_voltage
-	distributions[1] = MUDWD(ND(0.01, 0.005), 1, 3.2, 3.2)
+	distributions[1] = MUDWD(ND(0.01, 0.005), BatteryVoltageMin, BatteryVoltageMax, BatteryVoltageMax)
 
 	return &DoorMeasurement{
 		timestamp:     start,
@@ -2,7 +2,6 @@ package iot
 
 import (
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -33,7 +32,7 @@ func NewHomeConfigMeasurement(start time.Time, id []byte) *HomeConfigMeasurement
 		lastChange:     start,
 		sensorId:       id,
 		config:         genRandomString(),
-		changeInterval: time.Hour * time.Duration(rand.Int63n(12)+1),
+		changeInterval: time.Hour * time.Duration(Rand.Int63n(12)+1),
 	}
 }
 
@@ -42,7 +41,7 @@ func (m *HomeConfigMeasurement) Tick(d time.Duration) {
 	//change config only in random 12 hours interval
 	if m.timestamp.Sub(m.lastChange) > m.changeInterval {
 		m.config = genRandomString()
-		m.changeInterval = time.Hour * time.Duration(rand.Int63n(12)+1)
+		m.changeInterval = time.Hour * time.Duration(Rand.Int63n(12)+1)
 		m.updateValue = true
 		m.lastChange = m.timestamp
 	} else {
@@ -62,12 +61,12 @@ func (m *HomeConfigMeasurement) ToPoint(p *Point) bool {
 
 func genRandomString() []byte {
 	//len 10-20k
-	len := int((rand.Int63n(10) + 10) * 1024)
+	len := int((Rand.Int63n(10) + 10) * 1024)
 	buff := make([]byte, len)
 	for i := 0; i < len; i++ {
-		buff[i] = byte(rand.Int63n(87) + 40)
+		buff[i] = byte(Rand.Int63n(87) + 40)
 		for buff[i] == 92 {
-			buff[i] = byte(rand.Int63n(87) + 40)
+			buff[i] = byte(Rand.Int63n(87) + 40)
 		}
 	}
 	return buff
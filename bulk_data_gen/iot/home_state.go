@@ -2,7 +2,6 @@ package iot
 
 import (
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
@@ -40,7 +39,7 @@ func NewHomeStateMeasurement(start time.Time, id []byte) *HomeStateMeasurement {
 
 func (m *HomeStateMeasurement) Tick(d time.Duration) {
 	m.timestamp = m.timestamp.Add(d)
-	m.state = rand.Int63n(int64(len(HomeStates)))
+	m.state = Rand.Int63n(int64(len(HomeStates)))
 }
 
 func (m *HomeStateMeasurement) ToPoint(p *Point) bool {
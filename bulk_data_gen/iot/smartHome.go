@@ -3,10 +3,17 @@ package iot
 import (
 	"fmt"
 	. "github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
-	"math/rand"
 	"time"
 )
 
+// BatteryVoltageMin and BatteryVoltageMax bound the battery_voltage of the
+// battery powered sensors, which walks from BatteryVoltageMax down to
+// BatteryVoltageMin and back up.
+const (
+	BatteryVoltageMin = 1.0
+	BatteryVoltageMax = 3.2
+)
+
 var (
 	// The duration of a log epoch.
 	EpochDuration = 60 * time.Second
@@ -95,7 +102,7 @@ func (h *SmartHome) NumMeasurements() int {
 
 func (h *SmartHome) NewRoom(id int, start time.Time) *room {
 	h.lastRoomId++
-	windowsNum := int(rand.Int63n(3) + 1)
+	windowsNum := int(Rand.Int63n(3) + 1)
 	sm := make([]SimulatedMeasurement, 0, windowsNum*2+3)
 	for w := 0; w < windowsNum; w++ {
 		sm = append(sm, NewWindowMeasurement(start, []byte(fmt.Sprintf("%d", w+1)), NewSensorId()),
@@ -110,12 +117,12 @@ func (h *SmartHome) NewRoom(id int, start time.Time) *room {
 
 func (h *SmartHome) NewSmartHomeMeasurements(start time.Time) {
 
-	roomsNum := rand.Int63n(6) + 4
+	roomsNum := Rand.Int63n(6) + 4
 	h.Rooms = make([]*room, roomsNum)
 	for i := 0; i < int(roomsNum); i++ {
 		h.Rooms[i] = h.NewRoom(i+1, start)
 	}
-	doorsNum := rand.Int63n(3) + 1
+	doorsNum := Rand.Int63n(3) + 1
 
 	h.SimulatedMeasurements = []SimulatedMeasurement{
 		NewAirConditionOutdoorMeasurement(start, NewSensorId()),
@@ -124,8 +131,8 @@ func (h *SmartHome) NewSmartHomeMeasurements(start time.Time) {
 		NewHomeConfigMeasurement(start, NewSensorId()),
 		NewCameraDetectionMeasurement(start, NewSensorId()),
 		NewWaterLevelMeasurement(start, NewSensorId()),
-		NewWaterLeakageRoomMeasurement(start, []byte(fmt.Sprintf("%d", rand.Int63n(roomsNum)+1)), NewSensorId()),
-		NewWaterLeakageRoomMeasurement(start, []byte(fmt.Sprintf("%d", rand.Int63n(roomsNum)+1)), NewSensorId()),
+		NewWaterLeakageRoomMeasurement(start, []byte(fmt.Sprintf("%d", Rand.Int63n(roomsNum)+1)), NewSensorId()),
+		NewWaterLeakageRoomMeasurement(start, []byte(fmt.Sprintf("%d", Rand.Int63n(roomsNum)+1)), NewSensorId()),
 	}
 	for i := 0; i < int(doorsNum); i++ {
 		h.SimulatedMeasurements = append(h.SimulatedMeasurements, NewDoorMeasurement(start, []byte(fmt.Sprintf("%d", i)), NewSensorId()))
@@ -28,7 +28,7 @@ func NewWaterLevelMeasurement(start time.Time, id []byte) *WaterLevelMeasurement
 	//level
 	distributions[0] = MUDWD(ND(0, 1), 0.0, 8000, 5000)
 	//battery_voltage
-	distributions[1] = MUDWD(ND(0.01, 0.005), 1, 3.2, 3.2)
+	distributions[1] = MUDWD(ND(0.01, 0.005), BatteryVoltageMin, BatteryVoltageMax, BatteryVoltageMax)
 
 	return &WaterLevelMeasurement{
 		timestamp:     start,
@@ -0,0 +1,168 @@
+package bulk_load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CheckpointVersion is the version of the checkpoint file format.
+const CheckpointVersion = 1
+
+// Checkpoint records how far a load got: all the input items before Items
+// were read and, unless skipped on resume, written by fully acknowledged
+// batches.
+type Checkpoint struct {
+	Version  int       `json:"version"`
+	Input    string    `json:"input"`
+	Items    int64     `json:"items"`
+	Complete bool      `json:"complete"`
+	Time     time.Time `json:"time"`
+}
+
+// checkpointer tracks the batches in flight, identified by a key unique
+// while in flight (e.g. the pointer to their buffer), to advance the number
+// of input items of which all the batches were acknowledged, whatever the
+// order in which the workers finish them.
+type checkpointer struct {
+	mutex   sync.Mutex
+	path    string
+	input   string
+	skip    int64
+	skipped int64
+	// items skipped or written by the batches before the oldest in flight
+	items   int64
+	nextSeq uint64
+	pending map[interface{}]uint64
+	// batches since the oldest in flight, the first one has firstSeq
+	queue    []checkpointBatch
+	firstSeq uint64
+	// items read since the last batch, not skipped
+	unbatched int
+}
+
+type checkpointBatch struct {
+	items int
+	acked bool
+}
+
+func newCheckpointer(path, input string, skip int64) *checkpointer {
+	return &checkpointer{
+		path:    path,
+		input:   input,
+		skip:    skip,
+		items:   skip,
+		pending: make(map[interface{}]uint64),
+	}
+}
+
+// readCheckpoint reads a checkpoint file, nil if it does not exist.
+func readCheckpoint(path string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if c.Version != CheckpointVersion {
+		return nil, fmt.Errorf("%s: unsupported checkpoint version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// skipItem tells whether the next input item was loaded before the
+// checkpoint. It is called by the scanner for every item.
+func (c *checkpointer) skipItem() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.skipped < c.skip {
+		c.skipped++
+		return true
+	}
+	c.unbatched++
+	return false
+}
+
+// sent records a batch holding the items read since the previous one.
+func (c *checkpointer) sent(key interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.pending[key] = c.nextSeq
+	c.nextSeq++
+	c.queue = append(c.queue, checkpointBatch{items: c.unbatched})
+	c.unbatched = 0
+}
+
+// acked records a batch as written.
+func (c *checkpointer) acked(key interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	seq, ok := c.pending[key]
+	if !ok {
+		return
+	}
+	delete(c.pending, key)
+	c.queue[seq-c.firstSeq].acked = true
+	for len(c.queue) > 0 && c.queue[0].acked {
+		c.items += int64(c.queue[0].items)
+		c.queue = c.queue[1:]
+		c.firstSeq++
+	}
+}
+
+// write writes the checkpoint file atomically.
+func (c *checkpointer) write(complete bool) error {
+	c.mutex.Lock()
+	checkpoint := Checkpoint{
+		Version:  CheckpointVersion,
+		Input:    c.input,
+		Items:    c.items,
+		Complete: complete,
+		Time:     time.Now().UTC(),
+	}
+	c.mutex.Unlock()
+	data, err := json.MarshalIndent(&checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// run writes the checkpoint every interval until stop is closed.
+func (c *checkpointer) run(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.write(false); err != nil {
+				log.Printf("Error writing checkpoint: %v\n", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
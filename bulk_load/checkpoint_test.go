@@ -0,0 +1,189 @@
+package bulk_load
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckpointerItems(t *testing.T) {
+	// a step reads items and sends them as a batch, or acknowledges a batch
+	type step struct {
+		send  int
+		ack   int // 1-based batch number
+		items int64
+	}
+	cases := []struct {
+		name  string
+		skip  int64
+		steps []step
+	}{
+		{
+			name: "in order",
+			steps: []step{
+				{send: 3, items: 0},
+				{send: 2, items: 0},
+				{ack: 1, items: 3},
+				{ack: 2, items: 5},
+			},
+		},
+		{
+			name: "out of order",
+			steps: []step{
+				{send: 3, items: 0},
+				{send: 2, items: 0},
+				{send: 4, items: 0},
+				{ack: 3, items: 0},
+				{ack: 2, items: 0},
+				{ack: 1, items: 9},
+			},
+		},
+		{
+			name: "duplicated ack",
+			steps: []step{
+				{send: 3, items: 0},
+				{send: 2, items: 0},
+				{ack: 2, items: 0},
+				{ack: 2, items: 0},
+				{ack: 1, items: 5},
+			},
+		},
+		{
+			name: "resumed",
+			skip: 4,
+			steps: []step{
+				{send: 6, items: 4},
+				{send: 1, items: 4},
+				{ack: 2, items: 4},
+				{ack: 1, items: 11},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cp := newCheckpointer("", "", c.skip)
+			for i := int64(0); i < c.skip; i++ {
+				require.True(t, cp.skipItem())
+			}
+			var keys []*int
+			for _, s := range c.steps {
+				if s.send > 0 {
+					for i := 0; i < s.send; i++ {
+						require.False(t, cp.skipItem())
+					}
+					key := new(int)
+					keys = append(keys, key)
+					cp.sent(key)
+				} else {
+					cp.acked(keys[s.ack-1])
+				}
+				require.Equal(t, s.items, cp.items)
+			}
+		})
+	}
+}
+
+func TestCheckpointerSkip(t *testing.T) {
+	cp := newCheckpointer("", "", 3)
+	for i := 0; i < 3; i++ {
+		require.True(t, cp.skipItem())
+	}
+	require.False(t, cp.skipItem())
+	require.Equal(t, 1, cp.unbatched)
+	require.Equal(t, int64(3), cp.items)
+}
+
+func TestCheckpointFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "load.checkpoint")
+
+	c, err := readCheckpoint(path)
+	require.NoError(t, err)
+	require.Nil(t, c, "no checkpoint file yet")
+
+	cp := newCheckpointer(path, "data.txt", 0)
+	for i := 0; i < 7; i++ {
+		cp.skipItem()
+	}
+	cp.sent(cp)
+	cp.acked(cp)
+	require.NoError(t, cp.write(false))
+	c, err = readCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, CheckpointVersion, c.Version)
+	require.Equal(t, "data.txt", c.Input)
+	require.Equal(t, int64(7), c.Items)
+	require.False(t, c.Complete)
+
+	require.NoError(t, cp.write(true))
+	c, err = readCheckpoint(path)
+	require.NoError(t, err)
+	require.True(t, c.Complete)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1, "temporary files are renamed")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"version": 2}`), 0644))
+	_, err = readCheckpoint(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unsupported checkpoint version")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"version":`), 0644))
+	_, err = readCheckpoint(path)
+	require.Error(t, err)
+}
+
+func TestResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "load.checkpoint")
+
+	// the interrupted load: of the 3 batches of 3 items, the first and the
+	// last one were acknowledged
+	first := &LoadRunner{checkpointFile: path, file: "data.txt"}
+	first.openCheckpoint()
+	require.False(t, first.resuming())
+	for batch := 0; batch < 3; batch++ {
+		for i := 0; i < 3; i++ {
+			require.False(t, first.SkipItem())
+		}
+		first.checkpointer.sent(batch)
+	}
+	first.checkpointer.acked(0)
+	first.checkpointer.acked(2)
+	require.NoError(t, first.checkpointer.write(false))
+
+	// the resumed load skips the items of the first batch only
+	resumed := &LoadRunner{checkpointFile: path, file: "data.txt", resume: true}
+	resumed.openCheckpoint()
+	require.True(t, resumed.resuming())
+	for i := 0; i < 3; i++ {
+		require.True(t, resumed.SkipItem())
+	}
+	require.False(t, resumed.SkipItem())
+}
+
+func TestLoadedValues(t *testing.T) {
+	cases := []struct {
+		name    string
+		values  int64
+		loaded  int64
+		skipped int64
+		result  int64
+	}{
+		{name: "nothing skipped", values: 100, loaded: 10, result: 100},
+		{name: "half skipped", values: 100, loaded: 5, skipped: 5, result: 50},
+		{name: "all skipped", values: 100, skipped: 10, result: 0},
+		{name: "nothing read", values: 0, result: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.result, LoadedValues(c.values, c.loaded, c.skipped))
+		})
+	}
+}
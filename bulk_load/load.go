@@ -1,9 +1,12 @@
 package bulk_load
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"github.com/pkg/profile"
+	"hash"
 	"io"
 	"log"
 	"math"
@@ -14,11 +17,14 @@ import (
 	"runtime"
 	"runtime/pprof"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
+	"github.com/influxdata/influxdb-comparisons/util/coordinator"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 )
 
@@ -52,6 +58,7 @@ type LoadRunner struct {
 	consistency            string
 	telemetryStderr        bool
 	telemetryBatchSize     uint64
+	telemetryOptions       report.TelemetryOptions
 	reportDatabase         string
 	reportBucketId         string
 	reportHost             string
@@ -59,22 +66,42 @@ type LoadRunner struct {
 	reportPassword         string
 	reportTagsCSV          string
 	reportTelemetry        bool
+	reportResources        bool
+	resourcesPid           int
 	reportOrgId            string
 	reportAuthToken        string
+	reportSinks            string
+	reportPrometheusLinger time.Duration
+	summaryFile            string
 	notificationListenPort int
 	printInterval          uint64
 	trendSamples           int
 	movingAverageInterval  time.Duration
 	file                   string
+	manifestFile           string
+	coordinatorAddr        string
+	checkpointFile         string
+	checkpointInterval     time.Duration
+	resume                 bool
+	retryPolicy            RetryPolicy
+	deadLetterFile         string
 
 	backingOffChans       []chan bool
 	backingOffDones       []chan struct{}
 	telemetryChanPoints   chan *report.Point
-	telemetryChanDone     chan struct{}
+	telemetryChanDone     chan report.TelemetryStats
+	telemetryStats        *report.TelemetryStats
+	resourceSampler       *report.ResourceSampler
+	agent                 *coordinator.Agent
+	checkpointer          *checkpointer
+	deadLetter            *deadLetterFile
+	retriedWrites         int64
+	deadLetterBatches     int64
 	syncChanDone          chan int
 	progressIntervalItems uint64
 	reportTags            [][2]string
 	reportHostname        string
+	reportSink            report.Sink
 	ingestionRateGran     float64
 	endedPrematurely      bool
 	prematureEndReason    string
@@ -87,6 +114,10 @@ type LoadRunner struct {
 	movingAverageStat     *TimedStatGroup
 	scanFinished          bool
 	sourceReader          *os.File
+	manifest              *common.Manifest
+	inputHash             hash.Hash
+	errorsMutex           sync.Mutex
+	errors                []string
 }
 
 var Runner = &LoadRunner{}
@@ -121,6 +152,10 @@ func (r *LoadRunner) Init(defaultBatchSize int) {
 	flag.BoolVar(&r.memprofile, "memprofile", false, "Whether to write a memprofile (file automatically determined).")
 	flag.BoolVar(&r.telemetryStderr, "telemetry-stderr", false, "Whether to write telemetry also to stderr.")
 	flag.Uint64Var(&r.telemetryBatchSize, "telemetry-batch-size", 1, "Telemetry batch size (lines).")
+	r.telemetryOptions = report.DefaultTelemetryOptions
+	flag.IntVar(&r.telemetryOptions.BufferSize, "telemetry-buffer", r.telemetryOptions.BufferSize, "Maximum number of telemetry points buffered in memory while the report destination is failing.")
+	flag.IntVar(&r.telemetryOptions.MaxRetries, "telemetry-retries", r.telemetryOptions.MaxRetries, "Number of retries, with exponential backoff, of a telemetry batch before spilling it.")
+	flag.StringVar(&r.telemetryOptions.SpillFile, "telemetry-spill-file", "", "File appended with the telemetry points which could not be delivered, in line protocol (empty to drop them).")
 	flag.StringVar(&r.reportDatabase, "report-database", "database_benchmarks", "Database name where to store result metrics")
 	flag.StringVar(&r.reportHost, "report-host", "", "Host to send result metrics")
 	flag.StringVar(&r.reportUser, "report-user", "", "User for host to send result metrics")
@@ -130,8 +165,22 @@ func (r *LoadRunner) Init(defaultBatchSize int) {
 	flag.StringVar(&r.reportAuthToken, "report-auth-token", "", "Authentication token for InfluxDb 2 where to store metrics")
 	flag.StringVar(&r.reportBucketId, "report-bucket-id", "", "BucketId where to store result metrics (InfluxDb 2). Bucket must exist!")
 	flag.BoolVar(&r.reportTelemetry, "report-telemetry", false, "Turn on/off reporting telemetry")
+	flag.BoolVar(&r.reportResources, "report-resources", false, "Whether to report every second the client CPU, memory, network and GC usage as telemetry (Linux only).")
+	flag.IntVar(&r.resourcesPid, "report-resources-pid", 0, "PID of a local target database process whose CPU and memory usage to report along with -report-resources.")
+	flag.StringVar(&r.reportSinks, "report-sink", "", "Comma separated additional destinations of result metrics and telemetry: json=<file>, csv=<file>, prometheus=<listen address>, stdout. InfluxDB is used when report-host is set.")
+	flag.DurationVar(&r.reportPrometheusLinger, "report-prometheus-linger", 0, "How long the prometheus report sink keeps serving the final results before exiting.")
 	flag.IntVar(&r.notificationListenPort, "notification-port", -1, "Listen port for remote notification messages. Used to remotely finish benchmark. -1 to disable feature")
 	flag.StringVar(&r.file, "file", "", "Input file")
+	flag.StringVar(&r.summaryFile, "summary-file", "", "Write a JSON summary of the run (parameters, tags, throughput, errors, system info) to this file on completion.")
+	flag.StringVar(&r.coordinatorAddr, "coordinator", "", "host:port of a benchmark_coordinator to run as one of its agents: it assigns the client index and workers, and starts the load together with the other agents. A {group} placeholder in -file is replaced with the client index.")
+	flag.StringVar(&r.checkpointFile, "checkpoint-file", "", "File where to record periodically how many input items were loaded by acknowledged batches, to resume the load with -resume.")
+	flag.DurationVar(&r.checkpointInterval, "checkpoint-interval", 10*time.Second, "Interval of writing the checkpoint file.")
+	flag.BoolVar(&r.resume, "resume", false, "Whether to resume the load from the checkpoint file, skipping the input items already loaded (and the database creation).")
+	flag.IntVar(&r.retryPolicy.MaxAttempts, "retry-attempts", 1, "Maximum number of attempts of writing a batch failing with a retryable error (1 to never retry).")
+	flag.DurationVar(&r.retryPolicy.Backoff, "retry-backoff", time.Second, "Delay before the first retry of a batch, doubled before every following one, with jitter.")
+	flag.DurationVar(&r.retryPolicy.MaxBackoff, "retry-max-backoff", 30*time.Second, "Maximum delay between the retries of a batch.")
+	flag.StringVar(&r.deadLetterFile, "dead-letter-file", "", "File appended verbatim with the batches which permanently failed, to be replayed later, instead of aborting the load.")
+	flag.StringVar(&r.manifestFile, "manifest", "", "Dataset manifest written by bulk_data_gen. Input is verified against its content hash and dataset parameters are added to report tags.")
 }
 
 func (r *LoadRunner) SetPrematureEnd(reason string) {
@@ -144,6 +193,9 @@ func (r *LoadRunner) HasEndedPrematurely() bool {
 }
 
 func (r *LoadRunner) Validate() {
+	if r.coordinatorAddr != "" {
+		r.registerAgent()
+	}
 
 	if r.trendSamples <= 0 {
 		r.trendSamples = int(r.movingAverageInterval.Seconds())
@@ -153,6 +205,37 @@ func (r *LoadRunner) Validate() {
 		log.Fatalf("invalid number of Workers: %d\n", r.Workers)
 	}
 
+	if r.telemetryOptions.BufferSize < 1 {
+		log.Fatalf("invalid telemetry buffer size: %d\n", r.telemetryOptions.BufferSize)
+	}
+
+	if r.reportResources && r.reportHost == "" && r.reportSinks == "" {
+		log.Fatalf("invalid configuration: cannot report resources without specified report host or sink")
+	}
+	if r.resourcesPid < 0 || (r.resourcesPid > 0 && !r.reportResources) {
+		log.Fatalf("invalid resources pid: %d (requires -report-resources)\n", r.resourcesPid)
+	}
+
+	if r.retryPolicy.MaxAttempts < 1 {
+		log.Fatalf("invalid number of retry attempts: %d\n", r.retryPolicy.MaxAttempts)
+	}
+	if r.retryPolicy.Backoff < 0 || r.retryPolicy.MaxBackoff < r.retryPolicy.Backoff {
+		log.Fatalf("invalid retry backoff: %v (max %v)\n", r.retryPolicy.Backoff, r.retryPolicy.MaxBackoff)
+	}
+	if r.deadLetterFile != "" {
+		var err error
+		r.deadLetter, err = openDeadLetterFile(r.deadLetterFile)
+		if err != nil {
+			log.Fatalf("Error opening dead-letter file: %v\n", err)
+		}
+	}
+
+	if r.checkpointFile != "" {
+		r.openCheckpoint()
+	} else if r.resume {
+		log.Fatalf("invalid configuration: cannot resume without checkpoint file")
+	}
+
 	if r.file != "" {
 		if f, err := os.Open(r.file); err == nil {
 			r.sourceReader = f
@@ -164,9 +247,14 @@ func (r *LoadRunner) Validate() {
 		r.sourceReader = os.Stdin
 	}
 
-	if r.reportHost != "" {
-		fmt.Printf("results report destination: %v\n", r.reportHost)
-		fmt.Printf("results report database: %v\n", r.reportDatabase)
+	if r.reportHost != "" || r.reportSinks != "" || r.summaryFile != "" {
+		if r.reportHost != "" {
+			fmt.Printf("results report destination: %v\n", r.reportHost)
+			fmt.Printf("results report database: %v\n", r.reportDatabase)
+		}
+		if r.reportSinks != "" {
+			fmt.Printf("results report sinks: %v\n", r.reportSinks)
+		}
 
 		var err error
 		r.reportHostname, err = os.Hostname()
@@ -194,6 +282,18 @@ func (r *LoadRunner) Validate() {
 		r.reportDatabase = r.reportBucketId
 	}
 
+	if r.manifestFile != "" {
+		m, err := common.ReadManifest(r.manifestFile)
+		if err != nil {
+			log.Fatalf("Error reading manifest: %v\n", err)
+		}
+		r.manifest = m
+		r.reportTags = append(r.reportTags, m.ReportTags()...)
+		fmt.Printf("dataset manifest: use case %s, format %s, scale-var %d, %d points, %d values, %d series\n", m.UseCase, m.Format, m.ScaleVar, m.Points, m.Values, m.SeriesCount)
+		if r.ItemLimit >= 0 {
+			fmt.Println("warning: input is not verified against the dataset manifest, as -item-limit reads only a part of it")
+		}
+	}
 }
 
 func printInfo() {
@@ -220,7 +320,7 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 		}
 		defer pprof.StopCPUProfile()
 	}
-	if r.DoLoad && r.DoDBCreate {
+	if r.DoLoad && r.DoDBCreate && !r.resuming() {
 		load.CreateDb()
 	}
 
@@ -233,13 +333,20 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 
 	r.movingAverageStat = NewTimedStatGroup(r.movingAverageInterval, r.trendSamples)
 
-	if r.reportHost != "" && r.reportTelemetry {
-		telemetryCollector := report.NewCollector(r.reportHost, r.reportDatabase, r.reportUser, r.reportPassword)
-		err := telemetryCollector.CreateDatabase()
-		if err != nil {
-			log.Fatalf("Error creating telemetry db: %v\n", err)
+	r.openReportSink()
+	var telemetrySink chan *report.Point
+	if r.reportSink != nil && (r.reportTelemetry || r.reportResources) {
+		r.telemetryChanPoints, r.telemetryChanDone = report.TelemetryRunAsync(r.reportSink, r.telemetryBatchSize, r.telemetryStderr, 0, r.telemetryOptions)
+		if r.reportTelemetry {
+			telemetrySink = r.telemetryChanPoints
+		}
+		if r.reportResources {
+			tags := make([][2]string, 0, len(r.reportTags)+1)
+			tags = append(tags, r.reportTags...)
+			tags = append(tags, [2]string{"client_type", "load"})
+			r.resourceSampler = report.NewResourceSampler(r.resourcesPid, tags)
+			r.resourceSampler.RunAsync(r.telemetryChanPoints, time.Second)
 		}
-		r.telemetryChanPoints, r.telemetryChanDone = report.TelemetryRunAsync(telemetryCollector, r.telemetryBatchSize, r.telemetryStderr, 0)
 	}
 
 	if r.notificationListenPort > 0 {
@@ -257,7 +364,7 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 
 	r.StatChan = make(chan *Stat, r.Workers)
 	r.statGroup.Add(1)
-	go r.processStats(r.telemetryChanPoints)
+	go r.processStats(telemetrySink)
 
 	var once sync.Once
 	var workersGroup sync.WaitGroup
@@ -270,9 +377,10 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 		batchProcessor.PrepareProcess(i)
 		workersGroup.Add(1)
 		go func(w int) {
-			err := batchProcessor.RunProcess(w, &workersGroup, r.telemetryChanPoints, r.reportTags)
+			err := batchProcessor.RunProcess(w, &workersGroup, telemetrySink, r.reportTags)
 			if err != nil {
 				fmt.Println(err.Error())
+				r.addError(err.Error())
 				once.Do(func() {
 					r.endedPrematurely = true
 					r.prematureEndReason = "Worker error"
@@ -306,14 +414,48 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 		}()
 	}
 
+	var input io.Reader = r.sourceReader
+	if r.manifest != nil {
+		r.inputHash = sha256.New()
+		input = io.TeeReader(input, r.inputHash)
+	}
+
+	if r.agent != nil {
+		fmt.Println("waiting for the other agents...")
+		if err := r.agent.WaitStart(); err != nil {
+			log.Fatalf("Error waiting for coordinator: %v\n", err)
+		}
+	}
+
+	var checkpointStop chan struct{}
+	if r.checkpointer != nil {
+		checkpointStop = make(chan struct{})
+		go r.checkpointer.run(r.checkpointInterval, checkpointStop)
+	}
+
 	start := time.Now()
-	scanner.RunScanner(r.sourceReader, r.syncChanDone)
+	scanner.RunScanner(input, r.syncChanDone)
 
 	load.SyncEnd()
 	close(r.syncChanDone)
 
 	workersGroup.Wait()
 
+	if r.deadLetter != nil {
+		if err := r.deadLetter.close(); err != nil {
+			r.addError(fmt.Sprintf("closing dead-letter file: %v", err))
+			exitCode = 1
+		}
+	}
+
+	if r.checkpointer != nil {
+		close(checkpointStop)
+		complete := !r.endedPrematurely && exitCode == 0 && r.ItemLimit < 0
+		if err := r.checkpointer.write(complete); err != nil {
+			log.Printf("Error writing checkpoint: %v\n", err)
+		}
+	}
+
 	close(r.StatChan)
 	r.statGroup.Wait()
 
@@ -322,6 +464,17 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 	end := time.Now()
 	took := end.Sub(start)
 
+	if r.manifest != nil && r.ItemLimit < 0 {
+		if r.manifest.ContentHash == "" {
+			fmt.Println("warning: input is not verified against the dataset manifest, as it has no content hash")
+		} else if r.endedPrematurely {
+			fmt.Println("warning: input is not verified against the dataset manifest, as the load ended prematurely")
+		} else if !r.verifyManifestHash() {
+			r.addError("input does not match dataset manifest")
+			exitCode = 1
+		}
+	}
+
 	if r.file != "" {
 		r.sourceReader.Close()
 	}
@@ -331,17 +484,45 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 	bytesRate := float64(bytesRead) / float64(took.Seconds())
 	valuesRate := float64(valuesRead) / float64(took.Seconds())
 
-	if r.reportHost != "" && r.reportTelemetry {
+	if r.telemetryChanPoints != nil {
+		if r.resourceSampler != nil {
+			r.resourceSampler.Stop()
+		}
 		close(r.telemetryChanPoints)
-		<-r.telemetryChanDone
+		stats := <-r.telemetryChanDone
+		r.telemetryStats = &stats
+		stats.Print(r.telemetryOptions.SpillFile)
 	}
 	if r.endedPrematurely {
 		fmt.Printf("load finished prematurely: %s\n", r.prematureEndReason)
 	}
+	if r.retriedWrites > 0 || r.deadLetterBatches > 0 {
+		fmt.Printf("retried %d batch writes", r.retriedWrites)
+		if r.deadLetter != nil {
+			fmt.Printf(", %d batches written to %s", r.deadLetterBatches, r.deadLetterFile)
+		}
+		fmt.Println()
+	}
+	if r.agent != nil {
+		r.errorsMutex.Lock()
+		result := &coordinator.Result{
+			Workers:  r.Workers,
+			Duration: took,
+			Items:    itemsRead,
+			Values:   valuesRead,
+			Bytes:    bytesRead,
+			Errors:   r.errors,
+		}
+		r.errorsMutex.Unlock()
+		if err := r.agent.Finish(result); err != nil {
+			log.Printf("Error sending result to coordinator: %v\n", err)
+			exitCode = 1
+		}
+	}
 
 	fmt.Printf("loaded %d items in %fsec with %d workers (mean point rate %f/sec, mean value rate %f/s, %.2fMB/sec from stdin)\n", itemsRead, took.Seconds(), r.Workers, itemsRate, valuesRate, bytesRate/(1<<20))
 
-	if r.reportHost != "" {
+	if r.reportSink != nil || r.summaryFile != "" {
 		//append db specific tags to custom tags
 		if r.endedPrematurely {
 			r.reportTags = append(r.reportTags, [2]string{"premature_end_reason", report.Escape(r.prematureEndReason)})
@@ -358,6 +539,7 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 				Hostname:           r.reportHostname,
 				Workers:            r.Workers,
 				ItemLimit:          int(r.ItemLimit),
+				Sink:               r.reportSink,
 			},
 			IsGzip:    false,
 			BatchSize: r.BatchSize,
@@ -367,13 +549,49 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 			reportParams.ReportAuthToken = r.reportAuthToken
 		}
 		customTags, extraVals := load.UpdateReport(reportParams)
-		if customTags != nil {
-			reportParams.ReportTags = append(r.reportTags, customTags...)
+		if r.telemetryStats != nil {
+			extraVals = append(extraVals, report.ExtraVal{Name: "telemetry_spilled_points", Value: r.telemetryStats.Spilled})
+			extraVals = append(extraVals, report.ExtraVal{Name: "telemetry_dropped_points", Value: r.telemetryStats.Dropped})
+		}
+		if r.retryPolicy.MaxAttempts > 1 {
+			extraVals = append(extraVals, report.ExtraVal{Name: "retried_writes", Value: r.retriedWrites})
+		}
+		if r.deadLetter != nil {
+			extraVals = append(extraVals, report.ExtraVal{Name: "dead_letter_batches", Value: r.deadLetterBatches})
 		}
-		err := report.ReportLoadResult(reportParams, itemsRead, valuesRate, bytesRate, took, extraVals...)
+		reportParams.ReportTags = append(r.reportTags, customTags...)
+		if r.reportSink != nil {
+			err := report.ReportLoadResult(reportParams, itemsRead, valuesRate, bytesRate, took, extraVals...)
 
-		if err != nil {
-			log.Fatal(err)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := r.reportSink.Close(); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		if r.summaryFile != "" {
+			summary := report.NewSummary(report.SummaryLoad, start, end)
+			summary.SetParams(&reportParams.ReportParams)
+			summary.SetExtraVals(extraVals)
+			summary.Load = &report.LoadSummary{
+				BatchSize:  r.BatchSize,
+				Items:      itemsRead,
+				Values:     valuesRead,
+				Bytes:      bytesRead,
+				ItemsRate:  itemsRate,
+				ValuesRate: valuesRate,
+				BytesRate:  bytesRate,
+			}
+			summary.Errors = append(summary.Errors, r.errors...)
+			if r.endedPrematurely {
+				summary.PrematureEnd = r.prematureEndReason
+			}
+			summary.ExitCode = exitCode
+			if err := summary.WriteFile(r.summaryFile); err != nil {
+				log.Fatalf("Error writing summary: %v\n", err)
+			}
 		}
 	}
 	if exitCode != 0 {
@@ -383,6 +601,160 @@ func (r *LoadRunner) Run(load BulkLoad) int {
 	return exitCode
 }
 
+// addError records an error for the summary. It is safe for concurrent use.
+func (r *LoadRunner) addError(err string) {
+	r.errorsMutex.Lock()
+	r.errors = append(r.errors, err)
+	r.errorsMutex.Unlock()
+}
+
+// openCheckpoint reads the checkpoint file to resume from, if any.
+func (r *LoadRunner) openCheckpoint() {
+	var skip int64
+	if r.resume {
+		c, err := readCheckpoint(r.checkpointFile)
+		if err != nil {
+			log.Fatalf("Error reading checkpoint: %v\n", err)
+		}
+		if c == nil {
+			fmt.Printf("no checkpoint in %s, loading from the beginning\n", r.checkpointFile)
+		} else {
+			if c.Input != r.file {
+				log.Fatalf("checkpoint of input %q cannot resume loading %q\n", c.Input, r.file)
+			}
+			if c.Complete {
+				fmt.Printf("load already complete according to %s\n", r.checkpointFile)
+				os.Exit(0)
+			}
+			skip = c.Items
+			fmt.Printf("resuming the load after %d items, checkpointed at %s\n", skip, c.Time)
+		}
+	}
+	r.checkpointer = newCheckpointer(r.checkpointFile, r.file, skip)
+}
+
+// resuming tells whether the load resumes from a checkpoint.
+func (r *LoadRunner) resuming() bool {
+	return r.checkpointer != nil && r.checkpointer.skip > 0
+}
+
+// SkipItem tells whether the next input item was loaded before the resumed
+// checkpoint. Scanners call it for every item read, and skip the item if so.
+func (r *LoadRunner) SkipItem() bool {
+	if r.checkpointer == nil {
+		return false
+	}
+	return r.checkpointer.skipItem()
+}
+
+// BatchSent records a batch of the items read since the previous one as
+// handed to the workers. The key identifies the batch until written, e.g.
+// the pointer to its buffer.
+func (r *LoadRunner) BatchSent(key interface{}) {
+	if r.checkpointer != nil {
+		r.checkpointer.sent(key)
+	}
+}
+
+// BatchWritten records a batch as acknowledged by the database.
+func (r *LoadRunner) BatchWritten(key interface{}) {
+	if r.checkpointer != nil {
+		r.checkpointer.acked(key)
+	}
+}
+
+// registerAgent registers with the coordinator, applying the assigned shard.
+func (r *LoadRunner) registerAgent() {
+	agent, err := coordinator.Register(r.coordinatorAddr, coordinator.KindLoad)
+	if err != nil {
+		log.Fatalf("Error registering with coordinator %s: %v\n", r.coordinatorAddr, err)
+	}
+	r.agent = agent
+	if agent.Shard.Workers > 0 {
+		r.Workers = agent.Shard.Workers
+	}
+	r.file = strings.Replace(r.file, "{group}", strconv.Itoa(agent.Shard.ClientIndex), -1)
+	r.reportTags = append(r.reportTags, [2]string{"client_index", strconv.Itoa(agent.Shard.ClientIndex)})
+	fmt.Printf("agent %d of %d of coordinator %s\n", agent.Shard.ClientIndex, agent.Shard.Clients, r.coordinatorAddr)
+}
+
+// Shard returns the shard assigned by the coordinator, nil if not an agent.
+func (r *LoadRunner) Shard() *coordinator.Shard {
+	if r.agent == nil {
+		return nil
+	}
+	return &r.agent.Shard
+}
+
+// openReportSink creates the destinations of result metrics and telemetry
+// selected by the report flags, if any.
+func (r *LoadRunner) openReportSink() {
+	sinks, err := report.NewSinks(r.reportSinks, r.reportPrometheusLinger)
+	if err != nil {
+		log.Fatalf("Error creating report sinks: %v\n", err)
+	}
+	if r.reportHost != "" {
+		if r.reportOrgId == "" {
+			s, err := report.NewInfluxSink(r.reportHost, r.reportDatabase, r.reportUser, r.reportPassword)
+			if err != nil {
+				log.Fatalf("Error creating report db: %v\n", err)
+			}
+			sinks = append(sinks, s)
+		} else {
+			sinks = append(sinks, report.NewInfluxSinkV2(r.reportHost, r.reportOrgId, r.reportDatabase, r.reportAuthToken))
+		}
+	}
+	if len(sinks) > 0 {
+		r.reportSink = report.MultiSink(sinks)
+	}
+}
+
+// LoadedValues returns the share of the values read which belongs to the
+// loaded items, for scanners which know only the values of all the items
+// read, including the ones skipped on resume.
+func LoadedValues(values, loaded, skipped int64) int64 {
+	if skipped == 0 || loaded+skipped == 0 {
+		return values
+	}
+	return int64(float64(values) * float64(loaded) / float64(loaded+skipped))
+}
+
+// HasManifest reports whether the input is verified against a dataset
+// manifest, loaders of formats without the dataset size marker reject it.
+func (r *LoadRunner) HasManifest() bool {
+	return r.manifestFile != ""
+}
+
+// CheckDatasetSize compares the dataset size marker read from the input with
+// the counts of the manifest, if any, and aborts the load on mismatch without
+// waiting for the content hash verification.
+func (r *LoadRunner) CheckDatasetSize(points, values int64) {
+	if r.manifest == nil {
+		return
+	}
+	if points != r.manifest.Points || values != r.manifest.Values {
+		log.Fatalf("input does NOT match dataset manifest: dataset size %d points, %d values, expected %d points, %d values\n", points, values, r.manifest.Points, r.manifest.Values)
+	}
+}
+
+// verifyManifestHash compares hash of the whole input with the content hash
+// from the manifest. It is meaningful only if the input was read completely.
+func (r *LoadRunner) verifyManifestHash() bool {
+	// drain what the scanner has not read, e.g. after the dataset size marker
+	if _, err := io.Copy(r.inputHash, r.sourceReader); err != nil {
+		log.Printf("Error reading rest of input: %v\n", err)
+	}
+	inputHash := common.ManifestHashPrefix + hex.EncodeToString(r.inputHash.Sum(nil))
+	match := inputHash == r.manifest.ContentHash
+	if match {
+		fmt.Printf("input matches dataset manifest (%s)\n", inputHash)
+	} else {
+		fmt.Printf("input does NOT match dataset manifest: expected %s, got %s\n", r.manifest.ContentHash, inputHash)
+	}
+	r.reportTags = append(r.reportTags, [2]string{"dataset_verified", fmt.Sprintf("%t", match)})
+	return match
+}
+
 var firstStat time.Time
 
 func (r *LoadRunner) processStats(telemetrySink chan *report.Point) {
@@ -424,6 +796,12 @@ func (r *LoadRunner) processStats(telemetrySink chan *report.Point) {
 				p.AddIntField("load_workers", r.Workers)
 				telemetrySink <- p
 			}
+			if r.agent != nil {
+				r.agent.SendProgress(coordinator.Progress{
+					Values:     r.statMapping["*"].Sum,
+					IngestRate: r.movingAverageStat.Rate(),
+				})
+			}
 		}
 
 		// print stats to stderr (if printInterval is greater than zero):
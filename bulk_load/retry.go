@@ -0,0 +1,142 @@
+package bulk_load
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryPolicy is the policy of retrying the failed writes of batches.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a write, 1 to never
+	// retry.
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled before every
+	// following one up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Delay returns the delay before retrying a write which failed attempt times:
+// the exponential backoff, of which a random half is cut off to spread the
+// retries of the workers.
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// StatusError is an error response of a database to a write.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// RetryableStatus tells whether a write answered with an HTTP status code
+// may succeed when retried: on server errors, timeouts and throttling.
+func RetryableStatus(code int) bool {
+	return code >= 500 || code == 408 || code == 429
+}
+
+// IsRetryable is the classification of the errors of the loaders writing
+// over HTTP: a StatusError is retryable according to its status, while any
+// other error, raised by the transport, is.
+func IsRetryable(err error) bool {
+	if se, ok := err.(*StatusError); ok {
+		return RetryableStatus(se.StatusCode)
+	}
+	return true
+}
+
+// IsNetError tells whether an error is a network error or a connection
+// closed by the server.
+func IsNetError(err error) bool {
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// deadLetterFile is where the batches which permanently failed are appended
+// verbatim, to be replayed later.
+type deadLetterFile struct {
+	mutex sync.Mutex
+	file  *os.File
+	w     *bufio.Writer
+}
+
+func openDeadLetterFile(path string) (*deadLetterFile, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &deadLetterFile{file: f, w: bufio.NewWriter(f)}, nil
+}
+
+// write appends a batch written by write.
+func (d *deadLetterFile) write(write func(w io.Writer) error) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := write(d.w); err != nil {
+		return err
+	}
+	return d.w.Flush()
+}
+
+func (d *deadLetterFile) close() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if err := d.w.Flush(); err != nil {
+		d.file.Close()
+		return err
+	}
+	return d.file.Close()
+}
+
+// WriteBatch writes a batch by calling write, retrying the errors for which
+// retryable is true according to the retry policy. If the batch permanently
+// failed and a dead-letter file is set, deadLetter appends to it the items of
+// the batch that were not written, given the error of the last attempt, and
+// nil is returned. Otherwise the error of the last attempt is.
+func (r *LoadRunner) WriteBatch(write func() error, retryable func(error) bool, deadLetter func(w io.Writer, err error) error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = write(); err == nil {
+			return nil
+		}
+		if attempt >= r.retryPolicy.MaxAttempts || !retryable(err) {
+			break
+		}
+		delay := r.retryPolicy.Delay(attempt)
+		fmt.Printf("write attempt %d failed, retrying in %v: %v\n", attempt, delay, err)
+		atomic.AddInt64(&r.retriedWrites, 1)
+		time.Sleep(delay)
+	}
+	if r.deadLetter == nil {
+		return err
+	}
+	if dlErr := r.deadLetter.write(func(w io.Writer) error { return deadLetter(w, err) }); dlErr != nil {
+		return fmt.Errorf("%v (writing to dead-letter file: %v)", err, dlErr)
+	}
+	fmt.Printf("batch written to dead-letter file: %v\n", err)
+	atomic.AddInt64(&r.deadLetterBatches, 1)
+	return nil
+}
@@ -0,0 +1,151 @@
+package bulk_load
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	cases := []struct {
+		attempt int
+		backoff time.Duration // before jitter
+	}{
+		{attempt: 1, backoff: 100 * time.Millisecond},
+		{attempt: 2, backoff: 200 * time.Millisecond},
+		{attempt: 3, backoff: 400 * time.Millisecond},
+		{attempt: 4, backoff: 800 * time.Millisecond},
+		{attempt: 5, backoff: time.Second},
+		{attempt: 50, backoff: time.Second},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("attempt %d", c.attempt), func(t *testing.T) {
+			seen := make(map[time.Duration]bool)
+			for i := 0; i < 100; i++ {
+				d := policy.Delay(c.attempt)
+				require.True(t, d >= c.backoff/2 && d <= c.backoff, "delay %v out of [%v, %v]", d, c.backoff/2, c.backoff)
+				seen[d] = true
+			}
+			require.True(t, len(seen) > 1, "delays are jittered")
+		})
+	}
+
+	require.Equal(t, time.Duration(0), (&RetryPolicy{MaxAttempts: 3}).Delay(2))
+}
+
+func TestRetryableStatus(t *testing.T) {
+	cases := []struct {
+		code      int
+		retryable bool
+	}{
+		{200, false},
+		{204, false},
+		{400, false},
+		{401, false},
+		{404, false},
+		{408, true},
+		{413, false},
+		{429, true},
+		{500, true},
+		{503, true},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("status %d", c.code), func(t *testing.T) {
+			require.Equal(t, c.retryable, RetryableStatus(c.code))
+		})
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	cases := []struct {
+		name      string
+		err       error
+		retryable bool
+		netError  bool
+	}{
+		{name: "server error", err: &StatusError{StatusCode: 503}, retryable: true},
+		{name: "throttled", err: &StatusError{StatusCode: 429}, retryable: true},
+		{name: "bad request", err: &StatusError{StatusCode: 400}, retryable: false},
+		{name: "network error", err: netErr, retryable: true, netError: true},
+		{name: "connection closed", err: io.EOF, retryable: true, netError: true},
+		{name: "connection closed early", err: io.ErrUnexpectedEOF, retryable: true, netError: true},
+		{name: "other error", err: errors.New("invalid document"), retryable: true, netError: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.retryable, IsRetryable(c.err))
+			require.Equal(t, c.netError, IsNetError(c.err))
+		})
+	}
+}
+
+func TestWriteBatch(t *testing.T) {
+	errRetryable := &StatusError{StatusCode: 503, Message: "unavailable"}
+	errPermanent := &StatusError{StatusCode: 400, Message: "bad request"}
+	cases := []struct {
+		name        string
+		errs        []error // of the successive attempts, nil after them
+		deadLetter  bool
+		attempts    int
+		retried     int64
+		err         error
+		deadLetters string
+	}{
+		{name: "written", attempts: 1},
+		{name: "written after retries", errs: []error{errRetryable, errRetryable}, attempts: 3, retried: 2},
+		{name: "retries exhausted", errs: []error{errRetryable, errRetryable, errRetryable}, attempts: 3, retried: 2, err: errRetryable},
+		{name: "permanent error", errs: []error{errPermanent}, attempts: 1, err: errPermanent},
+		{name: "permanent error to dead-letter file", errs: []error{errPermanent}, deadLetter: true, attempts: 1, deadLetters: "batch failed with bad request\n"},
+		{name: "retries exhausted to dead-letter file", errs: []error{errRetryable, errRetryable, errRetryable}, deadLetter: true, attempts: 3, retried: 2, deadLetters: "batch failed with unavailable\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "dead-letter")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "dead-letter.txt")
+
+			r := &LoadRunner{retryPolicy: RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}}
+			if c.deadLetter {
+				r.deadLetter, err = openDeadLetterFile(path)
+				require.NoError(t, err)
+			}
+
+			attempts := 0
+			err = r.WriteBatch(func() error {
+				attempts++
+				if attempts <= len(c.errs) {
+					return c.errs[attempts-1]
+				}
+				return nil
+			}, IsRetryable, func(w io.Writer, err error) error {
+				_, werr := fmt.Fprintf(w, "batch failed with %v\n", err)
+				return werr
+			})
+			require.Equal(t, c.err, err)
+			require.Equal(t, c.attempts, attempts)
+			require.Equal(t, c.retried, r.retriedWrites)
+
+			if c.deadLetter {
+				require.NoError(t, r.deadLetter.close())
+				data, err := ioutil.ReadFile(path)
+				require.NoError(t, err)
+				require.Equal(t, c.deadLetters, string(data))
+				expected := int64(0)
+				if c.deadLetters != "" {
+					expected = 1
+				}
+				require.Equal(t, expected, r.deadLetterBatches)
+			}
+		})
+	}
+}
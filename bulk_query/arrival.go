@@ -0,0 +1,112 @@
+package bulk_query
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+)
+
+// Arrival distributions of the open-loop mode:
+const (
+	ArrivalConstant = "constant"
+	ArrivalPoisson  = "poisson"
+)
+
+// arrivalTicketsBuffer bounds the number of overdue queries the scheduler
+// keeps track of while all the workers are busy.
+const arrivalTicketsBuffer = 1 << 16
+
+// arrivalScheduler issues the intended start times of the queries at a
+// target rate, regardless of how fast the queries complete. A query taken
+// by a worker after its intended start time was queued.
+type arrivalScheduler struct {
+	rate         float64 // queries per second
+	distribution string
+	tickets      chan time.Time
+	done         chan struct{}
+	rand         *rand.Rand
+}
+
+func newArrivalScheduler(rate float64, distribution string) *arrivalScheduler {
+	return &arrivalScheduler{
+		rate:         rate,
+		distribution: distribution,
+		tickets:      make(chan time.Time, arrivalTicketsBuffer),
+		done:         make(chan struct{}),
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// interval returns the time to the next arrival.
+func (s *arrivalScheduler) interval() time.Duration {
+	mean := float64(time.Second) / s.rate
+	if s.distribution == ArrivalPoisson {
+		return time.Duration(s.rand.ExpFloat64() * mean)
+	}
+	return time.Duration(mean)
+}
+
+// run issues the tickets from start until stopped. The intended start times
+// follow the schedule even when the tickets cannot be issued on time, so
+// that the delays are accounted for.
+func (s *arrivalScheduler) run(start time.Time) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	next := start
+	for {
+		if d := time.Until(next); d > 0 {
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(d)
+			select {
+			case <-timer.C:
+			case <-s.done:
+				return
+			}
+		}
+		select {
+		case s.tickets <- next:
+		case <-s.done:
+			return
+		}
+		next = next.Add(s.interval())
+	}
+}
+
+func (s *arrivalScheduler) stop() {
+	close(s.done)
+}
+
+func (q *QueryBenchmarker) ArrivalRate() float64 {
+	return q.arrivalRate
+}
+
+// WaitArrival blocks until the intended start time of the next query in the
+// open-loop mode, and returns how long the query was queued past it, in
+// milliseconds, to be recorded with Stat.SetQueueDelay. In the default
+// closed-loop mode it returns -1 at once.
+func (q *QueryBenchmarker) WaitArrival() float64 {
+	if q.arrivals == nil {
+		return -1
+	}
+	intended := <-q.arrivals.tickets
+	return float64(time.Since(intended).Nanoseconds()) / 1e6
+}
+
+func (q *QueryBenchmarker) validateArrivals() {
+	if q.arrivalRate < 0 {
+		log.Fatalf("invalid arrival rate: %f", q.arrivalRate)
+	}
+	if q.arrivalRate == 0 {
+		return
+	}
+	if q.arrivalDistribution != ArrivalConstant && q.arrivalDistribution != ArrivalPoisson {
+		log.Fatalf("invalid arrival distribution: %s (choices: %s, %s)", q.arrivalDistribution, ArrivalConstant, ArrivalPoisson)
+	}
+	fmt.Printf("Open-loop mode: %.2f queries/sec, %s arrivals\n", q.arrivalRate, q.arrivalDistribution)
+}
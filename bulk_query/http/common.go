@@ -20,6 +20,12 @@ type HTTPClientDoOptions struct {
 	Authorization        string
 	Debug                int
 	PrettyPrintResponses bool
+	// Verify, when set, is called with the response body of the queries
+	// having expected results.
+	Verify func(q *Query, body []byte)
+	// CountRows, when set, returns the number of result rows of a response
+	// body.
+	CountRows func(body []byte) int64
 }
 
 // HTTPClient interface.
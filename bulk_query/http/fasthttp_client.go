@@ -83,7 +83,19 @@ func (w *FastHTTPClient) Do(q *Query, opts *HTTPClientDoOptions) (lag float64, e
 		}
 	}
 
+	q.ResponseBytes, q.ResponseRows = -1, -1
+	if err == nil {
+		q.ResponseBytes = int64(len(resp.Body()))
+		if opts != nil && opts.CountRows != nil {
+			q.ResponseRows = opts.CountRows(resp.Body())
+		}
+	}
+
 	if opts != nil {
+		if err == nil && opts.Verify != nil && q.Expected != nil {
+			opts.Verify(q, resp.Body())
+		}
+
 		// Print debug messages, if applicable:
 		switch opts.Debug {
 		case 1:
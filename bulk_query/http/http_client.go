@@ -81,7 +81,19 @@ func (w *DefaultHTTPClient) Do(q *Query, opts *HTTPClientDoOptions) (lag float64
 		}
 	}
 
+	q.ResponseBytes, q.ResponseRows = -1, -1
+	if err == nil {
+		q.ResponseBytes = int64(len(respBody))
+		if opts != nil && opts.CountRows != nil {
+			q.ResponseRows = opts.CountRows(respBody)
+		}
+	}
+
 	if opts != nil {
+		if err == nil && opts.Verify != nil && q.Expected != nil {
+			opts.Verify(q, respBody)
+		}
+
 		// Print debug messages, if applicable:
 		switch opts.Debug {
 		case 1:
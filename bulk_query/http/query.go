@@ -10,6 +10,11 @@ type Query struct {
 	Path             []byte
 	Body             []byte
 	ID               int64
+	Expected         *ExpectedResult // set when the generator embedded the expected results
+
+	// set by HTTPClient.Do, -1 when unknown
+	ResponseBytes int64
+	ResponseRows  int64
 }
 
 // String produces a debug-ready description of a Query.
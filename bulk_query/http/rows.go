@@ -0,0 +1,137 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// CountInfluxRows counts the result rows of an InfluxDB response: the values
+// of all the series of an InfluxQL JSON response, or the records of a Flux
+// annotated CSV response. It returns -1 for an unparsable response.
+func CountInfluxRows(body []byte) int64 {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return 0
+	}
+	if trimmed[0] == '{' {
+		var resp struct {
+			Results []struct {
+				Series []struct {
+					Values []json.RawMessage `json:"values"`
+				} `json:"series"`
+			} `json:"results"`
+		}
+		if err := json.Unmarshal(trimmed, &resp); err != nil {
+			return -1
+		}
+		var rows int64
+		for _, result := range resp.Results {
+			for _, series := range result.Series {
+				rows += int64(len(series.Values))
+			}
+		}
+		return rows
+	}
+
+	// Flux CSV: tables are separated by empty lines, each one starting with
+	// optional #annotations and a header line
+	var rows int64
+	header := true
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		switch {
+		case len(line) == 0:
+			header = true
+		case line[0] == '#':
+		case header:
+			header = false
+		default:
+			rows++
+		}
+	}
+	return rows
+}
+
+// CountElasticsearchRows counts the result rows of an Elasticsearch search
+// response: the leaf buckets of its aggregations, or its hits when it has
+// no aggregations. It returns -1 for an unparsable response.
+func CountElasticsearchRows(body []byte) int64 {
+	var resp struct {
+		Hits struct {
+			Hits []json.RawMessage `json:"hits"`
+		} `json:"hits"`
+		Aggregations map[string]interface{} `json:"aggregations"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return -1
+	}
+	if len(resp.Aggregations) == 0 {
+		return int64(len(resp.Hits.Hits))
+	}
+	return countLeafBuckets(resp.Aggregations)
+}
+
+// countLeafBuckets counts the buckets of the aggregations of agg which have
+// no sub-aggregation buckets themselves. A metric aggregation counts as one.
+func countLeafBuckets(agg map[string]interface{}) int64 {
+	var rows int64
+	for _, v := range agg {
+		sub, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		buckets, ok := sub["buckets"].([]interface{})
+		if !ok {
+			if _, ok := sub["value"]; ok {
+				rows++
+			} else if _, ok := sub["values"]; ok {
+				rows++
+			}
+			continue
+		}
+		for _, b := range buckets {
+			bucket, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if n := countSubBuckets(bucket); n > 0 {
+				rows += n
+			} else {
+				rows++
+			}
+		}
+	}
+	return rows
+}
+
+// countSubBuckets counts the leaf buckets of the bucket aggregations nested
+// in bucket.
+func countSubBuckets(bucket map[string]interface{}) int64 {
+	var rows int64
+	for _, v := range bucket {
+		sub, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok := sub["buckets"].([]interface{}); ok {
+			rows += countLeafBuckets(map[string]interface{}{"": sub})
+		}
+	}
+	return rows
+}
+
+// CountGraphiteRows counts the datapoints of all the series of a Graphite
+// render JSON response. It returns -1 for an unparsable response.
+func CountGraphiteRows(body []byte) int64 {
+	var series []struct {
+		Datapoints []json.RawMessage `json:"datapoints"`
+	}
+	if err := json.Unmarshal(body, &series); err != nil {
+		return -1
+	}
+	var rows int64
+	for _, s := range series {
+		rows += int64(len(s.Datapoints))
+	}
+	return rows
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCountInfluxRows(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		rows int64
+	}{
+		{name: "empty", body: "", rows: 0},
+		{name: "no series", body: `{"results":[{"statement_id":0}]}`, rows: 0},
+		{
+			name: "influxql",
+			body: `{"results":[{"statement_id":0,"series":[{"name":"cpu","tags":{"hostname":"host_0"},"columns":["time","max"],"values":[["2018-01-01T00:00:00Z",90.5],["2018-01-01T00:01:00Z",12]]},{"name":"cpu","tags":{"hostname":"host_1"},"columns":["time","max"],"values":[["2018-01-01T00:00:00Z",3]]}]},{"statement_id":1,"series":[{"name":"mem","columns":["time","used"],"values":[["2018-01-01T00:00:00Z",1]]}]}]}`,
+			rows: 4,
+		},
+		{name: "invalid influxql", body: `{"results":[`, rows: -1},
+		{
+			name: "flux",
+			body: "#datatype,string,long,dateTime:RFC3339,double\r\n" +
+				"#group,false,false,false,false\r\n" +
+				"#default,_result,,,\r\n" +
+				",result,table,_time,_value\r\n" +
+				",,0,2018-01-01T00:00:00Z,90.5\r\n" +
+				",,0,2018-01-01T00:01:00Z,12\r\n" +
+				"\r\n" +
+				"#datatype,string,long,dateTime:RFC3339,double\r\n" +
+				",result,table,_time,_value\r\n" +
+				",,1,2018-01-01T00:00:00Z,3\r\n" +
+				"\r\n",
+			rows: 3,
+		},
+		{name: "flux without annotations", body: ",result,table,_value\n,,0,1\n,,0,2\n", rows: 2},
+		{name: "flux header only", body: ",result,table,_value\n", rows: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.rows, CountInfluxRows([]byte(c.body)))
+		})
+	}
+}
+
+func TestCountElasticsearchRows(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		rows int64
+	}{
+		{name: "hits", body: `{"hits":{"total":3,"hits":[{"_id":"1"},{"_id":"2"},{"_id":"3"}]}}`, rows: 3},
+		{name: "no hits", body: `{"hits":{"total":0,"hits":[]}}`, rows: 0},
+		{
+			name: "buckets",
+			body: `{"hits":{"hits":[]},"aggregations":{"result":{"buckets":[{"key":1,"doc_count":5,"max_usage_user":{"value":90}},{"key":2,"doc_count":5,"max_usage_user":{"value":12}}]}}}`,
+			rows: 2,
+		},
+		{
+			name: "nested buckets",
+			body: `{"hits":{"hits":[]},"aggregations":{"result":{"buckets":[{"key":"host_0","by_minute":{"buckets":[{"key":1},{"key":2},{"key":3}]}},{"key":"host_1","by_minute":{"buckets":[{"key":1},{"key":2}]}},{"key":"host_2","by_minute":{"buckets":[]}}]}}}`,
+			rows: 6,
+		},
+		{name: "metric aggregation", body: `{"hits":{"hits":[{"_id":"1"}]},"aggregations":{"max_usage":{"value":90},"percentiles":{"values":{"50.0":3}}}}`, rows: 2},
+		{name: "invalid", body: `<html>`, rows: -1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.rows, CountElasticsearchRows([]byte(c.body)))
+		})
+	}
+}
+
+func TestCountGraphiteRows(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+		rows int64
+	}{
+		{name: "no series", body: `[]`, rows: 0},
+		{
+			name: "series",
+			body: `[{"target":"host_0.cpu.usage_user","datapoints":[[90.5,1514764800],[null,1514764860]]},{"target":"host_1.cpu.usage_user","datapoints":[[3,1514764800]]}]`,
+			rows: 3,
+		},
+		{name: "invalid", body: `{"error":"bad target"}`, rows: -1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.rows, CountGraphiteRows([]byte(c.body)))
+		})
+	}
+}
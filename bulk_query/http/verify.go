@@ -0,0 +1,79 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ExpectedResult holds the expected answer of a query returning a single
+// series of time buckets, as embedded by bulk_query_gen: the start of every
+// non-empty bucket, in ascending order, and its aggregated value.
+type ExpectedResult struct {
+	Times  []int64
+	Values []float64
+}
+
+type influxQLResponse struct {
+	Results []struct {
+		Series []struct {
+			Values [][]interface{} `json:"values"`
+		} `json:"series"`
+		Error string `json:"error"`
+	} `json:"results"`
+}
+
+// VerifyInfluxQL compares an InfluxQL JSON response to the expected result.
+// Buckets with a null value are empty ones. Values match when they differ
+// by at most tolerance, relative to the larger of them (or absolute below 1).
+func (e *ExpectedResult) VerifyInfluxQL(body []byte, tolerance float64) error {
+	var resp influxQLResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("invalid response: %v", err)
+	}
+
+	var times []int64
+	var values []float64
+	for _, result := range resp.Results {
+		if result.Error != "" {
+			return fmt.Errorf("query error: %s", result.Error)
+		}
+		for _, series := range result.Series {
+			for _, row := range series.Values {
+				if len(row) < 2 || row[1] == nil {
+					continue
+				}
+				ts, ok := row[0].(string)
+				if !ok {
+					return fmt.Errorf("unexpected time %v", row[0])
+				}
+				t, err := time.Parse(time.RFC3339Nano, ts)
+				if err != nil {
+					return err
+				}
+				v, ok := row[1].(float64)
+				if !ok {
+					return fmt.Errorf("unexpected value %v at %s", row[1], ts)
+				}
+				times = append(times, t.UnixNano())
+				values = append(values, v)
+			}
+		}
+	}
+
+	if len(times) != len(e.Times) {
+		return fmt.Errorf("expected %d non-empty buckets, got %d", len(e.Times), len(times))
+	}
+	for i := range times {
+		if times[i] != e.Times[i] {
+			return fmt.Errorf("bucket %d: expected time %s, got %s", i, time.Unix(0, e.Times[i]).UTC().Format(time.RFC3339), time.Unix(0, times[i]).UTC().Format(time.RFC3339))
+		}
+		diff := math.Abs(values[i] - e.Values[i])
+		scale := math.Max(1, math.Max(math.Abs(values[i]), math.Abs(e.Values[i])))
+		if diff > tolerance*scale {
+			return fmt.Errorf("bucket %s: expected %v, got %v", time.Unix(0, times[i]).UTC().Format(time.RFC3339), e.Values[i], values[i])
+		}
+	}
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 	"flag"
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_load"
+	bulkQueryGen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
+	"github.com/influxdata/influxdb-comparisons/util/coordinator"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"io"
 	"io/ioutil"
@@ -17,6 +19,7 @@ import (
 	"os"
 	"runtime/pprof"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -43,12 +46,20 @@ type QueryBenchmarker struct {
 	memProfile             string
 	telemetryStderr        bool
 	telemetryBatchSize     uint64
+	telemetryOptions       report.TelemetryOptions
 	reportDatabase         string
 	reportHost             string
 	reportUser             string
 	reportPassword         string
 	reportTagsCSV          string
+	reportOrgId            string
+	reportAuthToken        string
+	reportBucketId         string
+	reportSinks            string
+	reportPrometheusLinger time.Duration
+	summaryFile            string
 	useCase                string
+	queryManifestFile      string
 	queriesBatch           int
 	waitInterval           time.Duration
 	responseTimeLimit      time.Duration
@@ -62,8 +73,16 @@ type QueryBenchmarker struct {
 	trendSamples           int
 	movingAverageInterval  time.Duration
 	reportTelemetry        bool
+	reportResources        bool
+	resourcesPid           int
 	file                   string
+	verifyResults          bool
+	verifyTolerance        float64
+	arrivalRate            float64
+	arrivalDistribution    string
+	coordinatorAddr        string
 	//runtime vars
+	agent             *coordinator.Agent
 	statMapping       StatsMap
 	statChan          chan *Stat
 	statPool          sync.Pool
@@ -72,6 +91,7 @@ type QueryBenchmarker struct {
 	telemetryTags     [][2]string
 	reportTags        [][2]string
 	reportHostname    string
+	reportSink        report.Sink
 	batchSize         int
 	movingAverageStat *TimedStatGroup
 	isBurnIn          bool
@@ -80,6 +100,8 @@ type QueryBenchmarker struct {
 	scanCloseMutex    *sync.Mutex
 	notificationServer *http.Server
 	sigtermReceived   bool
+	verification      *verification
+	arrivals          *arrivalScheduler
 }
 
 const (
@@ -128,12 +150,24 @@ func (q *QueryBenchmarker) Init() {
 	flag.StringVar(&q.memProfile, "memprofile", "", "Write a memory profile to this file.")
 	flag.BoolVar(&q.telemetryStderr, "telemetry-stderr", false, "Whether to write telemetry also to stderr.")
 	flag.Uint64Var(&q.telemetryBatchSize, "telemetry-batch-size", 1, "Telemetry batch size (lines).")
+	q.telemetryOptions = report.DefaultTelemetryOptions
+	flag.IntVar(&q.telemetryOptions.BufferSize, "telemetry-buffer", q.telemetryOptions.BufferSize, "Maximum number of telemetry points buffered in memory while the report destination is failing.")
+	flag.IntVar(&q.telemetryOptions.MaxRetries, "telemetry-retries", q.telemetryOptions.MaxRetries, "Number of retries, with exponential backoff, of a telemetry batch before spilling it.")
+	flag.StringVar(&q.telemetryOptions.SpillFile, "telemetry-spill-file", "", "File appended with the telemetry points which could not be delivered, in line protocol (empty to drop them).")
 	flag.BoolVar(&q.reportTelemetry, "report-telemetry", false, "Whether to report also progress info about mean, moving mean and #workers.")
+	flag.BoolVar(&q.reportResources, "report-resources", false, "Whether to report every second the client CPU, memory, network and GC usage as telemetry (Linux only).")
+	flag.IntVar(&q.resourcesPid, "report-resources-pid", 0, "PID of a local target database process whose CPU and memory usage to report along with -report-resources.")
 	flag.StringVar(&q.reportDatabase, "report-database", "database_benchmarks", "Database name where to store result metrics.")
 	flag.StringVar(&q.reportHost, "report-host", "", "Host to send result metrics.")
 	flag.StringVar(&q.reportUser, "report-user", "", "User for Host to send result metrics.")
 	flag.StringVar(&q.reportPassword, "report-password", "", "User password for Host to send result metrics.")
 	flag.StringVar(&q.reportTagsCSV, "report-tags", "", "Comma separated k:v tags to send  alongside result metrics.")
+	flag.StringVar(&q.reportOrgId, "report-org-id", "", "Organization Id of the bucket where to store result metrics (InfluxDb 2).")
+	flag.StringVar(&q.reportAuthToken, "report-auth-token", "", "Authentication token for InfluxDb 2 where to store metrics.")
+	flag.StringVar(&q.reportBucketId, "report-bucket-id", "", "BucketId where to store result metrics (InfluxDb 2). Bucket must exist!")
+	flag.StringVar(&q.reportSinks, "report-sink", "", "Comma separated additional destinations of result metrics and telemetry: json=<file>, csv=<file>, prometheus=<listen address>, stdout. InfluxDB is used when report-host is set.")
+	flag.DurationVar(&q.reportPrometheusLinger, "report-prometheus-linger", 0, "How long the prometheus report sink keeps serving the final results before exiting.")
+	flag.StringVar(&q.queryManifestFile, "query-manifest", "", "Query manifest written by bulk_query_gen (-manifest-file). The generation parameters, like the host distribution, are added to report tags.")
 	flag.IntVar(&q.queriesBatch, "batch-size", 18, "Number of queries in batch per worker for Dashboard use-case")
 	flag.DurationVar(&q.waitInterval, "wait-interval", time.Second*0, "Delay between sending batches of queries in the dashboard use-case")
 	flag.BoolVar(&q.gradualWorkersIncrease, "grad-workers-inc", false, "Whether to gradually increase number of workers. The 'workers' params defines initial number of workers in this case.")
@@ -147,18 +181,31 @@ func (q *QueryBenchmarker) Init() {
 	flag.IntVar(&q.trendSamples, "rt-trend-samples", -1, "Number of avg response time samples used for linear regression (-1: number of samples equals increase-interval in seconds)")
 	flag.DurationVar(&q.movingAverageInterval, "moving-average-interval", time.Second*30, "Interval of measuring mean response time on which moving average  is calculated.")
 	flag.StringVar(&q.file, "file", "", "Input file")
+	flag.StringVar(&q.summaryFile, "summary-file", "", "Write a JSON summary of the run (parameters, tags, per-label latency stats, errors, system info) to this file on completion.")
+	q.verification = &verification{labels: make(map[string]*verificationStats)}
+	flag.Float64Var(&q.arrivalRate, "arrival-rate", 0, "Issue queries at this target rate (queries/sec) regardless of their response times, measuring latency from their intended start time (0 to send them as fast as the workers complete them).")
+	flag.StringVar(&q.coordinatorAddr, "coordinator", "", "host:port of a benchmark_coordinator to run as one of its agents: it assigns the client index and workers, and starts the queries together with the other agents. A {group} placeholder in -file is replaced with the client index.")
+	flag.StringVar(&q.arrivalDistribution, "arrival-distribution", ArrivalConstant, "Distribution of query arrivals at the target rate: "+ArrivalConstant+" or "+ArrivalPoisson+".")
 }
 
 func (q *QueryBenchmarker) Validate() {
+	if q.coordinatorAddr != "" {
+		q.registerAgent()
+	}
 	if q.workers < 1 {
 		log.Fatalf("invalid number of workers: %d\n", q.workers)
 	}
 
+	if q.telemetryOptions.BufferSize < 1 {
+		log.Fatalf("invalid telemetry buffer size: %d\n", q.telemetryOptions.BufferSize)
+	}
+
 	q.batchSize = 1
 	if q.useCase == Dashboard {
 		q.batchSize = q.queriesBatch
 		fmt.Printf("Dashboard simulation: %d batch, %s interval\n", q.batchSize, q.waitInterval)
 	}
+	q.validateArrivals()
 	if q.gradualWorkersIncrease {
 		fmt.Printf("Gradual workers increasing in %s interval\n", q.increaseInterval)
 		if q.gradualWorkersMax > 0 {
@@ -174,9 +221,14 @@ func (q *QueryBenchmarker) Validate() {
 		fmt.Printf("Response time limit set to %s\n", q.responseTimeLimit)
 	}
 
-	if q.reportHost != "" {
-		fmt.Printf("results report destination: %v\n", q.reportHost)
-		fmt.Printf("results report database: %v\n", q.reportDatabase)
+	if q.reportHost != "" || q.reportSinks != "" || q.summaryFile != "" {
+		if q.reportHost != "" {
+			fmt.Printf("results report destination: %v\n", q.reportHost)
+			fmt.Printf("results report database: %v\n", q.reportDatabase)
+		}
+		if q.reportSinks != "" {
+			fmt.Printf("results report sinks: %v\n", q.reportSinks)
+		}
 
 		var err error
 		q.reportHostname, err = os.Hostname()
@@ -193,11 +245,34 @@ func (q *QueryBenchmarker) Validate() {
 				q.reportTags = append(q.reportTags, tagpair)
 			}
 		}
+		if q.queryManifestFile != "" {
+			m, err := bulkQueryGen.ReadQueryManifest(q.queryManifestFile)
+			if err != nil {
+				log.Fatalf("Error reading query manifest: %v\n", err)
+			}
+			q.reportTags = append(q.reportTags, m.ReportTags()...)
+			fmt.Printf("query manifest: use case %s, format %s, scale-var %d, %d queries, %s host distribution\n", m.UseCase, m.Format, m.ScaleVar, m.Queries, m.HostDistribution)
+		}
 		fmt.Printf("results report tags: %v\n", q.reportTags)
 	}
 
-	if q.reportTelemetry && q.reportHost == "" {
-		log.Fatalf("invalid configuration: cannot report telemetry without specified report host")
+	if (q.reportBucketId != "" && (q.reportOrgId == "" || q.reportAuthToken == "")) ||
+		(q.reportOrgId != "" && (q.reportBucketId == "" || q.reportAuthToken == "")) ||
+		(q.reportAuthToken != "" && (q.reportBucketId == "" || q.reportOrgId == "")) {
+		log.Fatalf("Missing mandatory InfluxDb 2 reporting parameter")
+	}
+	if q.reportBucketId != "" {
+		q.reportDatabase = q.reportBucketId
+	}
+
+	if q.reportTelemetry && q.reportHost == "" && q.reportSinks == "" {
+		log.Fatalf("invalid configuration: cannot report telemetry without specified report host or sink")
+	}
+	if q.reportResources && q.reportHost == "" && q.reportSinks == "" {
+		log.Fatalf("invalid configuration: cannot report resources without specified report host or sink")
+	}
+	if q.resourcesPid < 0 || (q.resourcesPid > 0 && !q.reportResources) {
+		log.Fatalf("invalid resources pid: %d (requires -report-resources)", q.resourcesPid)
 	}
 
 	if q.trendSamples <= 0 {
@@ -274,26 +349,46 @@ func (q *QueryBenchmarker) RunBenchmark(bulkQuery BulkQuery) {
 	q.statChan = make(chan *Stat, statChanBuff)
 
 	var telemetryChanPoints chan *report.Point
-	var telemetryChanDone chan struct{}
-
-	if q.reportTelemetry {
-		telemetryCollector := report.NewCollector(q.reportHost, q.reportDatabase, q.reportUser, q.reportPassword)
-		err = telemetryCollector.CreateDatabase()
-		if err != nil {
-			log.Fatalf("Error creating temetry db: %v\n", err)
+	var telemetryChanDone chan report.TelemetryStats
+	var telemetryStats *report.TelemetryStats
+	var telemetrySink chan *report.Point
+	var resourceSampler *report.ResourceSampler
+
+	q.openReportSink()
+	if q.reportTelemetry || q.reportResources {
+		telemetryChanPoints, telemetryChanDone = report.TelemetryRunAsync(q.reportSink, q.telemetryBatchSize, q.telemetryStderr, 0, q.telemetryOptions)
+		if q.reportTelemetry {
+			telemetrySink = telemetryChanPoints
+		}
+		if q.reportResources {
+			tags := make([][2]string, 0, len(q.reportTags)+1)
+			tags = append(tags, q.reportTags...)
+			tags = append(tags, [2]string{"client_type", "query"})
+			resourceSampler = report.NewResourceSampler(q.resourcesPid, tags)
+			resourceSampler.RunAsync(telemetryChanPoints, time.Second)
 		}
-		telemetryChanPoints, telemetryChanDone = report.TelemetryRunAsync(telemetryCollector, q.telemetryBatchSize, q.telemetryStderr, 0)
 	}
 
 	// Launch the stats processor:
 	q.statGroup.Add(1)
-	go q.processStats(telemetryChanPoints)
+	go q.processStats(telemetrySink)
 
 	bulkQuery.Prepare()
 
 	processor := bulkQuery.GetProcessor()
 
+	if q.agent != nil {
+		fmt.Println("waiting for the other agents...")
+		if err := q.agent.WaitStart(); err != nil {
+			log.Fatalf("Error waiting for coordinator: %v\n", err)
+		}
+	}
+
 	workersIncreaseStep := q.workers
+	if q.arrivalRate > 0 {
+		q.arrivals = newArrivalScheduler(q.arrivalRate, q.arrivalDistribution)
+		go q.arrivals.run(time.Now())
+	}
 	// Launch the query processors:
 	for i := 0; i < q.workers; i++ {
 		workersGroup.Add(1)
@@ -421,6 +516,7 @@ loop:
 		waitCh <- 1
 	}()
 	waitTimer := time.NewTimer(time.Minute * 10)
+	workersTimedOut := false
 waitLoop:
 	for {
 		select {
@@ -429,10 +525,14 @@ waitLoop:
 			break waitLoop
 		case <-waitTimer.C:
 			log.Println("Waiting for workers timeout")
+			workersTimedOut = true
 			break waitLoop
 		}
 	}
 	close(waitCh)
+	if q.arrivals != nil {
+		q.arrivals.stop()
+	}
 
 	close(q.statChan)
 
@@ -446,11 +546,40 @@ waitLoop:
 		log.Fatal(err)
 	}
 
-	if q.reportTelemetry {
+	var checked, mismatched int64
+	if q.verifyResults {
+		checked, mismatched = q.printVerification()
+	}
+
+	if telemetryChanPoints != nil {
 		fmt.Println("shutting down telemetry...")
+		if resourceSampler != nil {
+			resourceSampler.Stop()
+		}
 		close(telemetryChanPoints)
-		<-telemetryChanDone
+		stats := <-telemetryChanDone
+		telemetryStats = &stats
 		fmt.Println("done shutting down telemetry.")
+		stats.Print(q.telemetryOptions.SpillFile)
+	}
+
+	if q.agent != nil {
+		result := &coordinator.Result{
+			Workers:  q.workers,
+			Duration: wallTook,
+		}
+		for label, stat := range q.statMapping {
+			result.Queries = append(result.Queries, coordinator.QueryStat{Label: label, Count: stat.Count, Min: stat.Min, Max: stat.Max, Sum: stat.Sum})
+		}
+		if mismatched > 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("%d queries returned unexpected results", mismatched))
+		}
+		if workersTimedOut {
+			result.Errors = append(result.Errors, "Waiting for workers timeout")
+		}
+		if err := q.agent.Finish(result); err != nil {
+			log.Printf("Error sending result to coordinator: %v\n", err)
+		}
 	}
 
 	if q.notificationServer != nil {
@@ -472,24 +601,46 @@ waitLoop:
 		}
 	}
 
-	if q.reportHost != "" {
-		found := false
+	if q.reportSink != nil || q.summaryFile != "" {
+		found, foundHosts := false, false
 		for _, pair := range q.reportTags {
-			if pair[0] == "use_case" {
+			switch pair[0] {
+			case "use_case":
 				found = true
-				break
+			case "host_distribution":
+				foundHosts = true
 			}
 		}
 		if q.useCase != "" && !found {
 			q.reportTags = append(q.reportTags, [2]string{"use_case", q.useCase})
 		}
+		if !foundHosts {
+			// without a query manifest, the host distribution is told by the
+			// query labels
+			hosts := bulkQueryGen.UniformHostDistribution{}.String()
+			for label := range q.statMapping {
+				if label != AllQueriesLabel {
+					hosts = bulkQueryGen.HostDistributionOfLabel(label)
+					break
+				}
+			}
+			q.reportTags = append(q.reportTags, [2]string{"host_distribution", hosts})
+		}
 		extraVals := make([]report.ExtraVal, 0, 1)
+		if telemetryStats != nil {
+			extraVals = append(extraVals, report.ExtraVal{Name: "telemetry_spilled_points", Value: telemetryStats.Spilled})
+			extraVals = append(extraVals, report.ExtraVal{Name: "telemetry_dropped_points", Value: telemetryStats.Dropped})
+		}
 		q.reportTags = append(q.reportTags, [2]string{"batch_size", fmt.Sprintf("%d", q.batchSize)})
 		q.reportTags = append(q.reportTags, [2]string{"wait_interval", q.waitInterval.String()})
 		q.reportTags = append(q.reportTags, [2]string{"grad_workers_inc", fmt.Sprintf("%v", q.gradualWorkersIncrease)})
 		q.reportTags = append(q.reportTags, [2]string{"increase_interval", q.increaseInterval.String()})
 		q.reportTags = append(q.reportTags, [2]string{"benchmark_duration", q.testDuration.String()})
 		q.reportTags = append(q.reportTags, [2]string{"response_time_limit", q.responseTimeLimit.String()})
+		if q.arrivalRate > 0 {
+			q.reportTags = append(q.reportTags, [2]string{"arrival_rate", fmt.Sprintf("%g", q.arrivalRate)})
+			q.reportTags = append(q.reportTags, [2]string{"arrival_distribution", q.arrivalDistribution})
+		}
 		if responseTimeLimitReached {
 			q.reportTags = append(q.reportTags, [2]string{"response_time_limit_reached", fmt.Sprintf("%v", responseTimeLimitReached)})
 			extraVals = append(extraVals, report.ExtraVal{Name: "response_time_limit_workers", Value: int64(reponseTimeLimitWorkers)})
@@ -504,27 +655,66 @@ waitLoop:
 				Hostname:           q.reportHostname,
 				Workers:            q.workers,
 				ItemLimit:          int(q.limit),
+				Sink:               q.reportSink,
 			},
 			BurnIn: int64(q.burnIn),
 		}
+		if q.reportOrgId != "" {
+			reportParams.ReportOrgId = q.reportOrgId
+			reportParams.ReportAuthToken = q.reportAuthToken
+		}
 
 		reportParams.ReportTags, extraVals = bulkQuery.UpdateReport(reportParams, q.reportTags, extraVals)
 
-		if len(q.statMapping) > 2 {
-			for query, stat := range q.statMapping {
-				err = report.ReportQueryResult(reportParams, query, stat.Min, stat.Mean, stat.Max, stat.Count, wallTook, extraVals...)
+		if q.reportSink != nil {
+			if len(q.statMapping) > 2 {
+				for query, stat := range q.statMapping {
+					err = report.ReportQueryResult(reportParams, query, stat.Min, stat.Mean, stat.Max, stat.Count, wallTook, responseExtraVals(stat, extraVals)...)
+					if err != nil {
+						log.Fatal(err)
+					}
+				}
+			} else {
+				stat := q.statMapping[AllQueriesLabel]
+				err = report.ReportQueryResult(reportParams, AllQueriesLabel, stat.Min, stat.Mean, stat.Max, stat.Count, wallTook, responseExtraVals(stat, extraVals)...)
 				if err != nil {
 					log.Fatal(err)
 				}
 			}
-		} else {
-			stat := q.statMapping[AllQueriesLabel]
-			err = report.ReportQueryResult(reportParams, AllQueriesLabel, stat.Min, stat.Mean, stat.Max, stat.Count, wallTook, extraVals...)
-			if err != nil {
+			if err = q.reportSink.Close(); err != nil {
 				log.Fatal(err)
 			}
 		}
 
+		if q.summaryFile != "" {
+			summary := report.NewSummary(report.SummaryQuery, wallStart, wallEnd)
+			summary.SetParams(&reportParams.ReportParams)
+			summary.SetExtraVals(extraVals)
+			labels := make([]string, 0, len(q.statMapping))
+			for label := range q.statMapping {
+				labels = append(labels, label)
+			}
+			sort.Strings(labels)
+			for _, label := range labels {
+				stat := q.statMapping[label]
+				summary.AddQuery(label, stat.Count, stat.Min, stat.Mean, stat.Max, stat.Sum, responseExtraVals(stat, nil))
+			}
+			if mismatched > 0 {
+				summary.AddError(fmt.Sprintf("%d queries returned unexpected results", mismatched))
+				summary.ExitCode = 1
+			}
+			if responseTimeLimitReached {
+				summary.PrematureEnd = "Response time limit reached"
+			} else if q.sigtermReceived {
+				summary.PrematureEnd = "External notification"
+			}
+			if workersTimedOut {
+				summary.AddError("Waiting for workers timeout")
+			}
+			if err = summary.WriteFile(q.summaryFile); err != nil {
+				log.Fatalf("Error writing summary: %v\n", err)
+			}
+		}
 	}
 
 	// (Optional) create a memory profile:
@@ -537,6 +727,13 @@ waitLoop:
 		f.Close()
 	}
 
+	if q.verifyResults && checked == 0 {
+		log.Fatal("no query had expected results to verify")
+	}
+	if mismatched > 0 {
+		log.Fatalf("%d queries returned unexpected results", mismatched)
+	}
+
 }
 
 // processStats collects latency results, aggregating them into summary
@@ -572,6 +769,10 @@ func (q *QueryBenchmarker) processStats(telemetrySink chan *report.Point) {
 			q.movingAverageStat.Push(now, stat.Value)
 			q.statMapping[AllQueriesLabel].Push(stat.Value)
 			q.statMapping[string(stat.Label)].Push(stat.Value)
+			q.statMapping[AllQueriesLabel].PushResponse(stat.ResponseBytes, stat.ResponseRows)
+			q.statMapping[string(stat.Label)].PushResponse(stat.ResponseBytes, stat.ResponseRows)
+			q.statMapping[AllQueriesLabel].PushQueueDelay(stat.QueueDelay)
+			q.statMapping[string(stat.Label)].PushQueueDelay(stat.QueueDelay)
 			i++
 		}
 
@@ -594,6 +795,12 @@ func (q *QueryBenchmarker) processStats(telemetrySink chan *report.Point) {
 				p.AddInt64Field("queries", int64(i))
 				telemetrySink <- p
 			}
+			if q.agent != nil {
+				q.agent.SendProgress(coordinator.Progress{
+					Queries:          int64(i),
+					MeanResponseTime: q.movingAverageStat.Avg(),
+				})
+			}
 		}
 		// print stats to stderr (if printInterval is greater than zero):
 		if q.printInterval > 0 && i > 0 && i%q.printInterval == 0 && (int64(i) < q.limit || q.limit < 0) {
@@ -639,13 +846,87 @@ func fprintStats(w io.Writer, statGroups StatsMap) {
 		for len(paddedKey) < maxKeyLength {
 			paddedKey += " "
 		}
-		_, err := fmt.Fprintf(w, "%s : min: %8.2fms (%7.2f/sec), mean: %8.2fms (%7.2f/sec), max: %7.2fms (%6.2f/sec), count: %8d, sum: %5.1fsec \n", paddedKey, v.Min, minRate, v.Mean, meanRate, v.Max, maxRate, v.Count, v.Sum/1e3)
+		responses := ""
+		if v.ResponsesSized > 0 {
+			responses += fmt.Sprintf(", resp: %8.0fB", float64(v.ResponseBytes)/float64(v.ResponsesSized))
+		}
+		if v.ResponsesCounted > 0 {
+			responses += fmt.Sprintf(", rows: %8.1f, empty: %6d", float64(v.ResponseRows)/float64(v.ResponsesCounted), v.EmptyResponses)
+		}
+		if v.QueueDelays > 0 {
+			responses += fmt.Sprintf(", queue: mean: %8.2fms, max: %8.2fms", v.QueueDelaySum/float64(v.QueueDelays), v.QueueDelayMax)
+		}
+		_, err := fmt.Fprintf(w, "%s : min: %8.2fms (%7.2f/sec), mean: %8.2fms (%7.2f/sec), max: %7.2fms (%6.2f/sec), count: %8d, sum: %5.1fsec%s \n", paddedKey, v.Min, minRate, v.Mean, meanRate, v.Max, maxRate, v.Count, v.Sum/1e3, responses)
 		if err != nil {
 			log.Fatal(err)
 		}
 	}
 }
 
+// responseExtraVals appends the response statistics of stat, if measured,
+// to a copy of extraVals.
+func responseExtraVals(stat *StatGroup, extraVals []report.ExtraVal) []report.ExtraVal {
+	vals := append([]report.ExtraVal{}, extraVals...)
+	if stat.ResponsesSized > 0 {
+		vals = append(vals, report.ExtraVal{Name: "response_bytes_mean", Value: float64(stat.ResponseBytes) / float64(stat.ResponsesSized)})
+	}
+	if stat.ResponsesCounted > 0 {
+		vals = append(vals, report.ExtraVal{Name: "response_rows_mean", Value: float64(stat.ResponseRows) / float64(stat.ResponsesCounted)})
+		vals = append(vals, report.ExtraVal{Name: "empty_responses", Value: stat.EmptyResponses})
+	}
+	if stat.QueueDelays > 0 {
+		vals = append(vals, report.ExtraVal{Name: "queue_delay_mean", Value: stat.QueueDelaySum / float64(stat.QueueDelays)})
+		vals = append(vals, report.ExtraVal{Name: "queue_delay_max", Value: stat.QueueDelayMax})
+	}
+	return vals
+}
+
+// registerAgent registers with the coordinator, applying the assigned shard.
+func (q *QueryBenchmarker) registerAgent() {
+	agent, err := coordinator.Register(q.coordinatorAddr, coordinator.KindQuery)
+	if err != nil {
+		log.Fatalf("Error registering with coordinator %s: %v\n", q.coordinatorAddr, err)
+	}
+	q.agent = agent
+	if agent.Shard.Workers > 0 {
+		q.workers = agent.Shard.Workers
+	}
+	q.file = strings.Replace(q.file, "{group}", strconv.Itoa(agent.Shard.ClientIndex), -1)
+	q.reportTags = append(q.reportTags, [2]string{"client_index", strconv.Itoa(agent.Shard.ClientIndex)})
+	fmt.Printf("agent %d of %d of coordinator %s\n", agent.Shard.ClientIndex, agent.Shard.Clients, q.coordinatorAddr)
+}
+
+// Shard returns the shard assigned by the coordinator, nil if not an agent.
+func (q *QueryBenchmarker) Shard() *coordinator.Shard {
+	if q.agent == nil {
+		return nil
+	}
+	return &q.agent.Shard
+}
+
+// openReportSink creates the destinations of result metrics and telemetry
+// selected by the report flags, if any.
+func (q *QueryBenchmarker) openReportSink() {
+	sinks, err := report.NewSinks(q.reportSinks, q.reportPrometheusLinger)
+	if err != nil {
+		log.Fatalf("Error creating report sinks: %v\n", err)
+	}
+	if q.reportHost != "" {
+		if q.reportOrgId == "" {
+			s, err := report.NewInfluxSink(q.reportHost, q.reportDatabase, q.reportUser, q.reportPassword)
+			if err != nil {
+				log.Fatalf("Error creating report db: %v\n", err)
+			}
+			sinks = append(sinks, s)
+		} else {
+			sinks = append(sinks, report.NewInfluxSinkV2(q.reportHost, q.reportOrgId, q.reportDatabase, q.reportAuthToken))
+		}
+	}
+	if len(sinks) > 0 {
+		q.reportSink = report.MultiSink(sinks)
+	}
+}
+
 func (q *QueryBenchmarker) stopScan() {
 	q.scanCloseMutex.Lock()
 	if q.scanClose != nil {
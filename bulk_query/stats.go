@@ -12,6 +12,14 @@ type Stat struct {
 	Label []byte
 	Value float64
 	IsActual bool
+
+	// size of the response in bytes and number of result rows, -1 when unknown
+	ResponseBytes int64
+	ResponseRows  int64
+
+	// time in milliseconds the query waited past its intended start time in
+	// the open-loop mode, -1 in the closed-loop mode
+	QueueDelay float64
 }
 
 
@@ -25,6 +33,28 @@ func (s *Stat) InitWithActual(label []byte, value float64, isActual bool) {
 	s.Label = append(s.Label, label...)
 	s.Value = value
 	s.IsActual = isActual
+	s.ResponseBytes = -1
+	s.ResponseRows = -1
+	s.QueueDelay = -1
+}
+
+// SetResponse records the response size in bytes and the number of result
+// rows (series, documents, buckets, depending on the database), -1 when
+// unknown.
+func (s *Stat) SetResponse(bytes, rows int64) {
+	s.ResponseBytes = bytes
+	s.ResponseRows = rows
+}
+
+// SetQueueDelay records the time in milliseconds the query waited past its
+// intended start time in the open-loop mode, as returned by WaitArrival, and
+// adds it to the measured latency. A negative delay is ignored.
+func (s *Stat) SetQueueDelay(delay float64) {
+	if delay < 0 {
+		return
+	}
+	s.QueueDelay = delay
+	s.Value += delay
 }
 
 // StatGroup collects simple streaming statistics.
@@ -35,6 +65,18 @@ type StatGroup struct {
 	Sum  float64
 
 	Count int64
+
+	// response statistics, of the queries whose responses were measured
+	ResponseBytes    int64 // sum
+	ResponsesSized   int64
+	ResponseRows     int64 // sum
+	ResponsesCounted int64
+	EmptyResponses   int64
+
+	// queue delay statistics, of the queries issued in the open-loop mode
+	QueueDelaySum float64
+	QueueDelayMax float64
+	QueueDelays   int64
 }
 
 type StatsMap map[string]*StatGroup
@@ -66,6 +108,35 @@ func (s *StatGroup) Push(n float64) {
 	s.Count++
 }
 
+// PushResponse updates a StatGroup with the response size and number of rows
+// of a query, -1 when unknown.
+func (s *StatGroup) PushResponse(bytes, rows int64) {
+	if bytes >= 0 {
+		s.ResponseBytes += bytes
+		s.ResponsesSized++
+	}
+	if rows >= 0 {
+		s.ResponseRows += rows
+		s.ResponsesCounted++
+		if rows == 0 {
+			s.EmptyResponses++
+		}
+	}
+}
+
+// PushQueueDelay updates a StatGroup with the queue delay of a query, negative
+// when not measured.
+func (s *StatGroup) PushQueueDelay(delay float64) {
+	if delay < 0 {
+		return
+	}
+	s.QueueDelaySum += delay
+	if delay > s.QueueDelayMax {
+		s.QueueDelayMax = delay
+	}
+	s.QueueDelays++
+}
+
 // String makes a simple description of a StatGroup.
 func (s *StatGroup) String() string {
 	return fmt.Sprintf("min: %f, max: %f, mean: %f, count: %d, sum: %f", s.Min, s.Max, s.Mean, s.Count, s.Sum)
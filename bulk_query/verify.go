@@ -0,0 +1,90 @@
+package bulk_query
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// maxMismatchesPrinted limits the mismatch details printed per query label.
+const maxMismatchesPrinted = 5
+
+// verificationStats counts the verified queries of a label.
+type verificationStats struct {
+	Checked    int64
+	Mismatched int64
+	Mismatches []string // first mismatch details
+}
+
+// verification collects the results verification outcomes per query label.
+type verification struct {
+	mutex  sync.Mutex
+	labels map[string]*verificationStats
+}
+
+// InitVerification registers the results verification flags. Only the
+// benchmarkers able to verify the responses call it, the others reject
+// -verify-results as an unknown flag.
+func (q *QueryBenchmarker) InitVerification() {
+	flag.BoolVar(&q.verifyResults, "verify-results", false, "Compare responses to the expected results embedded in the queries (see bulk_query_gen -verify-manifest) and report mismatches per query label.")
+	flag.Float64Var(&q.verifyTolerance, "verify-tolerance", 1e-9, "Relative tolerance of float values when verifying results.")
+}
+
+func (q *QueryBenchmarker) VerifyResults() bool {
+	return q.verifyResults
+}
+
+func (q *QueryBenchmarker) VerifyTolerance() float64 {
+	return q.verifyTolerance
+}
+
+// RecordVerification records the outcome of checking the result of a query
+// labeled label against the expected one, mismatch being nil on match. It is
+// safe for concurrent use.
+func (q *QueryBenchmarker) RecordVerification(label []byte, mismatch error) {
+	v := q.verification
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	s, ok := v.labels[string(label)]
+	if !ok {
+		s = &verificationStats{}
+		v.labels[string(label)] = s
+	}
+	s.Checked++
+	if mismatch != nil {
+		s.Mismatched++
+		if len(s.Mismatches) < maxMismatchesPrinted {
+			s.Mismatches = append(s.Mismatches, mismatch.Error())
+		}
+	}
+}
+
+// printVerification prints the verification outcomes per query label and
+// returns the total numbers of checked and mismatched queries.
+func (q *QueryBenchmarker) printVerification() (checked, mismatched int64) {
+	v := q.verification
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	labels := make([]string, 0, len(v.labels))
+	for label := range v.labels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	fmt.Println("results verification:")
+	if len(labels) == 0 {
+		fmt.Println("  no query had expected results (see bulk_query_gen -verify-manifest)")
+	}
+	for _, label := range labels {
+		s := v.labels[label]
+		fmt.Printf("  %s: %d checked, %d mismatched\n", label, s.Checked, s.Mismatched)
+		for _, m := range s.Mismatches {
+			fmt.Printf("    %s\n", m)
+		}
+		checked += s.Checked
+		mismatched += s.Mismatched
+	}
+	return checked, mismatched
+}
@@ -3,7 +3,6 @@ package cassandra
 import (
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -62,7 +61,7 @@ func (d *CassandraDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Query
 // SELECT max(usage_user) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1m)
 func (d *CassandraDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	hostnames := []string{}
 	for _, n := range nn {
@@ -126,3 +125,145 @@ func (d *CassandraDevops) MeanCPUUsageDayByHourAllHostsGroupbyHost(qi bulkQueryg
 //	q.Path = []byte(fmt.Sprintf("/query?%s", v.Encode()))
 //	q.Body = nil
 //}
+
+// NewCassandraDevopsQuery returns a maker of generators producing Cassandra
+// queries of the single type populated by fill.
+func NewCassandraDevopsQuery(fill bulkQuerygen.DevopsQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.DevopsSingleQuery{
+			Devops:   newCassandraDevopsCommon(dbConfig, interval, duration, scaleVar).(*CassandraDevops),
+			NewQuery: func() bulkQuerygen.Query { return NewCassandraQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+// fillRawQuery sets the statements of a raw CQL query, joined by newlines.
+func fillRawQuery(qi bulkQuerygen.Query, humanLabel string, interval bulkQuerygen.TimeInterval, statements []string) {
+	q := qi.(*CassandraQuery)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, interval.StartString()))
+	q.TimeStart = interval.Start
+	q.TimeEnd = interval.End
+	q.RawCQL = []byte(strings.Join(statements, "\n"))
+}
+
+func quotedHostnames(hostnames []string) string {
+	quoted := make([]string, len(hostnames))
+	for i, s := range hostnames {
+		quoted[i] = fmt.Sprintf("'%s'", s)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// LastPointPerHost populates a Query with one statement per host that looks like:
+// SELECT * FROM measurements.cpu WHERE hostname = '$HOSTNAME' ORDER BY time DESC LIMIT 1
+func (d *CassandraDevops) LastPointPerHost(qi bulkQuerygen.Query) {
+	hostnames := bulkQuerygen.AllHostnames(d.ScaleVar)
+	statements := make([]string, len(hostnames))
+	for i, h := range hostnames {
+		statements[i] = fmt.Sprintf("SELECT * FROM measurements.cpu WHERE hostname = '%s' ORDER BY time DESC LIMIT 1", h)
+	}
+
+	humanLabel := "Cassandra last cpu point, all hosts"
+	fillRawQuery(qi, humanLabel, d.AllInterval, statements)
+}
+
+func (d *CassandraDevops) HighCPUOneHost(q bulkQuerygen.Query) {
+	d.highCPU(q, d.RandomHostnames(1))
+}
+
+func (d *CassandraDevops) HighCPUAllHosts(q bulkQuerygen.Query) {
+	d.highCPU(q, nil)
+}
+
+// highCPU populates a Query with a query that looks like:
+// SELECT * FROM measurements.cpu WHERE hostname IN ('$HOSTNAME') AND time >= $START AND time < $END AND usage_user > 90.0 ALLOW FILTERING
+func (d *CassandraDevops) highCPU(qi bulkQuerygen.Query, hostnames []string) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+
+	hosts := "all hosts"
+	hostCondition := ""
+	if len(hostnames) > 0 {
+		hostCondition = fmt.Sprintf("hostname IN (%s) AND ", quotedHostnames(hostnames))
+		hosts = fmt.Sprintf("rand %4d hosts", len(hostnames))
+	}
+
+	humanLabel := fmt.Sprintf("Cassandra high cpu, %s, rand 12h", hosts)
+	fillRawQuery(qi, humanLabel, interval, []string{
+		fmt.Sprintf("SELECT * FROM measurements.cpu WHERE %stime >= %d AND time < %d AND usage_user > %.1f ALLOW FILTERING", hostCondition, interval.StartUnixNano(), interval.EndUnixNano(), bulkQuerygen.HighCPUThreshold),
+	})
+}
+
+func (d *CassandraDevops) CPUMaxAllOneHost(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 1)
+}
+
+func (d *CassandraDevops) CPUMaxAllEightHosts(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 8)
+}
+
+// cpuMaxAll populates a Query with one statement per hour that looks like:
+// SELECT max(usage_user),...,max(usage_guest_nice) FROM measurements.cpu WHERE hostname IN ('$HOSTNAME_1',...,'$HOSTNAME_N') AND time >= $HOUR_START AND time < $HOUR_END
+func (d *CassandraDevops) cpuMaxAll(qi bulkQuerygen.Query, nhosts int) {
+	interval := d.AllInterval.RandWindow(8 * time.Hour)
+	hostnames := quotedHostnames(d.RandomHostnames(nhosts))
+
+	selectClauses := make([]string, len(bulkQuerygen.CPUMetrics))
+	for i, m := range bulkQuerygen.CPUMetrics {
+		selectClauses[i] = fmt.Sprintf("max(%s)", m)
+	}
+	statements := []string{}
+	for t := interval.Start; t.Before(interval.End); t = t.Add(time.Hour) {
+		statements = append(statements, fmt.Sprintf("SELECT %s FROM measurements.cpu WHERE hostname IN (%s) AND time >= %d AND time < %d", strings.Join(selectClauses, ","), hostnames, t.UnixNano(), t.Add(time.Hour).UnixNano()))
+	}
+
+	humanLabel := fmt.Sprintf("Cassandra max of all cpu fields, rand %4d hosts, rand 8h by 1h", nhosts)
+	fillRawQuery(qi, humanLabel, interval, statements)
+}
+
+func (d *CassandraDevops) DoubleGroupByOneMetric(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 1)
+}
+
+func (d *CassandraDevops) DoubleGroupByFiveMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 5)
+}
+
+func (d *CassandraDevops) DoubleGroupByAllMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, len(bulkQuerygen.CPUMetrics))
+}
+
+// doubleGroupBy populates a Query with one statement per hour that looks like:
+// SELECT hostname,avg(usage_user),...,avg($METRIC_N) FROM measurements.cpu WHERE time >= $HOUR_START AND time < $HOUR_END GROUP BY hostname ALLOW FILTERING
+func (d *CassandraDevops) doubleGroupBy(qi bulkQuerygen.Query, nmetrics int) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	metrics := bulkQuerygen.CPUMetrics[:nmetrics]
+
+	selectClauses := make([]string, len(metrics))
+	for i, m := range metrics {
+		selectClauses[i] = fmt.Sprintf("avg(%s)", m)
+	}
+	statements := []string{}
+	for t := interval.Start; t.Before(interval.End); t = t.Add(time.Hour) {
+		statements = append(statements, fmt.Sprintf("SELECT hostname,%s FROM measurements.cpu WHERE time >= %d AND time < %d GROUP BY hostname ALLOW FILTERING", strings.Join(selectClauses, ","), t.UnixNano(), t.Add(time.Hour).UnixNano()))
+	}
+
+	humanLabel := fmt.Sprintf("Cassandra mean of %d cpu fields, all hosts, rand 12h by 1h", nmetrics)
+	fillRawQuery(qi, humanLabel, interval, statements)
+}
+
+// TopFiveHostsByMemory populates a Query with a query that looks like:
+// SELECT hostname,max(used_percent) FROM measurements.mem WHERE time >= $START AND time < $END GROUP BY hostname ALLOW FILTERING
+//
+// Cassandra cannot order by an aggregate, the maxima of all hosts are returned
+// and the top five are picked by the client.
+func (d *CassandraDevops) TopFiveHostsByMemory(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	humanLabel := "Cassandra top 5 hosts by max memory used, rand 1h"
+	fillRawQuery(qi, humanLabel, interval, []string{
+		fmt.Sprintf("SELECT hostname,max(used_percent) FROM measurements.mem WHERE time >= %d AND time < %d GROUP BY hostname ALLOW FILTERING", interval.StartUnixNano(), interval.EndUnixNano()),
+	})
+	qi.(*CassandraQuery).RawCQLTopN = 5
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	bulkDataGenIot "github.com/influxdata/influxdb-comparisons/bulk_data_gen/iot"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -39,7 +38,7 @@ func (d *CassandraIot) AverageTemperatureDayByHourOneHome(q bulkQuerygen.Query)
 // SELECT avg(temperature) from air_condition_room where (home_id = '$HHOME_ID_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1h)
 func (d *CassandraIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query, nHomes int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nHomes]
+	nn := d.RandomHostIndexes(nHomes)
 
 	homes := []string{}
 	for _, n := range nn {
@@ -68,3 +67,84 @@ func (d *CassandraIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query,
 
 	q.TagsCondition = []byte(combinedHomesClause)
 }
+
+// NewCassandraIotQuery returns a maker of generators producing Cassandra
+// queries of the single iot type populated by fill.
+func NewCassandraIotQuery(fill bulkQuerygen.IotQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.IotSingleQuery{
+			Iot:      newCassandraIotCommon(dbConfig, interval, duration, scaleVar).(*CassandraIot),
+			NewQuery: func() bulkQuerygen.Query { return NewCassandraQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+// OpenDoorsWindowsOneHome populates a Query with statements that look like:
+// SELECT door_id, state FROM measurements.door_state WHERE home_id = '$HOME_ID' AND time >= $START AND time < $END AND state = 1 ALLOW FILTERING
+//
+// Cassandra can group only by primary key columns, the readings are counted
+// per door and window by the client.
+func (d *CassandraIot) OpenDoorsWindowsOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	humanLabel := "Cassandra open doors and windows, rand    1 homes, rand 12h"
+	fillRawQuery(qi, humanLabel, interval, []string{
+		fmt.Sprintf("SELECT door_id, state FROM measurements.door_state WHERE home_id = '%s' AND time >= %d AND time < %d AND state = 1 ALLOW FILTERING", home, interval.StartUnixNano(), interval.EndUnixNano()),
+		fmt.Sprintf("SELECT room_id, window_id, state FROM measurements.window_state_room WHERE home_id = '%s' AND time >= %d AND time < %d AND state = 1 ALLOW FILTERING", home, interval.StartUnixNano(), interval.EndUnixNano()),
+	})
+}
+
+// WaterLeakageAllHomes populates a Query with a query that looks like:
+// SELECT home_id, room_id FROM measurements.water_leakage_room WHERE time >= $START AND time < $END AND leakage = 1 ALLOW FILTERING
+func (d *CassandraIot) WaterLeakageAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	humanLabel := "Cassandra water leakage, all homes, rand 1h"
+	fillRawQuery(qi, humanLabel, interval, []string{
+		fmt.Sprintf("SELECT home_id, room_id FROM measurements.water_leakage_room WHERE time >= %d AND time < %d AND leakage = 1 ALLOW FILTERING", interval.StartUnixNano(), interval.EndUnixNano()),
+	})
+}
+
+// RadiatorValveVsTemperatureOneHome populates a Query with statements that
+// fetch the radiator valve openings and indoor temperatures of one home. The
+// hourly means per room are computed by the client.
+func (d *CassandraIot) RadiatorValveVsTemperatureOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	humanLabel := "Cassandra radiator valve vs temperature, rand    1 homes, rand 12h by 1h"
+	fillRawQuery(qi, humanLabel, interval, []string{
+		fmt.Sprintf("SELECT time, room_id, opening_level FROM measurements.radiator_valve_room WHERE home_id = '%s' AND time >= %d AND time < %d", home, interval.StartUnixNano(), interval.EndUnixNano()),
+		fmt.Sprintf("SELECT time, room_id, temperature FROM measurements.air_condition_room WHERE home_id = '%s' AND time >= %d AND time < %d", home, interval.StartUnixNano(), interval.EndUnixNano()),
+	})
+}
+
+// BatteryLowAllHomes populates a Query with one statement per measurement
+// that looks like:
+// SELECT home_id, sensor_id, battery_voltage FROM measurements.air_condition_room WHERE time >= $START AND time < $END AND battery_voltage < 2.0 ALLOW FILTERING
+func (d *CassandraIot) BatteryLowAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	statements := make([]string, len(bulkQuerygen.IotBatteryMeasurements))
+	for i, m := range bulkQuerygen.IotBatteryMeasurements {
+		statements[i] = fmt.Sprintf("SELECT home_id, sensor_id, battery_voltage FROM measurements.%s WHERE time >= %d AND time < %d AND battery_voltage < %g ALLOW FILTERING", m, interval.StartUnixNano(), interval.EndUnixNano(), bulkQuerygen.IotBatteryLowThreshold)
+	}
+
+	humanLabel := "Cassandra battery low sensors, all homes, rand 1h"
+	fillRawQuery(qi, humanLabel, interval, statements)
+}
+
+// LastStatePerRoomOneHome populates a Query with a query that looks like:
+// SELECT * FROM measurements.air_condition_room WHERE home_id = '$HOME_ID' ORDER BY time DESC LIMIT 100
+//
+// The latest row of each room is picked by the client.
+func (d *CassandraIot) LastStatePerRoomOneHome(qi bulkQuerygen.Query) {
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	humanLabel := "Cassandra last state per room, rand    1 homes"
+	fillRawQuery(qi, humanLabel, d.AllInterval, []string{
+		fmt.Sprintf("SELECT * FROM measurements.air_condition_room WHERE home_id = '%s' ORDER BY time DESC LIMIT 100", home),
+	})
+}
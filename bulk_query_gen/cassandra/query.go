@@ -19,6 +19,13 @@ type CassandraQuery struct {
 	TimeEnd         time.Time
 	GroupByDuration time.Duration
 	TagsCondition   []byte
+
+	// RawCQL holds newline separated CQL statements executed as they are,
+	// for queries which do not fit the single aggregation model above.
+	RawCQL []byte
+	// RawCQLTopN, if positive, keeps only the rows of each RawCQL statement
+	// with the N largest values of the last column.
+	RawCQLTopN int64
 }
 
 var CassandraQueryPool sync.Pool = sync.Pool{
@@ -30,6 +37,7 @@ var CassandraQueryPool sync.Pool = sync.Pool{
 			FieldName:        []byte{},
 			AggregationType:  []byte{},
 			TagsCondition:    []byte{},
+			RawCQL:           []byte{},
 		}
 	},
 }
@@ -40,12 +48,15 @@ func NewCassandraQuery() *CassandraQuery {
 
 // String produces a debug-ready description of a Query.
 func (q *CassandraQuery) String() string {
-	return fmt.Sprintf("HumanLabel: %s, HumanDescription: %s, MeasurementName: %s, AggregationType: %s, TimeStart: %s, TimeEnd: %s, GroupByDuration: %s, TagSets: %s", q.HumanLabel, q.HumanDescription, q.MeasurementName, q.AggregationType, q.TimeStart, q.TimeEnd, q.GroupByDuration, q.TagsCondition)
+	return fmt.Sprintf("HumanLabel: %s, HumanDescription: %s, MeasurementName: %s, AggregationType: %s, TimeStart: %s, TimeEnd: %s, GroupByDuration: %s, TagSets: %s, RawCQL: %s, RawCQLTopN: %d", q.HumanLabel, q.HumanDescription, q.MeasurementName, q.AggregationType, q.TimeStart, q.TimeEnd, q.GroupByDuration, q.TagsCondition, q.RawCQL, q.RawCQLTopN)
 }
 
 func (q *CassandraQuery) HumanLabelName() []byte {
 	return q.HumanLabel
 }
+func (q *CassandraQuery) SetHumanLabel(label []byte) {
+	q.HumanLabel = label
+}
 func (q *CassandraQuery) HumanDescriptionName() []byte {
 	return q.HumanDescription
 }
@@ -61,6 +72,8 @@ func (q *CassandraQuery) Release() {
 	q.TimeStart = time.Time{}
 	q.TimeEnd = time.Time{}
 	q.TagsCondition = q.TagsCondition[:0]
+	q.RawCQL = q.RawCQL[:0]
+	q.RawCQLTopN = 0
 
 	CassandraQueryPool.Put(q)
 }
@@ -1,13 +1,36 @@
 package bulk_query_gen
 
+import "fmt"
+
 type CommonParams struct {
 	AllInterval TimeInterval
 	ScaleVar    int
+	Hosts       HostDistribution
 }
 
 func NewCommonParams(interval TimeInterval, scaleVar int) *CommonParams {
 	return &CommonParams{
 		AllInterval: interval,
 		ScaleVar:    scaleVar,
+		Hosts:       DefaultHostDistribution,
+	}
+}
+
+// RandomHostIndex returns the index of a host picked according to the host distribution.
+func (p *CommonParams) RandomHostIndex() int {
+	return p.Hosts.Pick(p.ScaleVar)
+}
+
+// RandomHostIndexes returns the indexes of n distinct hosts picked according to the host distribution.
+func (p *CommonParams) RandomHostIndexes(n int) []int {
+	return p.Hosts.PickN(p.ScaleVar, n)
+}
+
+// RandomHostnames returns the names of n distinct hosts picked according to the host distribution.
+func (p *CommonParams) RandomHostnames(n int) []string {
+	hostnames := make([]string, 0, n)
+	for _, i := range p.RandomHostIndexes(n) {
+		hostnames = append(hostnames, fmt.Sprintf("host_%d", i))
 	}
+	return hostnames
 }
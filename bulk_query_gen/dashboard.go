@@ -0,0 +1,133 @@
+package bulk_query_gen
+
+import (
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/dashboard"
+	"math/rand"
+	"time"
+)
+
+// Dashboard describes a dashboard query generator. Every method populates
+// a Query with the query behind one panel of the dashboard use case.
+type Dashboard interface {
+	Availability(Query)
+	CpuNum(Query)
+	CpuUtilization(Query)
+	DiskAllocated(Query)
+	DiskUsage(Query)
+	DiskUtilization(Query)
+	HttpRequestDuration(Query)
+	HttpRequests(Query)
+	KapaCpu(Query)
+	KapaLoad(Query)
+	KapaRam(Query)
+	MemoryTotal(Query)
+	MemoryUtilization(Query)
+	NginxRequests(Query)
+	QueueBytes(Query)
+	RedisMemoryUtilization(Query)
+	SystemLoad(Query)
+	Throughput(Query)
+}
+
+// DashboardQueryFunc populates a Query using one of the Dashboard methods,
+// e.g. Dashboard.CpuUtilization.
+type DashboardQueryFunc func(Dashboard, Query)
+
+// DashboardPanels lists all the dashboard queries in the order of the
+// dashboard-all batch mix.
+var DashboardPanels = []DashboardQueryFunc{
+	Dashboard.Availability,
+	Dashboard.CpuNum,
+	Dashboard.CpuUtilization,
+	Dashboard.DiskAllocated,
+	Dashboard.DiskUsage,
+	Dashboard.DiskUtilization,
+	Dashboard.HttpRequestDuration,
+	Dashboard.HttpRequests,
+	Dashboard.KapaCpu,
+	Dashboard.KapaLoad,
+	Dashboard.KapaRam,
+	Dashboard.MemoryTotal,
+	Dashboard.MemoryUtilization,
+	Dashboard.NginxRequests,
+	Dashboard.QueueBytes,
+	Dashboard.RedisMemoryUtilization,
+	Dashboard.SystemLoad,
+	Dashboard.Throughput,
+}
+
+// DashboardSingleQuery is a QueryGenerator producing dashboard queries of one
+// type only.
+type DashboardSingleQuery struct {
+	Dashboard Dashboard
+	NewQuery  func() Query
+	Fill      DashboardQueryFunc
+}
+
+// Dispatch fulfills the QueryGenerator interface.
+func (g *DashboardSingleQuery) Dispatch(i int) Query {
+	q := g.NewQuery()
+	g.Fill(g.Dashboard, q)
+	return q
+}
+
+// DashboardAll is a QueryGenerator round-robining through generators of all
+// the dashboard queries. Each generator keeps its own time window, so every
+// panel slides through the data the same way as when generated alone.
+type DashboardAll struct {
+	Gens []QueryGenerator
+}
+
+// Dispatch fulfills the QueryGenerator interface.
+func (g *DashboardAll) Dispatch(i int) Query {
+	return g.Gens[i%len(g.Gens)].Dispatch(i)
+}
+
+// NewDashboardAllQuery returns a maker of generators producing all the
+// dashboard queries, using newQuery to make the single query generators.
+func NewDashboardAllQuery(newQuery func(DashboardQueryFunc) QueryGeneratorMaker) QueryGeneratorMaker {
+	return func(dbConfig DatabaseConfig, interval TimeInterval, duration time.Duration, scaleVar int) QueryGenerator {
+		g := &DashboardAll{}
+		for _, fill := range DashboardPanels {
+			g.Gens = append(g.Gens, newQuery(fill)(dbConfig, interval, duration, scaleVar))
+		}
+		return g
+	}
+}
+
+// DashboardCommon holds the parameters shared by the dashboard query
+// generators of all the databases.
+type DashboardCommon struct {
+	CommonParams
+	ClustersCount int
+	TimeWindow
+}
+
+// NewDashboardCommon makes a DashboardCommon for dashboard data of scaleVar
+// hosts, querying windows of the given duration.
+func NewDashboardCommon(interval TimeInterval, duration time.Duration, scaleVar int) *DashboardCommon {
+	clustersCount := scaleVar / dashboard.ClusterSize
+	if clustersCount == 0 {
+		clustersCount = 1
+	}
+	return &DashboardCommon{
+		CommonParams:  *NewCommonParams(interval, scaleVar),
+		ClustersCount: clustersCount,
+		TimeWindow:    TimeWindow{interval.Start, duration},
+	}
+}
+
+// NextInterval returns the time interval of the next query, sliding the
+// window when TimeWindowShift is set.
+func (d *DashboardCommon) NextInterval() TimeInterval {
+	if TimeWindowShift > 0 {
+		return d.TimeWindow.SlidingWindow(&d.AllInterval)
+	}
+	return d.AllInterval.RandWindow(d.Duration)
+}
+
+// RandomClusterId returns the cluster_id tag value of a random cluster.
+func (d *DashboardCommon) RandomClusterId() string {
+	return fmt.Sprintf("%d", rand.Intn(d.ClustersCount)+1)
+}
@@ -1,5 +1,26 @@
 package bulk_query_gen
 
+import "fmt"
+
+// CPUMetrics are the fields of the cpu measurement, in the order used by the
+// multi-metric devops queries.
+var CPUMetrics = []string{
+	"usage_user",
+	"usage_system",
+	"usage_idle",
+	"usage_nice",
+	"usage_iowait",
+	"usage_irq",
+	"usage_softirq",
+	"usage_steal",
+	"usage_guest",
+	"usage_guest_nice",
+}
+
+// HighCPUThreshold is the usage_user value above which a host is considered
+// to have high CPU usage.
+const HighCPUThreshold = 90.0
+
 // Devops describes a devops query generator.
 type Devops interface {
 	MaxCPUUsageHourByMinuteOneHost(Query)
@@ -15,9 +36,101 @@ type Devops interface {
 
 	//CountCPUUsageDayByHourAllHostsGroupbyHost(Query)
 
+	// LastPointPerHost selects the last cpu point of every host.
+	LastPointPerHost(Query)
+
+	// HighCPU* select cpu points with usage_user above HighCPUThreshold
+	// during random 12 hours.
+	HighCPUOneHost(Query)
+	HighCPUAllHosts(Query)
+
+	// CPUMaxAll* select max of all cpu metrics of random hosts during random
+	// 8 hours by 1 hour.
+	CPUMaxAllOneHost(Query)
+	CPUMaxAllEightHosts(Query)
+
+	// DoubleGroupBy* select mean of first N cpu metrics during random
+	// 12 hours grouped by 1 hour and hostname.
+	DoubleGroupByOneMetric(Query)
+	DoubleGroupByFiveMetrics(Query)
+	DoubleGroupByAllMetrics(Query)
+
+	// TopFiveHostsByMemory selects 5 hosts with the highest max mem
+	// used_percent during random 1 hour.
+	TopFiveHostsByMemory(Query)
+
 	Dispatch(int) Query
 }
 
+// DevopsQueryFunc populates a Query using one of the Devops methods, e.g.
+// Devops.LastPointPerHost.
+type DevopsQueryFunc func(Devops, Query)
+
+// DevopsSingleQuery is a QueryGenerator producing queries of one type only.
+type DevopsSingleQuery struct {
+	Devops   Devops
+	NewQuery func() Query
+	Fill     DevopsQueryFunc
+}
+
+// Dispatch fulfills the QueryGenerator interface.
+func (g *DevopsSingleQuery) Dispatch(i int) Query {
+	q := g.NewQuery()
+	g.Fill(g.Devops, q)
+	return q
+}
+
+// DevopsNotImplemented can be embedded by the Devops implementations of
+// databases that do not support the whole devops query suite. Its methods
+// panic: the query types are not in the use case matrix for these databases,
+// bulk_query_gen rejects them before generating any query.
+type DevopsNotImplemented struct{}
+
+func (DevopsNotImplemented) LastPointPerHost(Query) {
+	panic("lastpoint query is not implemented")
+}
+
+func (DevopsNotImplemented) HighCPUOneHost(Query) {
+	panic("high-cpu query is not implemented")
+}
+
+func (DevopsNotImplemented) HighCPUAllHosts(Query) {
+	panic("high-cpu query is not implemented")
+}
+
+func (DevopsNotImplemented) CPUMaxAllOneHost(Query) {
+	panic("cpu-max-all query is not implemented")
+}
+
+func (DevopsNotImplemented) CPUMaxAllEightHosts(Query) {
+	panic("cpu-max-all query is not implemented")
+}
+
+func (DevopsNotImplemented) DoubleGroupByOneMetric(Query) {
+	panic("double-groupby query is not implemented")
+}
+
+func (DevopsNotImplemented) DoubleGroupByFiveMetrics(Query) {
+	panic("double-groupby query is not implemented")
+}
+
+func (DevopsNotImplemented) DoubleGroupByAllMetrics(Query) {
+	panic("double-groupby query is not implemented")
+}
+
+func (DevopsNotImplemented) TopFiveHostsByMemory(Query) {
+	panic("top-5-memory query is not implemented")
+}
+
+// AllHostnames returns hostnames of all scaleVar hosts.
+func AllHostnames(scaleVar int) []string {
+	hostnames := make([]string, 0, scaleVar)
+	for i := 0; i < scaleVar; i++ {
+		hostnames = append(hostnames, fmt.Sprintf("host_%d", i))
+	}
+	return hostnames
+}
+
 // devopsDispatchAll round-robins through the different devops queries.
 func DevopsDispatchAll(d Devops, iteration int, q Query, scaleVar int) {
 	if scaleVar <= 0 {
@@ -0,0 +1,399 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"fmt"
+	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
+	"text/template"
+	"time"
+)
+
+var dashboardStatsQuery, dashboardDocumentsQuery, dashboardHistogramQuery, dashboardRequestDurationQuery *template.Template
+
+func init() {
+	dashboardStatsQuery = template.Must(template.New("dashboardStatsQuery").Parse(rawDashboardFilter + rawDashboardStatsQuery))
+	dashboardDocumentsQuery = template.Must(template.New("dashboardDocumentsQuery").Parse(rawDashboardFilter + rawDashboardDocumentsQuery))
+	dashboardHistogramQuery = template.Must(template.New("dashboardHistogramQuery").Parse(rawDashboardFilter + rawDashboardHistogramQuery))
+	dashboardRequestDurationQuery = template.Must(template.New("dashboardRequestDurationQuery").Parse(rawDashboardFilter + rawDashboardRequestDurationQuery))
+}
+
+// ElasticSearchDashboard produces ES-specific queries for the dashboard use case.
+type ElasticSearchDashboard struct {
+	bulkQuerygen.DashboardCommon
+}
+
+// NewElasticSearchDashboardQuery returns a maker of generators producing ES
+// queries of the single dashboard panel populated by fill.
+func NewElasticSearchDashboardQuery(fill bulkQuerygen.DashboardQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(_ bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.DashboardSingleQuery{
+			Dashboard: &ElasticSearchDashboard{DashboardCommon: *bulkQuerygen.NewDashboardCommon(interval, duration, scaleVar)},
+			NewQuery:  func() bulkQuerygen.Query { return bulkQuerygen.NewHTTPQuery() },
+			Fill:      fill,
+		}
+	}
+}
+
+// NewElasticSearchDashboardAll produces ES queries of all the dashboard panels.
+var NewElasticSearchDashboardAll = bulkQuerygen.NewDashboardAllQuery(NewElasticSearchDashboardQuery)
+
+// DashboardQueryParams are the parameters of the dashboard query templates.
+// The documents are filtered by the time range and by every non-empty
+// ClusterId, Hostname, HostnamePrefix and Path.
+type DashboardQueryParams struct {
+	Start, End                                string
+	ClusterId, Hostname, HostnamePrefix, Path string
+	Size                                      int
+	Fields                                    []string
+	Aggregation                               string
+	GroupBy                                   []string
+	Bucket, DerivativeUnit                    string
+	Latest                                    bool
+	HostnameCount                             int
+}
+
+// fillDashboardQuery populates the query of a panel searching index, with
+// the time range of the next interval and a random cluster when clustered.
+func (d *ElasticSearchDashboard) fillDashboardQuery(qi bulkQuerygen.Query, panel, index string, clustered bool, t *template.Template, params DashboardQueryParams) {
+	if d.ScaleVar > 10000 {
+		panic("scaleVar > 10000 implies size > 10000, which is not supported on elasticsearch. see https://www.elastic.co/guide/en/elasticsearch/reference/current/search-request-from-size.html")
+	}
+	params.HostnameCount = d.ScaleVar
+
+	interval := d.NextInterval()
+	switch bulkQuerygen.QueryIntervalType {
+	case "window":
+		params.Start, params.End = interval.StartString(), interval.EndString()
+	case "last":
+		params.Start = fmt.Sprintf("now-%dh", int64(2*interval.Duration().Hours()))
+		params.End = fmt.Sprintf("now-%dh", int64(interval.Duration().Hours()))
+	case "recent":
+		params.Start = fmt.Sprintf("now-%dh", int64(interval.Duration().Hours()+24))
+		params.End = "now-24h"
+	}
+	cluster := ""
+	if clustered {
+		params.ClusterId = d.RandomClusterId()
+		cluster = ", rand cluster"
+	}
+
+	body := new(bytes.Buffer)
+	mustExecuteTemplate(t, body, params)
+
+	humanLabel := fmt.Sprintf("Elastic %s%s in %s", panel, cluster, interval.Duration())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, interval.StartString()))
+	q.Method = []byte("POST")
+	q.Path = []byte(fmt.Sprintf("/%s/_search", index))
+	q.Body = body.Bytes()
+}
+
+// Availability populates a Query with the mean service_up of a random cluster.
+func (d *ElasticSearchDashboard) Availability(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "availability (percent)", "status", true, dashboardStatsQuery, DashboardQueryParams{
+		Fields:      []string{"service_up"},
+		Aggregation: "avg",
+	})
+}
+
+// CpuNum populates a Query with the per minute maximal n_cpus, latest first.
+func (d *ElasticSearchDashboard) CpuNum(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "cpu (number) by 1m", "system", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:      []string{"n_cpus"},
+		Aggregation: "max",
+		Bucket:      "1m",
+		Latest:      true,
+	})
+}
+
+// CpuUtilization populates a Query with the mean usage_user per host by minute.
+func (d *ElasticSearchDashboard) CpuUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "cpu utilization (percent) by host, 1m", "cpu", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:      []string{"usage_user"},
+		Aggregation: "avg",
+		GroupBy:     []string{"hostname"},
+		Bucket:      "1m",
+	})
+}
+
+// DiskAllocated populates a Query with the per 2 minutes maximal disk total
+// of the data nodes, latest first.
+func (d *ElasticSearchDashboard) DiskAllocated(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "disk allocated (GB) by 120s", "disk", true, dashboardHistogramQuery, DashboardQueryParams{
+		HostnamePrefix: "data",
+		Fields:         []string{"total"},
+		Aggregation:    "max",
+		Bucket:         "120s",
+		Latest:         true,
+	})
+}
+
+// DiskUsage populates a Query with the latest used_percent of the data nodes disks.
+func (d *ElasticSearchDashboard) DiskUsage(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "disk usage (GB)", "disk", true, dashboardDocumentsQuery, DashboardQueryParams{
+		HostnamePrefix: "data",
+		Size:           1,
+		Fields:         []string{"used_percent"},
+	})
+}
+
+// DiskUtilization populates a Query with the maximal used_percent of the data
+// nodes system disk per host by minute.
+func (d *ElasticSearchDashboard) DiskUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "disk utilization (percent) by 1m", "disk", true, dashboardHistogramQuery, DashboardQueryParams{
+		HostnamePrefix: "data",
+		Path:           "/dev/sda1",
+		Fields:         []string{"used_percent"},
+		Aggregation:    "max",
+		GroupBy:        []string{"hostname"},
+		Bucket:         "1m",
+	})
+}
+
+// HttpRequestDuration populates a Query dividing the derivative of the per
+// minute 99th percentile of uptime_in_seconds by the derivative of the per
+// minute maximal total_connections_received, per host.
+func (d *ElasticSearchDashboard) HttpRequestDuration(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "http request duration (99th %) by host, 1m", "redis", true, dashboardRequestDurationQuery, DashboardQueryParams{
+		GroupBy: []string{"hostname"},
+		Bucket:  "1m",
+	})
+}
+
+// HttpRequests populates a Query with the derivative per 10 seconds of the
+// per minute mean requests, per host.
+func (d *ElasticSearchDashboard) HttpRequests(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "http requests/min (number) by 1m, host", "nginx", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:         []string{"requests"},
+		Aggregation:    "avg",
+		GroupBy:        []string{"hostname"},
+		Bucket:         "1m",
+		DerivativeUnit: "10s",
+	})
+}
+
+// KapaCpu populates a Query with the usage_idle documents of the kapacitor host.
+func (d *ElasticSearchDashboard) KapaCpu(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "kapa cpu", "cpu", false, dashboardDocumentsQuery, DashboardQueryParams{
+		Hostname: "kapacitor_1",
+		Size:     10000,
+		Fields:   []string{"usage_idle"},
+	})
+}
+
+// KapaLoad populates a Query with the load documents of the kapacitor host.
+func (d *ElasticSearchDashboard) KapaLoad(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "kapa load 1,5,15", "system", false, dashboardDocumentsQuery, DashboardQueryParams{
+		Hostname: "kapacitor_1",
+		Size:     10000,
+		Fields:   []string{"load5", "load15", "load1"},
+	})
+}
+
+// KapaRam populates a Query with the used_percent documents of the kapacitor host.
+func (d *ElasticSearchDashboard) KapaRam(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "kapa mem used", "mem", false, dashboardDocumentsQuery, DashboardQueryParams{
+		Hostname: "kapacitor_1",
+		Size:     10000,
+		Fields:   []string{"used_percent"},
+	})
+}
+
+// MemoryTotal populates a Query with the per minute maximal memory total of
+// the data nodes, latest first.
+func (d *ElasticSearchDashboard) MemoryTotal(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "memory (MB) by 1m", "mem", true, dashboardHistogramQuery, DashboardQueryParams{
+		HostnamePrefix: "data",
+		Fields:         []string{"total"},
+		Aggregation:    "max",
+		Bucket:         "1m",
+		Latest:         true,
+	})
+}
+
+// MemoryUtilization populates a Query with the mean used_percent per host by minute.
+func (d *ElasticSearchDashboard) MemoryUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "memory utilization (percent) by 1m", "mem", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:      []string{"used_percent"},
+		Aggregation: "avg",
+		GroupBy:     []string{"hostname"},
+		Bucket:      "1m",
+	})
+}
+
+// NginxRequests populates a Query with the derivative per second of the per
+// minute mean accepts, per host.
+func (d *ElasticSearchDashboard) NginxRequests(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "queries executed (number) by 1m, host", "nginx", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:         []string{"accepts"},
+		Aggregation:    "avg",
+		GroupBy:        []string{"hostname"},
+		Bucket:         "1m",
+		DerivativeUnit: "1s",
+	})
+}
+
+// QueueBytes populates a Query with the mean temp_files per host by minute.
+func (d *ElasticSearchDashboard) QueueBytes(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "hinted handoff queue size (MB) by 1m", "postgresl", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:      []string{"temp_files"},
+		Aggregation: "avg",
+		GroupBy:     []string{"hostname"},
+		Bucket:      "1m",
+	})
+}
+
+// RedisMemoryUtilization populates a Query with the mean used_memory per host
+// and server by minute.
+func (d *ElasticSearchDashboard) RedisMemoryUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "redis memory utilization by 1m", "redis", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:      []string{"used_memory"},
+		Aggregation: "avg",
+		GroupBy:     []string{"hostname", "server"},
+		Bucket:      "1m",
+	})
+}
+
+// SystemLoad populates a Query with the maximal load5 and n_cpus per host by minute.
+func (d *ElasticSearchDashboard) SystemLoad(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "system load (load5) by 1m", "system", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:      []string{"load5", "n_cpus"},
+		Aggregation: "max",
+		GroupBy:     []string{"hostname"},
+		Bucket:      "1m",
+	})
+}
+
+// Throughput populates a Query with the derivative per 10 seconds of the per
+// minute maximal keyspace_hits, per host.
+func (d *ElasticSearchDashboard) Throughput(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "per-host point throughput (number) by 1m", "redis", true, dashboardHistogramQuery, DashboardQueryParams{
+		Fields:         []string{"keyspace_hits"},
+		Aggregation:    "max",
+		GroupBy:        []string{"hostname"},
+		Bucket:         "1m",
+		DerivativeUnit: "10s",
+	})
+}
+
+const rawDashboardFilter = `{{define "filter"}}
+    "bool": {
+      "filter": [
+        {
+          "range": {
+            "timestamp": {
+              "gte": "{{.Start}}",
+              "lt": "{{.End}}"
+            }
+          }
+        }{{if .ClusterId}},
+        { "term": { "cluster_id": "{{.ClusterId}}" } }{{end}}{{if .Hostname}},
+        { "term": { "hostname": "{{.Hostname}}" } }{{end}}{{if .HostnamePrefix}},
+        { "prefix": { "hostname": "{{.HostnamePrefix}}" } }{{end}}{{if .Path}},
+        { "term": { "path": "{{.Path}}" } }{{end}}
+      ]
+    }
+{{- end}}`
+
+const rawDashboardStatsQuery = `
+{
+  "size": 0,
+  "query": {{"{"}}{{template "filter" .}}
+  },
+  "aggs": {
+    {{- range $i, $f := .Fields}}{{if $i}},{{end}}
+    "{{$.Aggregation}}_{{$f}}": { "{{$.Aggregation}}": { "field": "{{$f}}" } }
+    {{- end}}
+  }
+}
+`
+
+const rawDashboardDocumentsQuery = `
+{
+  "size": {{.Size}},
+  "_source": [ "timestamp"{{range .Fields}}, "{{.}}"{{end}} ],
+  "sort": [ { "timestamp": { "order": "desc" } } ],
+  "query": {{"{"}}{{template "filter" .}}
+  }
+}
+`
+
+const rawDashboardHistogramQuery = `
+{
+  "size": 0,
+  "query": {{"{"}}{{template "filter" .}}
+  },
+  "aggs": {
+    {{- range .GroupBy}}
+    "by_{{.}}": {
+      "terms": {
+        "size": {{$.HostnameCount}},
+        "field": "{{.}}"
+      },
+      "aggs": {
+    {{- end}}
+    "result": {
+      "date_histogram": {
+        "field": "timestamp",
+        "interval": "{{.Bucket}}",
+        "min_doc_count": {{if .Latest}}1,
+        "order": { "_key": "desc" }{{else}}0{{end}}
+      },
+      "aggs": {
+        {{- range $i, $f := .Fields}}{{if $i}},{{end}}
+        "{{$.Aggregation}}_{{$f}}": { "{{$.Aggregation}}": { "field": "{{$f}}" } }
+        {{- if $.DerivativeUnit}},
+        "derivative_{{$f}}": { "derivative": { "buckets_path": "{{$.Aggregation}}_{{$f}}", "unit": "{{$.DerivativeUnit}}" } }
+        {{- end}}
+        {{- end}}
+      }
+    }
+    {{- range .GroupBy}}
+      }
+    }
+    {{- end}}
+  }
+}
+`
+
+const rawDashboardRequestDurationQuery = `
+{
+  "size": 0,
+  "query": {{"{"}}{{template "filter" .}}
+  },
+  "aggs": {
+    {{- range .GroupBy}}
+    "by_{{.}}": {
+      "terms": {
+        "size": {{$.HostnameCount}},
+        "field": "{{.}}"
+      },
+      "aggs": {
+    {{- end}}
+    "result": {
+      "date_histogram": {
+        "field": "timestamp",
+        "interval": "{{.Bucket}}",
+        "min_doc_count": 0
+      },
+      "aggs": {
+        "p99_uptime_in_seconds": { "percentiles": { "field": "uptime_in_seconds", "percents": [ 99 ] } },
+        "max_total_connections_received": { "max": { "field": "total_connections_received" } },
+        "derivative_p99": { "derivative": { "buckets_path": "p99_uptime_in_seconds[99.0]" } },
+        "derivative_max": { "derivative": { "buckets_path": "max_total_connections_received" } },
+        "duration": {
+          "bucket_script": {
+            "buckets_path": { "p99": "derivative_p99", "connections": "derivative_max" },
+            "script": "params.connections > 0 ? params.p99 / params.connections : 0"
+          }
+        }
+      }
+    }
+    {{- range .GroupBy}}
+      }
+    }
+    {{- end}}
+  }
+}
+`
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
 	"io"
-	"math/rand"
 	"strings"
 	"text/template"
 	"time"
@@ -13,12 +12,20 @@ import (
 
 var (
 	fleetQuery, fleetGroupByHostnameQuery, hostsQuery *template.Template
+
+	lastPointQuery, highCPUQuery, cpuMaxAllQuery, doubleGroupByQuery, topHostsByMemoryQuery *template.Template
 )
 
 func init() {
 	fleetQuery = template.Must(template.New("fleetQuery").Parse(rawFleetQuery))
 	fleetGroupByHostnameQuery = template.Must(template.New("fleetGroupByHostnameQuery").Parse(rawFleetGroupByHostnameQuery))
 	hostsQuery = template.Must(template.New("hostsQuery").Parse(rawHostsQuery))
+
+	lastPointQuery = template.Must(template.New("lastPointQuery").Parse(rawLastPointQuery))
+	highCPUQuery = template.Must(template.New("highCPUQuery").Parse(rawHighCPUQuery))
+	cpuMaxAllQuery = template.Must(template.New("cpuMaxAllQuery").Parse(rawCPUMaxAllQuery))
+	doubleGroupByQuery = template.Must(template.New("doubleGroupByQuery").Parse(rawDoubleGroupByQuery))
+	topHostsByMemoryQuery = template.Must(template.New("topHostsByMemoryQuery").Parse(rawTopHostsByMemoryQuery))
 }
 
 // ElasticSearchDevops produces ES-specific queries for the devops use case.
@@ -79,7 +86,7 @@ func (d *ElasticSearchDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Q
 
 func (d *ElasticSearchDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	hostnames := []string{}
 	for _, n := range nn {
@@ -138,6 +145,139 @@ func (d *ElasticSearchDevops) MeanCPUUsageDayByHourAllHostsGroupbyHost(qi bulkQu
 	q.Body = body.Bytes()
 }
 
+// NewElasticSearchDevopsQuery returns a maker of generators producing ES
+// queries of the single type populated by fill.
+func NewElasticSearchDevopsQuery(fill bulkQuerygen.DevopsQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(_ bulkQuerygen.DatabaseConfig, queriesFullRange bulkQuerygen.TimeInterval, _ time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.DevopsSingleQuery{
+			Devops:   NewElasticSearchDevops(queriesFullRange, scaleVar).(*ElasticSearchDevops),
+			NewQuery: func() bulkQuerygen.Query { return bulkQuerygen.NewHTTPQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+func (d *ElasticSearchDevops) fillSearchQuery(qi bulkQuerygen.Query, humanLabel, index, start string, t *template.Template, params DevopsQueryParams) {
+	if d.ScaleVar > 10000 {
+		panic("scaleVar > 10000 implies size > 10000, which is not supported on elasticsearch. see https://www.elastic.co/guide/en/elasticsearch/reference/current/search-request-from-size.html")
+	}
+	params.HostnameCount = d.ScaleVar
+
+	body := new(bytes.Buffer)
+	mustExecuteTemplate(t, body, params)
+
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, start))
+	q.Method = []byte("POST")
+
+	q.Path = []byte(fmt.Sprintf("/%s/_search", index))
+	q.Body = body.Bytes()
+}
+
+func jsonEncodedHostnames(hostnames []string) string {
+	hostnameClauses := []string{}
+	for _, s := range hostnames {
+		hostnameClauses = append(hostnameClauses, fmt.Sprintf("\"%s\"", s))
+	}
+	return fmt.Sprintf("[ %s ]", strings.Join(hostnameClauses, ", "))
+}
+
+// LastPointPerHost populates a Query with the latest cpu document of every host.
+func (d *ElasticSearchDevops) LastPointPerHost(qi bulkQuerygen.Query) {
+	humanLabel := "Elastic last cpu point, all hosts"
+	d.fillSearchQuery(qi, humanLabel, "cpu", d.AllInterval.StartString(), lastPointQuery, DevopsQueryParams{})
+}
+
+func (d *ElasticSearchDevops) HighCPUOneHost(q bulkQuerygen.Query) {
+	d.highCPU(q, d.RandomHostnames(1))
+}
+
+func (d *ElasticSearchDevops) HighCPUAllHosts(q bulkQuerygen.Query) {
+	d.highCPU(q, nil)
+}
+
+// highCPU populates a Query with the cpu documents having usage_user over
+// the threshold.
+func (d *ElasticSearchDevops) highCPU(qi bulkQuerygen.Query, hostnames []string) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+
+	params := DevopsQueryParams{
+		Start:     interval.StartString(),
+		End:       interval.EndString(),
+		Threshold: bulkQuerygen.HighCPUThreshold,
+	}
+	hosts := "all hosts"
+	if len(hostnames) > 0 {
+		params.JSONEncodedHostnames = jsonEncodedHostnames(hostnames)
+		hosts = fmt.Sprintf("rand %4d hosts", len(hostnames))
+	}
+
+	humanLabel := fmt.Sprintf("Elastic high cpu, %s, rand 12h", hosts)
+	d.fillSearchQuery(qi, humanLabel, "cpu", interval.StartString(), highCPUQuery, params)
+}
+
+func (d *ElasticSearchDevops) CPUMaxAllOneHost(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 1)
+}
+
+func (d *ElasticSearchDevops) CPUMaxAllEightHosts(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 8)
+}
+
+// cpuMaxAll populates a Query with the hourly maxima of all cpu fields of
+// random hosts.
+func (d *ElasticSearchDevops) cpuMaxAll(qi bulkQuerygen.Query, nhosts int) {
+	interval := d.AllInterval.RandWindow(8 * time.Hour)
+
+	humanLabel := fmt.Sprintf("Elastic max of all cpu fields, rand %4d hosts, rand 8h by 1h", nhosts)
+	d.fillSearchQuery(qi, humanLabel, "cpu", interval.StartString(), cpuMaxAllQuery, DevopsQueryParams{
+		JSONEncodedHostnames: jsonEncodedHostnames(d.RandomHostnames(nhosts)),
+		Start:                interval.StartString(),
+		End:                  interval.EndString(),
+		Bucket:               "1h",
+		Fields:               bulkQuerygen.CPUMetrics,
+	})
+}
+
+func (d *ElasticSearchDevops) DoubleGroupByOneMetric(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 1)
+}
+
+func (d *ElasticSearchDevops) DoubleGroupByFiveMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 5)
+}
+
+func (d *ElasticSearchDevops) DoubleGroupByAllMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, len(bulkQuerygen.CPUMetrics))
+}
+
+// doubleGroupBy populates a Query with the hourly means of cpu fields of all
+// hosts, grouped by hostname and hour.
+func (d *ElasticSearchDevops) doubleGroupBy(qi bulkQuerygen.Query, nmetrics int) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+
+	humanLabel := fmt.Sprintf("Elastic mean of %d cpu fields, all hosts, rand 12h by 1h", nmetrics)
+	d.fillSearchQuery(qi, humanLabel, "cpu", interval.StartString(), doubleGroupByQuery, DevopsQueryParams{
+		Start:  interval.StartString(),
+		End:    interval.EndString(),
+		Bucket: "1h",
+		Fields: bulkQuerygen.CPUMetrics[:nmetrics],
+	})
+}
+
+// TopFiveHostsByMemory populates a Query with the five hosts of the highest
+// maximum memory used_percent.
+func (d *ElasticSearchDevops) TopFiveHostsByMemory(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	humanLabel := "Elastic top 5 hosts by max memory used, rand 1h"
+	d.fillSearchQuery(qi, humanLabel, "mem", interval.StartString(), topHostsByMemoryQuery, DevopsQueryParams{
+		Start: interval.StartString(),
+		End:   interval.EndString(),
+	})
+}
+
 func mustExecuteTemplate(t *template.Template, w io.Writer, params interface{}) {
 	err := t.Execute(w, params)
 	if err != nil {
@@ -155,6 +295,14 @@ type HostsQueryParams struct {
 	Bucket, Start, End, Field string
 }
 
+type DevopsQueryParams struct {
+	JSONEncodedHostnames string
+	Bucket, Start, End   string
+	Fields               []string
+	Threshold            float64
+	HostnameCount        int
+}
+
 const rawFleetQuery = `
 {
   "size" : 0,
@@ -276,3 +424,172 @@ const rawHostsQuery = `
   }
 }
 `
+
+const rawLastPointQuery = `
+{
+  "size": 0,
+  "aggs": {
+    "by_hostname": {
+      "terms": {
+        "size": {{.HostnameCount}},
+        "field": "hostname"
+      },
+      "aggs": {
+        "last_point": {
+          "top_hits": {
+            "size": 1,
+            "sort": [ { "timestamp": { "order": "desc" } } ]
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+const rawHighCPUQuery = `
+{
+  "size": 10000,
+  "query": {
+    "bool": {
+      "filter": [
+        {
+          "range": {
+            "timestamp": {
+              "gte": "{{.Start}}",
+              "lt": "{{.End}}"
+            }
+          }
+        },
+        {
+          "range": {
+            "usage_user": {
+              "gt": {{.Threshold}}
+            }
+          }
+        }{{if .JSONEncodedHostnames}},
+        {
+          "terms": {
+            "hostname": {{.JSONEncodedHostnames}}
+          }
+        }{{end}}
+      ]
+    }
+  }
+}
+`
+
+const rawCPUMaxAllQuery = `
+{
+  "size": 0,
+  "aggs": {
+    "result": {
+      "filter": {
+        "bool": {
+          "filter": [
+            {
+              "range": {
+                "timestamp": {
+                  "gte": "{{.Start}}",
+                  "lt": "{{.End}}"
+                }
+              }
+            },
+            {
+              "terms": {
+                "hostname": {{.JSONEncodedHostnames}}
+              }
+            }
+          ]
+        }
+      },
+      "aggs": {
+        "result2": {
+          "date_histogram": {
+            "field": "timestamp",
+            "interval": "{{.Bucket}}",
+            "format": "yyyy-MM-dd-HH"
+          },
+          "aggs": {
+            {{range $i, $f := .Fields}}{{if $i}},
+            {{end}}"max_of_{{$f}}": { "max": { "field": "{{$f}}" } }{{end}}
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+const rawDoubleGroupByQuery = `
+{
+  "size": 0,
+  "aggs": {
+    "result": {
+      "filter": {
+        "range": {
+          "timestamp": {
+            "gte": "{{.Start}}",
+            "lt": "{{.End}}"
+          }
+        }
+      },
+      "aggs": {
+        "by_hostname": {
+          "terms": {
+            "size": {{.HostnameCount}},
+            "field": "hostname"
+          },
+          "aggs": {
+            "result2": {
+              "date_histogram": {
+                "field": "timestamp",
+                "interval": "{{.Bucket}}",
+                "format": "yyyy-MM-dd-HH"
+              },
+              "aggs": {
+                {{range $i, $f := .Fields}}{{if $i}},
+                {{end}}"avg_of_{{$f}}": { "avg": { "field": "{{$f}}" } }{{end}}
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+const rawTopHostsByMemoryQuery = `
+{
+  "size": 0,
+  "aggs": {
+    "result": {
+      "filter": {
+        "range": {
+          "timestamp": {
+            "gte": "{{.Start}}",
+            "lt": "{{.End}}"
+          }
+        }
+      },
+      "aggs": {
+        "by_hostname": {
+          "terms": {
+            "size": 5,
+            "field": "hostname",
+            "order": { "max_used_percent": "desc" }
+          },
+          "aggs": {
+            "max_used_percent": {
+              "max": {
+                "field": "used_percent"
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
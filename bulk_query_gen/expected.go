@@ -0,0 +1,136 @@
+package bulk_query_gen
+
+import (
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/devops"
+	"time"
+)
+
+// ExpectedResult holds the expected answer of a query returning a single
+// series of time buckets: the start of every non-empty bucket, in ascending
+// order, and its aggregated value.
+type ExpectedResult struct {
+	Times  []int64
+	Values []float64
+}
+
+// Expected is the replayed dataset the generators compute expected results
+// from. It is nil unless results verification is enabled.
+var Expected *DevopsReplay
+
+// DevopsReplay holds the cpu usage_user values of a devops dataset, replayed
+// in memory from the parameters recorded in its manifest.
+type DevopsReplay struct {
+	samples map[string][]devopsSample // by hostname, in time order
+}
+
+type devopsSample struct {
+	timestamp int64
+	value     float64
+}
+
+// ReplayDevops replays the devops simulation described by the manifest
+// written by bulk_data_gen. The whole dataset is replayed, so all its
+// interleaved groups are expected to be loaded. It reseeds the PRNG of the
+// simulators.
+func ReplayDevops(m *common.Manifest) (*DevopsReplay, error) {
+	if m.UseCase != common.UseCaseDevOps {
+		return nil, fmt.Errorf("cannot replay use case '%s', only '%s' is supported", m.UseCase, common.UseCaseDevOps)
+	}
+	if m.ConfigFile != "" {
+		return nil, fmt.Errorf("cannot replay a dataset generated with config file %s", m.ConfigFile)
+	}
+	start, err := time.Parse(time.RFC3339, m.TimestampStart)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.RFC3339, m.TimestampEnd)
+	if err != nil {
+		return nil, err
+	}
+	samplingInterval, err := time.ParseDuration(m.SamplingInterval)
+	if err != nil {
+		return nil, err
+	}
+	samplingJitter, err := time.ParseDuration(m.SamplingJitter)
+	if err != nil {
+		return nil, err
+	}
+	precision, err := common.ParseTimestampPrecision(m.TimestampPrecision)
+	if err != nil {
+		return nil, err
+	}
+
+	// seed as bulk_data_gen does:
+	common.Seed(m.Seed)
+	devops.EpochDuration = samplingInterval
+	cfg := &devops.DevopsSimulatorConfig{
+		Start: start.UTC(),
+		End:   end.UTC(),
+
+		HostCount:  m.ScaleVar,
+		HostOffset: m.ScaleVarOffset,
+	}
+	sim := cfg.ToSimulator()
+	timestamps := common.NewTimestampAdjuster(precision, samplingJitter, m.Seed)
+
+	r := &DevopsReplay{samples: make(map[string][]devopsSample, m.ScaleVar)}
+	point := common.MakeUsablePoint()
+	for !sim.Finished() {
+		sim.Next(point)
+		timestamps.Adjust(point)
+		if string(point.MeasurementName) == "cpu" {
+			r.addCPUPoint(point)
+		}
+		point.Reset()
+	}
+	return r, nil
+}
+
+func (r *DevopsReplay) addCPUPoint(p *common.Point) {
+	var hostname string
+	for i, k := range p.TagKeys {
+		if string(k) == "hostname" {
+			hostname = string(p.TagValues[i])
+			break
+		}
+	}
+	for i, k := range p.FieldKeys {
+		if string(k) == "usage_user" {
+			r.samples[hostname] = append(r.samples[hostname], devopsSample{p.Timestamp.UnixNano(), p.FieldValues[i].(float64)})
+			break
+		}
+	}
+}
+
+// MaxCPUUsage returns the maximum usage_user of the hosts within interval,
+// by groupBy buckets aligned on the epoch, as InfluxDB groups by time. The
+// interval bounds are truncated to seconds, as in the RFC3339 formatted
+// query.
+func (r *DevopsReplay) MaxCPUUsage(hostnames []string, interval TimeInterval, groupBy time.Duration) *ExpectedResult {
+	start := interval.Start.Truncate(time.Second).UnixNano()
+	end := interval.End.Truncate(time.Second).UnixNano()
+	step := groupBy.Nanoseconds()
+	max := make(map[int64]float64)
+	for _, hostname := range hostnames {
+		for _, s := range r.samples[hostname] {
+			if s.timestamp < start || s.timestamp >= end {
+				continue
+			}
+			bucket := s.timestamp - s.timestamp%step
+			if v, ok := max[bucket]; !ok || s.value > v {
+				max[bucket] = s.value
+			}
+		}
+	}
+
+	result := &ExpectedResult{}
+	for bucket := start - start%step; bucket < end; bucket += step {
+		if v, ok := max[bucket]; ok {
+			result.Times = append(result.Times, bucket)
+			result.Values = append(result.Values, v)
+		}
+	}
+	return result
+}
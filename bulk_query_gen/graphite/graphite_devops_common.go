@@ -3,7 +3,6 @@ package graphite
 import (
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -11,6 +10,7 @@ import (
 // GraphiteDevops produces Influx-specific queries for all the devops query types.
 type GraphiteDevops struct {
 	GraphiteCommon
+	bulkQuerygen.DevopsNotImplemented
 }
 
 // NewGraphiteDevops makes an InfluxDevops object ready to generate Queries.
@@ -59,7 +59,7 @@ func (d *GraphiteDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Query)
 // SELECT max(usage_user) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1m)
 func (d *GraphiteDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	hostnamesNumbers := []string{}
 	for _, n := range nn {
@@ -0,0 +1,204 @@
+package bulk_query_gen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HostDistribution describes how queries pick hosts (or homes) out of the
+// scaleVar ones. Host i is the one named host_i.
+type HostDistribution interface {
+	// Pick returns a random host index in [0, scaleVar).
+	Pick(scaleVar int) int
+	// PickN returns n distinct random host indexes in [0, scaleVar).
+	PickN(scaleVar, n int) []int
+	String() string
+}
+
+// DefaultHostDistribution is the distribution used by the CommonParams made
+// by NewCommonParams.
+var DefaultHostDistribution HostDistribution = UniformHostDistribution{}
+
+// ParseHostDistribution parses a host distribution specification:
+//
+//	uniform                           every host is equally likely
+//	zipfian[:skew]                    host i is picked with probability proportional to 1/(i+1)^skew (default skew 1.0)
+//	hot-set[:hot-percent[:access-percent]]  access-percent of picks go to the first hot-percent of hosts (default 20:80)
+func ParseHostDistribution(spec string) (HostDistribution, error) {
+	parts := strings.Split(spec, ":")
+	params := make([]float64, len(parts)-1)
+	for i, p := range parts[1:] {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host distribution parameter '%s': %v", p, err)
+		}
+		params[i] = v
+	}
+
+	switch parts[0] {
+	case "uniform":
+		if len(params) > 0 {
+			return nil, fmt.Errorf("uniform host distribution takes no parameters")
+		}
+		return UniformHostDistribution{}, nil
+	case "zipfian":
+		d := &ZipfianHostDistribution{Skew: 1.0}
+		if len(params) > 1 {
+			return nil, fmt.Errorf("zipfian host distribution takes one parameter at most")
+		}
+		if len(params) == 1 {
+			d.Skew = params[0]
+		}
+		if d.Skew <= 0 {
+			return nil, fmt.Errorf("zipfian skew must be positive")
+		}
+		return d, nil
+	case "hot-set":
+		d := HotSetHostDistribution{HotPercent: 20, AccessPercent: 80}
+		if len(params) > 2 {
+			return nil, fmt.Errorf("hot-set host distribution takes two parameters at most")
+		}
+		if len(params) > 0 {
+			d.HotPercent = params[0]
+		}
+		if len(params) > 1 {
+			d.AccessPercent = params[1]
+		}
+		if d.HotPercent <= 0 || d.HotPercent > 100 || d.AccessPercent < 0 || d.AccessPercent > 100 {
+			return nil, fmt.Errorf("hot-set percentages must be within (0, 100]")
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("unknown host distribution '%s'", parts[0])
+}
+
+// hostDistributionLabel encloses the host distribution appended to the labels
+// of the queries.
+const hostDistributionLabel = " [hosts: "
+
+// LabelHostDistribution appends a host distribution other than the uniform
+// one to the label of a query, so that the benchmark results of queries of
+// different distributions can be told apart without the query manifest.
+func LabelHostDistribution(q Query, d HostDistribution) {
+	if _, ok := d.(UniformHostDistribution); ok {
+		return
+	}
+	q.SetHumanLabel(append(q.HumanLabelName(), hostDistributionLabel+d.String()+"]"...))
+}
+
+// HostDistributionOfLabel returns the host distribution appended to a query
+// label by LabelHostDistribution, or "uniform" if there is none.
+func HostDistributionOfLabel(label string) string {
+	i := strings.LastIndex(label, hostDistributionLabel)
+	if i < 0 || !strings.HasSuffix(label, "]") {
+		return UniformHostDistribution{}.String()
+	}
+	return label[i+len(hostDistributionLabel) : len(label)-1]
+}
+
+// UniformHostDistribution picks every host with the same probability.
+type UniformHostDistribution struct{}
+
+func (UniformHostDistribution) Pick(scaleVar int) int {
+	return rand.Intn(scaleVar)
+}
+
+func (UniformHostDistribution) PickN(scaleVar, n int) []int {
+	return rand.Perm(scaleVar)[:n]
+}
+
+func (UniformHostDistribution) String() string {
+	return "uniform"
+}
+
+// ZipfianHostDistribution picks host i with probability proportional to
+// 1/(i+1)^Skew, so host_0 is the most popular one.
+type ZipfianHostDistribution struct {
+	Skew float64
+
+	// cumulative probabilities of the hosts, computed for cdfScaleVar hosts
+	cdf         []float64
+	cdfScaleVar int
+}
+
+func (d *ZipfianHostDistribution) Pick(scaleVar int) int {
+	if d.cdfScaleVar != scaleVar {
+		d.cdf = make([]float64, scaleVar)
+		sum := 0.0
+		for i := range d.cdf {
+			sum += 1 / math.Pow(float64(i+1), d.Skew)
+			d.cdf[i] = sum
+		}
+		for i := range d.cdf {
+			d.cdf[i] /= sum
+		}
+		d.cdfScaleVar = scaleVar
+	}
+	i := sort.SearchFloat64s(d.cdf, rand.Float64())
+	if i >= scaleVar {
+		i = scaleVar - 1
+	}
+	return i
+}
+
+func (d *ZipfianHostDistribution) PickN(scaleVar, n int) []int {
+	return pickDistinct(scaleVar, n, d.Pick)
+}
+
+func (d *ZipfianHostDistribution) String() string {
+	return fmt.Sprintf("zipfian:%g", d.Skew)
+}
+
+// HotSetHostDistribution directs AccessPercent of the picks to the first
+// HotPercent of the hosts, and the rest to the other hosts, uniformly.
+type HotSetHostDistribution struct {
+	HotPercent, AccessPercent float64
+}
+
+func (d HotSetHostDistribution) Pick(scaleVar int) int {
+	hot := int(math.Ceil(float64(scaleVar) * d.HotPercent / 100))
+	if hot >= scaleVar || rand.Float64()*100 < d.AccessPercent {
+		return rand.Intn(hot)
+	}
+	return hot + rand.Intn(scaleVar-hot)
+}
+
+func (d HotSetHostDistribution) PickN(scaleVar, n int) []int {
+	return pickDistinct(scaleVar, n, d.Pick)
+}
+
+func (d HotSetHostDistribution) String() string {
+	return fmt.Sprintf("hot-set:%g:%g", d.HotPercent, d.AccessPercent)
+}
+
+// pickDistinct draws n distinct host indexes using pick. When the
+// distribution makes further distinct draws unlikely, the remaining hosts
+// are taken uniformly.
+func pickDistinct(scaleVar, n int, pick func(int) int) []int {
+	if n > scaleVar {
+		panic("logic error: more hosts requested than available")
+	}
+	picked := make([]int, 0, n)
+	seen := make(map[int]bool, n)
+	for tries := 0; len(picked) < n && tries < 100*n; tries++ {
+		i := pick(scaleVar)
+		if !seen[i] {
+			seen[i] = true
+			picked = append(picked, i)
+		}
+	}
+	for _, i := range rand.Perm(scaleVar) {
+		if len(picked) == n {
+			break
+		}
+		if !seen[i] {
+			seen[i] = true
+			picked = append(picked, i)
+		}
+	}
+	return picked
+}
@@ -0,0 +1,147 @@
+package bulk_query_gen
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+func testHostDistributions(t *testing.T) []HostDistribution {
+	var distributions []HostDistribution
+	for _, spec := range []string{"uniform", "zipfian", "zipfian:2.5", "hot-set", "hot-set:10:90", "hot-set:100:100"} {
+		d, err := ParseHostDistribution(spec)
+		require.NoError(t, err)
+		distributions = append(distributions, d)
+	}
+	return distributions
+}
+
+func TestParseHostDistribution(t *testing.T) {
+	cases := []struct {
+		spec     string
+		expected string
+		err      bool
+	}{
+		{spec: "uniform", expected: "uniform"},
+		{spec: "zipfian", expected: "zipfian:1"},
+		{spec: "zipfian:1.2", expected: "zipfian:1.2"},
+		{spec: "hot-set", expected: "hot-set:20:80"},
+		{spec: "hot-set:10", expected: "hot-set:10:80"},
+		{spec: "hot-set:10:90", expected: "hot-set:10:90"},
+		{spec: "uniform:1", err: true},
+		{spec: "zipfian:0", err: true},
+		{spec: "zipfian:1:2", err: true},
+		{spec: "hot-set:0", err: true},
+		{spec: "hot-set:10:101", err: true},
+		{spec: "hot-set:x", err: true},
+		{spec: "gaussian", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			d, err := ParseHostDistribution(c.spec)
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, d.String())
+		})
+	}
+}
+
+func TestHostDistributionRange(t *testing.T) {
+	for _, d := range testHostDistributions(t) {
+		t.Run(d.String(), func(t *testing.T) {
+			rand.Seed(1)
+			for _, scaleVar := range []int{1, 2, 7, 100} {
+				p := &CommonParams{ScaleVar: scaleVar, Hosts: d}
+				for i := 0; i < 1000; i++ {
+					n := p.RandomHostIndex()
+					require.True(t, n >= 0 && n < scaleVar, "host %d of %d", n, scaleVar)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomHostIndexes(t *testing.T) {
+	for _, d := range testHostDistributions(t) {
+		t.Run(d.String(), func(t *testing.T) {
+			rand.Seed(1)
+			for _, scaleVar := range []int{1, 8, 100} {
+				p := &CommonParams{ScaleVar: scaleVar, Hosts: d}
+				// down to every host, which the skewed distributions are
+				// unlikely to draw
+				for _, n := range []int{1, scaleVar / 2, scaleVar} {
+					for i := 0; i < 20; i++ {
+						indexes := p.RandomHostIndexes(n)
+						require.Len(t, indexes, n)
+						seen := make(map[int]bool, n)
+						for _, h := range indexes {
+							require.True(t, h >= 0 && h < scaleVar, "host %d of %d", h, scaleVar)
+							require.False(t, seen[h], "host %d picked twice", h)
+							seen[h] = true
+						}
+					}
+				}
+			}
+			p := &CommonParams{ScaleVar: 4, Hosts: d}
+			require.Panics(t, func() { p.RandomHostIndexes(5) })
+		})
+	}
+}
+
+func TestHostDistributionSkew(t *testing.T) {
+	const scaleVar, picks = 100, 100000
+	cases := []struct {
+		spec   string
+		hot    int     // hosts of the hot set, the first ones
+		access float64 // expected fraction of the picks of the hot set
+	}{
+		{spec: "uniform", hot: 20, access: 0.2},
+		{spec: "hot-set", hot: 20, access: 0.8},
+		{spec: "hot-set:10:90", hot: 10, access: 0.9},
+		{spec: "hot-set:10:0", hot: 10, access: 0},
+		// 1/(i+1) normalized by the 100th harmonic number
+		{spec: "zipfian", hot: 1, access: 1 / 5.187},
+		{spec: "zipfian", hot: 10, access: 2.929 / 5.187},
+	}
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			d, err := ParseHostDistribution(c.spec)
+			require.NoError(t, err)
+			rand.Seed(1)
+			hot := 0
+			for i := 0; i < picks; i++ {
+				if d.Pick(scaleVar) < c.hot {
+					hot++
+				}
+			}
+			require.InDelta(t, c.access, float64(hot)/picks, 0.01)
+		})
+	}
+}
+
+func TestLabelHostDistribution(t *testing.T) {
+	cases := []struct {
+		spec  string
+		label string
+	}{
+		{spec: "uniform", label: "InfluxDB max cpu, rand    8 hosts"},
+		{spec: "zipfian:1.2", label: "InfluxDB max cpu, rand    8 hosts [hosts: zipfian:1.2]"},
+		{spec: "hot-set", label: "InfluxDB max cpu, rand    8 hosts [hosts: hot-set:20:80]"},
+	}
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			d, err := ParseHostDistribution(c.spec)
+			require.NoError(t, err)
+			q := NewHTTPQuery()
+			defer q.Release()
+			q.HumanLabel = append(q.HumanLabel, "InfluxDB max cpu, rand    8 hosts"...)
+			LabelHostDistribution(q, d)
+			require.Equal(t, c.label, string(q.HumanLabelName()))
+			require.Equal(t, d.String(), HostDistributionOfLabel(c.label))
+		})
+	}
+	require.Equal(t, "uniform", HostDistributionOfLabel("all queries"))
+}
@@ -0,0 +1,214 @@
+package bulk_query_gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pelletier/go-toml"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// HTTPTemplate describes a user-defined HTTP query, as read from a template
+// file. Method, Path and Body are text/template templates executed with
+// HTTPTemplateParams.
+type HTTPTemplate struct {
+	Name     string `json:"name"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Body     string `json:"body"`
+	Hosts    int    `json:"hosts"`    // number of random hosts, -1 for all the hosts
+	Duration string `json:"duration"` // time window of the query, query-interval when empty
+	Interval string `json:"interval"` // group-by interval
+	Weight   int    `json:"weight"`   // weight of the query in the generated mix, 1 when unset
+}
+
+// HTTPTemplateParams are the placeholders available to HTTPTemplate templates.
+type HTTPTemplateParams struct {
+	Database                       string
+	Start, End                     string // RFC3339
+	StartUnixNano, EndUnixNano     int64
+	StartUnixMillis, EndUnixMillis int64
+	Hostnames                      []string
+	ClusterId                      string
+	Interval                       string
+}
+
+var httpTemplateFuncs = template.FuncMap{
+	// join concatenates the items using sep, e.g. {{join .Hostnames ","}}
+	"join": func(items []string, sep string) string {
+		return strings.Join(items, sep)
+	},
+	// each formats every item using format, e.g. {{each "hostname = '%s'" .Hostnames}}
+	"each": func(format string, items []string) []string {
+		formatted := make([]string, len(items))
+		for i, item := range items {
+			formatted[i] = fmt.Sprintf(format, item)
+		}
+		return formatted
+	},
+	// json encodes a value to JSON, e.g. {{json .Hostnames}}
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// LoadHTTPTemplates reads the [[queries]] of a TOML template file, e.g.:
+//
+//	[[queries]]
+//	name = "max cpu, 4 hosts, 1h by 1m"
+//	method = "GET"
+//	path = "/query?db={{.Database}}&q={{urlquery (printf \"SELECT max(usage_user) FROM cpu WHERE (%s) AND time >= '%s' AND time < '%s' GROUP BY time(%s)\" (join (each \"hostname = '%s'\" .Hostnames) \" or \") .Start .End .Interval)}}"
+//	hosts = 4
+//	duration = "1h"
+//	interval = "1m"
+func LoadHTTPTemplates(path string) ([]HTTPTemplate, error) {
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("query template loading failed: %v", err)
+	}
+	b, err := json.Marshal(tree.ToMap()["queries"])
+	if err != nil {
+		return nil, fmt.Errorf("query template marshall failed: %v", err)
+	}
+	var templates []HTTPTemplate
+	err = json.Unmarshal(b, &templates)
+	if err != nil {
+		return nil, fmt.Errorf("query template unmarshall failed: %v", err)
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no queries in query template file %s", path)
+	}
+	return templates, nil
+}
+
+// HTTPTemplateQuery is a QueryGenerator rendering a HTTPTemplate.
+type HTTPTemplateQuery struct {
+	DashboardCommon
+	DatabaseName string
+	Name         string
+	Hosts        int
+	Interval     string
+
+	method, path, body *template.Template
+}
+
+// NewHTTPTemplateQuery returns a maker of generators interleaving the
+// queries of the templates according to their weights. The templates are
+// checked against the dataset interval, the default query duration and
+// scaleVar the generators are to be made with. Templates only query random
+// windows of the dataset, the last and recent query interval types are
+// rejected.
+func NewHTTPTemplateQuery(templates []HTTPTemplate, interval TimeInterval, duration time.Duration, scaleVar int) (QueryGeneratorMaker, error) {
+	if QueryIntervalType != "" && QueryIntervalType != "window" {
+		return nil, fmt.Errorf("query templates do not support the '%s' query interval type", QueryIntervalType)
+	}
+	durations := make([]time.Duration, len(templates))
+	for i := range templates {
+		t := &templates[i]
+		if t.Name == "" {
+			t.Name = fmt.Sprintf("template query %d", i+1)
+		}
+		if t.Method == "" {
+			t.Method = "GET"
+		}
+		if t.Weight == 0 {
+			t.Weight = 1
+		}
+		if t.Weight < 0 {
+			return nil, fmt.Errorf("query template '%s': negative weight", t.Name)
+		}
+		if t.Hosts > scaleVar {
+			return nil, fmt.Errorf("query template '%s': %d hosts, more than scale-var %d", t.Name, t.Hosts, scaleVar)
+		}
+		durations[i] = duration
+		if t.Duration != "" {
+			d, err := time.ParseDuration(t.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("query template '%s': invalid duration: %v", t.Name, err)
+			}
+			durations[i] = d
+		}
+		if durations[i] <= 0 || durations[i] >= interval.Duration() {
+			return nil, fmt.Errorf("query template '%s': duration %v must be positive and shorter than the dataset interval %v", t.Name, durations[i], interval.Duration())
+		}
+		// fail early on template syntax errors:
+		if _, err := parseHTTPTemplate(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(dbConfig DatabaseConfig, interval TimeInterval, _ time.Duration, scaleVar int) QueryGenerator {
+		gens := make([]QueryGenerator, len(templates))
+		weights := make([]int, len(templates))
+		for i := range templates {
+			g, _ := parseHTTPTemplate(&templates[i])
+			g.DashboardCommon = *NewDashboardCommon(interval, durations[i], scaleVar)
+			g.DatabaseName = dbConfig[DatabaseName]
+			gens[i] = g
+			weights[i] = templates[i].Weight
+		}
+		return NewQueryMix(gens, weights)
+	}, nil
+}
+
+func parseHTTPTemplate(t *HTTPTemplate) (*HTTPTemplateQuery, error) {
+	g := &HTTPTemplateQuery{Name: t.Name, Hosts: t.Hosts, Interval: t.Interval}
+	var err error
+	for _, part := range []struct {
+		name string
+		text string
+		dest **template.Template
+	}{
+		{"method", t.Method, &g.method},
+		{"path", t.Path, &g.path},
+		{"body", t.Body, &g.body},
+	} {
+		*part.dest, err = template.New(part.name).Funcs(httpTemplateFuncs).Parse(part.text)
+		if err != nil {
+			return nil, fmt.Errorf("query template '%s': invalid %s: %v", t.Name, part.name, err)
+		}
+	}
+	return g, nil
+}
+
+// Dispatch fulfills the QueryGenerator interface.
+func (g *HTTPTemplateQuery) Dispatch(_ int) Query {
+	interval := g.NextInterval()
+	params := HTTPTemplateParams{
+		Database:        g.DatabaseName,
+		Start:           interval.StartString(),
+		End:             interval.EndString(),
+		StartUnixNano:   interval.StartUnixNano(),
+		EndUnixNano:     interval.EndUnixNano(),
+		StartUnixMillis: interval.StartUnixNano() / int64(time.Millisecond),
+		EndUnixMillis:   interval.EndUnixNano() / int64(time.Millisecond),
+		ClusterId:       g.RandomClusterId(),
+		Interval:        g.Interval,
+	}
+	if g.Hosts < 0 {
+		params.Hostnames = AllHostnames(g.ScaleVar)
+	} else if g.Hosts > 0 {
+		params.Hostnames = g.RandomHostnames(g.Hosts)
+	}
+
+	q := NewHTTPQuery()
+	q.HumanLabel = []byte(g.Name)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", g.Name, interval.StartString()))
+	q.Method = g.execute(g.method, &params)
+	q.Path = g.execute(g.path, &params)
+	q.Body = g.execute(g.body, &params)
+	q.StartTimestamp = interval.StartUnixNano()
+	q.EndTimestamp = interval.EndUnixNano()
+	return q
+}
+
+func (g *HTTPTemplateQuery) execute(t *template.Template, params *HTTPTemplateParams) []byte {
+	buf := new(bytes.Buffer)
+	if err := t.Execute(buf, params); err != nil {
+		panic(fmt.Sprintf("query template '%s': %s", g.Name, err))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,44 @@
+package bulk_query_gen
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPTemplateQuery(t *testing.T) {
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	interval := NewTimeInterval(start, start.Add(24*time.Hour))
+	cases := []struct {
+		name         string
+		template     HTTPTemplate
+		intervalType string
+		err          string
+	}{
+		{name: "default duration", template: HTTPTemplate{Path: "/query"}},
+		{name: "own duration", template: HTTPTemplate{Path: "/query", Duration: "12h"}},
+		{name: "duration too long", template: HTTPTemplate{Path: "/query", Duration: "24h"}, err: "shorter than the dataset interval"},
+		{name: "negative duration", template: HTTPTemplate{Path: "/query", Duration: "-1h"}, err: "must be positive"},
+		{name: "invalid duration", template: HTTPTemplate{Path: "/query", Duration: "1 hour"}, err: "invalid duration"},
+		{name: "too many hosts", template: HTTPTemplate{Path: "/query", Hosts: 11}, err: "more than scale-var"},
+		{name: "negative weight", template: HTTPTemplate{Path: "/query", Weight: -1}, err: "negative weight"},
+		{name: "invalid path", template: HTTPTemplate{Path: "/query?q={{.Start"}, err: "invalid path"},
+		{name: "window interval type", template: HTTPTemplate{Path: "/query"}, intervalType: "window"},
+		{name: "last interval type", template: HTTPTemplate{Path: "/query"}, intervalType: "last", err: "query interval type"},
+	}
+	defer func(intervalType string) { QueryIntervalType = intervalType }(QueryIntervalType)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			QueryIntervalType = c.intervalType
+			maker, err := NewHTTPTemplateQuery([]HTTPTemplate{c.template}, interval, time.Hour, 10)
+			if c.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+			g := maker(DatabaseConfig{DatabaseName: "benchmark_db"}, interval, time.Hour, 10)
+			require.NotNil(t, g.Dispatch(0))
+		})
+	}
+}
@@ -30,7 +30,18 @@ func (d *InfluxDashboardAvailability) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//SELECT (sum("service_up") / count("service_up"))*100 AS "up_time" FROM "watcher"."autogen"."ping" WHERE cluster_id = :Cluster_Id: and time > :dashboardTime: FILL(linear)
-	query = fmt.Sprintf("SELECT (sum(\"service_up\") / count(\"service_up\"))*100 AS \"up_time\" FROM status WHERE cluster_id = '%s' and %s FILL(linear)", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT (sum(\"service_up\") / count(\"service_up\"))*100 AS \"up_time\" FROM status WHERE cluster_id = '%s' and %s FILL(linear)", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "status" and r._field == "service_up" and r.cluster_id == "%s") `+
+			`|> group() `+
+			`|> mean() `+
+			`|> map(fn:(r) => ({_time: r._stop, _value: r._value * 100.0})) `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval), d.RandomClusterId())
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) Availability (Percent), rand cluster in %s", d.language.String(), interval.Duration())
 
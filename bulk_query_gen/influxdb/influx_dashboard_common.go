@@ -2,17 +2,14 @@ package influxdb
 
 import (
 	"fmt"
-	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/dashboard"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"time"
 )
 
 // InfluxDashboard produces Influx-specific queries for all the devops query types.
 type InfluxDashboard struct {
 	InfluxCommon
-	ClustersCount int
-	bulkQuerygen.TimeWindow
+	bulkQuerygen.DashboardCommon
 }
 
 // NewInfluxDashboard makes an InfluxDashboard object ready to generate Queries.
@@ -20,14 +17,9 @@ func newInfluxDashboard(lang Language, dbConfig bulkQuerygen.DatabaseConfig, int
 	if _, ok := dbConfig[bulkQuerygen.DatabaseName]; !ok {
 		panic("need influx database name")
 	}
-	clustersCount := scaleVar / dashboard.ClusterSize //ClusterSizes[len(dashboard.ClusterSizes)/2]
-	if clustersCount == 0 {
-		clustersCount = 1
-	}
 	return &InfluxDashboard{
-		InfluxCommon:  *newInfluxCommon(lang, dbConfig[bulkQuerygen.DatabaseName], interval, scaleVar),
-		ClustersCount: clustersCount,
-		TimeWindow:    bulkQuerygen.TimeWindow{interval.Start, duration},
+		InfluxCommon:    *newInfluxCommon(lang, dbConfig[bulkQuerygen.DatabaseName], interval, scaleVar),
+		DashboardCommon: *bulkQuerygen.NewDashboardCommon(interval, duration, scaleVar),
 	}
 }
 
@@ -40,12 +32,7 @@ func (d *InfluxDashboard) Dispatch(i int) bulkQuerygen.Query {
 
 func (d *InfluxDashboard) DispatchCommon(i int) (*bulkQuerygen.HTTPQuery, *bulkQuerygen.TimeInterval) {
 	q := bulkQuerygen.NewHTTPQuery() // from pool
-	var interval bulkQuerygen.TimeInterval
-	if bulkQuerygen.TimeWindowShift > 0 {
-		interval = d.TimeWindow.SlidingWindow(&d.AllInterval)
-	} else {
-		interval = d.AllInterval.RandWindow(d.Duration)
-	}
+	interval := d.NextInterval()
 	return q, &interval
 }
 
@@ -62,6 +49,17 @@ func (d *InfluxDashboard) GetTimeConstraint(interval *bulkQuerygen.TimeInterval)
 	return s
 }
 
-func (d *InfluxDashboard) GetRandomClusterId() string {
-	return fmt.Sprintf("%d", rand.Intn(d.ClustersCount-1)+1)
+// GetFluxTimeRange returns the range() arguments covering the same period as GetTimeConstraint.
+func (d *InfluxDashboard) GetFluxTimeRange(interval *bulkQuerygen.TimeInterval) string {
+	var s string
+	switch bulkQuerygen.QueryIntervalType {
+	case "window":
+		s = fmt.Sprintf("start:%s, stop:%s", interval.StartString(), interval.EndString())
+	case "last":
+		s = fmt.Sprintf("start:-%dh, stop:-%dh", int64(2*interval.Duration().Hours()), int64(interval.Duration().Hours()))
+	case "recent":
+		s = fmt.Sprintf("start:-%dh, stop:-%dh", int64(interval.Duration().Hours()+24), int64(24))
+	}
+	return s
 }
+
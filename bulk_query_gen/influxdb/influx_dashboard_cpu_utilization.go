@@ -30,7 +30,18 @@ func (d *InfluxDashboardCpuUtilization) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//c "telegraf"."default"."cpu" WHERE time > :dashboardTime: and cluster_id = :Cluster_Id: GROUP BY host, time(1m)
-	query = fmt.Sprintf("SELECT mean(\"usage_user\") FROM cpu WHERE cluster_id = '%s' and %s group by hostname,time(1m)", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT mean(\"usage_user\") FROM cpu WHERE cluster_id = '%s' and %s group by hostname,time(1m)", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "cpu" and r._field == "usage_user" and r.cluster_id == "%s") `+
+			`|> group(by:["hostname"]) `+
+			`|> window(every:1m) `+
+			`|> mean() `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval), d.RandomClusterId())
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) CPU Utilization (Percent), rand cluster, %s by host, 1m", d.language.String(), interval.Duration())
 
@@ -30,7 +30,22 @@ func (d *InfluxDashboardDiskAllocated) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//SELECT last("max") from (SELECT max("total")/1073741824 FROM "telegraf"."default"."disk" WHERE time > :dashboardTime: and cluster_id = :Cluster_Id: and host =~ /.data./ GROUP BY time(120s))
-	query = fmt.Sprintf("SELECT last(\"max\") from (SELECT max(\"total\")/1073741824 FROM disk WHERE cluster_id = '%s' and %s and hostname =~ /data/ group by time(120s))", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT last(\"max\") from (SELECT max(\"total\")/1073741824 FROM disk WHERE cluster_id = '%s' and %s and hostname =~ /data/ group by time(120s))", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "disk" and r._field == "total" and r.cluster_id == "%s" and r.hostname =~ /data/) `+
+			`|> group() `+
+			`|> window(every:120s) `+
+			`|> max() `+
+			`|> group() `+
+			`|> sort(cols:["_time"]) `+
+			`|> last() `+
+			`|> map(fn:(r) => ({_time: r._time, _value: float(v:r._value) / 1073741824.0})) `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval), d.RandomClusterId())
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) Disk Allocated (GB), rand cluster, %s by 120s", d.language.String(), interval.Duration())
 
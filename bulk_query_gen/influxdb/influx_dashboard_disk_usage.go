@@ -30,7 +30,18 @@ func (d *InfluxDashboardDiskUsage) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//SELECT last("used_percent") AS "mean_used_percent" FROM "telegraf"."default"."disk" WHERE time > :dashboardTime: and cluster_id = :Cluster_Id: and host =~ /.data./
-	query = fmt.Sprintf("SELECT last(\"used_percent\") AS \"mean_used_percent\" FROM disk WHERE cluster_id = '%s' and %s and hostname =~ /data/", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT last(\"used_percent\") AS \"mean_used_percent\" FROM disk WHERE cluster_id = '%s' and %s and hostname =~ /data/", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "disk" and r._field == "used_percent" and r.cluster_id == "%s" and r.hostname =~ /data/) `+
+			`|> group() `+
+			`|> sort(cols:["_time"]) `+
+			`|> last() `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval), d.RandomClusterId())
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) Disk Usage (GB), rand cluster, %s", d.language.String(), interval.Duration())
 
@@ -30,7 +30,18 @@ func (d *InfluxDashboardDiskUtilization) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//SELECT max("used_percent") FROM "telegraf"."default"."disk" WHERE "cluster_id" = :Cluster_Id: AND "path" = '/influxdb/conf' AND time > :dashboardTime: AND host =~ /.data./ GROUP BY time(1m), "host"
-	query = fmt.Sprintf("SELECT max(\"used_percent\") FROM disk WHERE cluster_id = '%s' and \"path\" = '/dev/sda1' and %s AND hostname =~ /data/ group by time(1m), \"hostname\"", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT max(\"used_percent\") FROM disk WHERE cluster_id = '%s' and \"path\" = '/dev/sda1' and %s AND hostname =~ /data/ group by time(1m), \"hostname\"", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "disk" and r._field == "used_percent" and r.cluster_id == "%s" and r.path == "/dev/sda1" and r.hostname =~ /data/) `+
+			`|> group(by:["hostname"]) `+
+			`|> window(every:1m) `+
+			`|> max() `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval), d.RandomClusterId())
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) Disk Utilization (Percent), rand cluster, %s by 1m", d.language.String(), interval.Duration())
 
@@ -30,7 +30,33 @@ func (d *InfluxDashboardHttpRequestDuration) Dispatch(i int) bulkQuerygen.Query
 
 	var query string
 	//SELECT non_negative_derivative(percentile("writeReqDurationNs", 99)) /  non_negative_derivative(max(writeReq)) FROM "telegraf"."default"."influxdb_httpd" WHERE "cluster_id" = :Cluster_Id: AND time > :dashboardTime: GROUP BY host, time(1m)
-	query = fmt.Sprintf("SELECT non_negative_derivative(percentile(\"uptime_in_seconds\", 99)) / non_negative_derivative(max(total_connections_received)) FROM redis WHERE cluster_id = '%s' and %s group by hostname, time(1m)", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT non_negative_derivative(percentile(\"uptime_in_seconds\", 99)) / non_negative_derivative(max(total_connections_received)) FROM redis WHERE cluster_id = '%s' and %s group by hostname, time(1m)", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		clusterId := d.RandomClusterId()
+		timeRange := d.GetFluxTimeRange(interval)
+		query = fmt.Sprintf(`duration = from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "redis" and r._field == "uptime_in_seconds" and r.cluster_id == "%s") `+
+			`|> group(by:["hostname"]) `+
+			`|> window(every:1m) `+
+			`|> percentile(percentile:0.99, method:"exact_selector") `+
+			`|> window(every:inf) `+
+			`|> derivative(unit:1m, nonNegative:true)`+"\n"+
+			`connections = from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "redis" and r._field == "total_connections_received" and r.cluster_id == "%s") `+
+			`|> group(by:["hostname"]) `+
+			`|> window(every:1m) `+
+			`|> max() `+
+			`|> window(every:inf) `+
+			`|> derivative(unit:1m, nonNegative:true)`+"\n"+
+			`join(tables:{duration:duration, connections:connections}, on:["_time", "hostname"]) `+
+			`|> map(fn:(r) => ({_time: r._time, hostname: r.hostname, _value: r._value_duration / r._value_connections})) `+
+			`|> yield()`,
+			d.DatabaseName, timeRange, clusterId,
+			d.DatabaseName, timeRange, clusterId)
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) HTTP Request Duration (99th %%), rand cluster, %s by host, 1m", d.language.String(), interval.Duration())
 
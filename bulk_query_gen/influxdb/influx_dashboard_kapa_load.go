@@ -30,7 +30,17 @@ func (d *InfluxDashboardKapaLoad) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//SELECT "load5", "load15", "load1" FROM "telegraf"."autogen"."system" WHERE time > :dashboardTime: AND "host"='kapacitor'
-	query = fmt.Sprintf("SELECT \"load5\", \"load15\", \"load1\" FROM system WHERE hostname='kapacitor_1' and %s", d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT \"load5\", \"load15\", \"load1\" FROM system WHERE hostname='kapacitor_1' and %s", d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "system" and (r._field == "load1" or r._field == "load5" or r._field == "load15") and r.hostname == "kapacitor_1") `+
+			`|> keep(columns:["_start", "_stop", "_time", "_field", "_value"]) `+
+			`|> pivot(rowKey:["_time"], colKey:["_field"], valueCol:"_value") `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval))
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) kapa load 1,5,15 in %s", d.language.String(), interval.Duration())
 
@@ -30,7 +30,22 @@ func (d *InfluxDashboardMemoryTotal) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//SELECT last("max") from (SELECT max("total")/1073741824 FROM "telegraf"."default"."mem" WHERE "cluster_id" = :Cluster_Id: AND time > :dashboardTime: and host =~ /.data./ GROUP BY time(1m), host)
-	query = fmt.Sprintf("SELECT last(\"max\") from (SELECT max(\"total\")/1073741824 FROM mem WHERE cluster_id = '%s' and %s and hostname =~ /data/  group by time(1m), hostname)", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT last(\"max\") from (SELECT max(\"total\")/1073741824 FROM mem WHERE cluster_id = '%s' and %s and hostname =~ /data/  group by time(1m), hostname)", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "mem" and r._field == "total" and r.cluster_id == "%s" and r.hostname =~ /data/) `+
+			`|> group(by:["hostname"]) `+
+			`|> window(every:1m) `+
+			`|> max() `+
+			`|> group() `+
+			`|> sort(cols:["_time"]) `+
+			`|> last() `+
+			`|> map(fn:(r) => ({_time: r._time, _value: float(v:r._value) / 1073741824.0})) `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval), d.RandomClusterId())
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) Memory (MB), rand cluster, %s by 1m", d.language.String(), interval.Duration())
 
@@ -30,7 +30,18 @@ func (d *InfluxDashboardMemoryUtilization) Dispatch(i int) bulkQuerygen.Query {
 
 	var query string
 	//SELECT mean("used_percent") FROM "telegraf"."default"."mem" WHERE "cluster_id" = :Cluster_Id: AND time > :dashboardTime: GROUP BY time(1m), "host"
-	query = fmt.Sprintf("SELECT mean(\"used_percent\") FROM mem WHERE cluster_id = '%s' and %s group by time(1m), hostname", d.GetRandomClusterId(), d.GetTimeConstraint(interval))
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT mean(\"used_percent\") FROM mem WHERE cluster_id = '%s' and %s group by time(1m), hostname", d.RandomClusterId(), d.GetTimeConstraint(interval))
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(%s) `+
+			`|> filter(fn:(r) => r._measurement == "mem" and r._field == "used_percent" and r.cluster_id == "%s") `+
+			`|> group(by:["hostname"]) `+
+			`|> window(every:1m) `+
+			`|> mean() `+
+			`|> yield()`,
+			d.DatabaseName, d.GetFluxTimeRange(interval), d.RandomClusterId())
+	}
 
 	humanLabel := fmt.Sprintf("InfluxDB (%s) Memory Utilization (Percent), rand cluster, %s by 1m", d.language.String(), interval.Duration())
 
@@ -3,7 +3,6 @@ package influxdb
 import (
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -64,7 +63,7 @@ func (d *InfluxDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Query) {
 // SELECT max(usage_user) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1m)
 func (d *InfluxDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	hostnames := []string{}
 	for _, n := range nn {
@@ -102,6 +101,9 @@ func (d *InfluxDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhos
 
 	q := qi.(*bulkQuerygen.HTTPQuery)
 	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+	if bulkQuerygen.Expected != nil && d.language == InfluxQL {
+		q.Expected = bulkQuerygen.Expected.MaxCPUUsage(hostnames, interval, time.Minute)
+	}
 }
 
 // MeanCPUUsageDayByHourAllHosts populates a Query with a query that looks like:
@@ -146,3 +148,216 @@ func (d *InfluxDevops) MeanCPUUsageDayByHourAllHostsGroupbyHost(qi bulkQuerygen.
 //	q.Path = []byte(fmt.Sprintf("/query?%s", v.Encode()))
 //	q.Body = nil
 //}
+
+// NewInfluxQLDevopsQuery returns a maker of generators producing InfluxQL
+// queries of the single type populated by fill.
+func NewInfluxQLDevopsQuery(fill bulkQuerygen.DevopsQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return newInfluxDevopsQuery(InfluxQL, fill)
+}
+
+// NewFluxDevopsQuery returns a maker of generators producing Flux queries of
+// the single type populated by fill.
+func NewFluxDevopsQuery(fill bulkQuerygen.DevopsQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return newInfluxDevopsQuery(Flux, fill)
+}
+
+func newInfluxDevopsQuery(lang Language, fill bulkQuerygen.DevopsQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.DevopsSingleQuery{
+			Devops:   newInfluxDevopsCommon(lang, dbConfig, interval, duration, scaleVar).(*InfluxDevops),
+			NewQuery: func() bulkQuerygen.Query { return bulkQuerygen.NewHTTPQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+// hostnamesClause returns condition matching any of the hostnames.
+func (d *InfluxDevops) hostnamesClause(hostnames []string) string {
+	hostnameClauses := []string{}
+	for _, s := range hostnames {
+		if d.language == InfluxQL {
+			hostnameClauses = append(hostnameClauses, fmt.Sprintf("hostname = '%s'", s))
+		} else {
+			hostnameClauses = append(hostnameClauses, fmt.Sprintf(`r.hostname == "%s"`, s))
+		}
+	}
+	return strings.Join(hostnameClauses, " or ")
+}
+
+// LastPointPerHost populates a Query with a query that looks like:
+// SELECT last(*) from cpu group by hostname
+func (d *InfluxDevops) LastPointPerHost(qi bulkQuerygen.Query) {
+	var query string
+	if d.language == InfluxQL {
+		query = "SELECT last(*) from cpu group by hostname"
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._measurement == "cpu") `+
+			`|> last() `+
+			`|> yield()`,
+			d.DatabaseName,
+			d.AllInterval.StartString(), d.AllInterval.EndString())
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) last cpu point, all hosts", d.language.String())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, d.AllInterval.StartString(), query, q)
+}
+
+func (d *InfluxDevops) HighCPUOneHost(q bulkQuerygen.Query) {
+	d.highCPU(q, d.RandomHostnames(1))
+}
+
+func (d *InfluxDevops) HighCPUAllHosts(q bulkQuerygen.Query) {
+	d.highCPU(q, nil)
+}
+
+// highCPU populates a Query with a query that looks like:
+// SELECT * from cpu where usage_user > 90.0 and (hostname = '$HOSTNAME') and time >= '$START' and time < '$END'
+func (d *InfluxDevops) highCPU(qi bulkQuerygen.Query, hostnames []string) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+
+	hosts := "all hosts"
+	var query string
+	if d.language == InfluxQL {
+		hostCondition := ""
+		if len(hostnames) > 0 {
+			hostCondition = fmt.Sprintf(" and (%s)", d.hostnamesClause(hostnames))
+			hosts = fmt.Sprintf("rand %4d hosts", len(hostnames))
+		}
+		query = fmt.Sprintf("SELECT * from cpu where usage_user > %.1f%s and time >= '%s' and time < '%s'", bulkQuerygen.HighCPUThreshold, hostCondition, interval.StartString(), interval.EndString())
+	} else {
+		hostCondition := ""
+		if len(hostnames) > 0 {
+			hostCondition = fmt.Sprintf(" and (%s)", d.hostnamesClause(hostnames))
+			hosts = fmt.Sprintf("rand %4d hosts", len(hostnames))
+		}
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._measurement == "cpu"%s) `+
+			`|> pivot(rowKey:["_time"], colKey:["_field"], valueCol:"_value") `+
+			`|> filter(fn:(r) => r.usage_user > %.1f) `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString(),
+			hostCondition, bulkQuerygen.HighCPUThreshold)
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) high cpu, %s, rand 12h", d.language.String(), hosts)
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
+
+func (d *InfluxDevops) CPUMaxAllOneHost(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 1)
+}
+
+func (d *InfluxDevops) CPUMaxAllEightHosts(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 8)
+}
+
+// cpuMaxAll populates a Query with a query that looks like:
+// SELECT max(usage_user),...,max(usage_guest_nice) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >= '$START' and time < '$END' group by time(1h)
+func (d *InfluxDevops) cpuMaxAll(qi bulkQuerygen.Query, nhosts int) {
+	interval := d.AllInterval.RandWindow(8 * time.Hour)
+	combinedHostnameClause := d.hostnamesClause(d.RandomHostnames(nhosts))
+
+	var query string
+	if d.language == InfluxQL {
+		selectClauses := make([]string, len(bulkQuerygen.CPUMetrics))
+		for i, m := range bulkQuerygen.CPUMetrics {
+			selectClauses[i] = fmt.Sprintf("max(%s)", m)
+		}
+		query = fmt.Sprintf("SELECT %s from cpu where (%s) and time >= '%s' and time < '%s' group by time(1h)", strings.Join(selectClauses, ","), combinedHostnameClause, interval.StartString(), interval.EndString())
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._measurement == "cpu" and (%s)) `+
+			`|> keep(columns:["_start", "_stop", "_time", "_field", "_value"]) `+
+			`|> window(every:1h) `+
+			`|> max() `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString(),
+			combinedHostnameClause)
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) max of all cpu fields, rand %4d hosts, rand 8h by 1h", d.language.String(), nhosts)
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
+
+func (d *InfluxDevops) DoubleGroupByOneMetric(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 1)
+}
+
+func (d *InfluxDevops) DoubleGroupByFiveMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 5)
+}
+
+func (d *InfluxDevops) DoubleGroupByAllMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, len(bulkQuerygen.CPUMetrics))
+}
+
+// doubleGroupBy populates a Query with a query that looks like:
+// SELECT mean(usage_user),...,mean($METRIC_N) from cpu where time >= '$START' and time < '$END' group by time(1h),hostname
+func (d *InfluxDevops) doubleGroupBy(qi bulkQuerygen.Query, nmetrics int) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	metrics := bulkQuerygen.CPUMetrics[:nmetrics]
+
+	var query string
+	if d.language == InfluxQL {
+		selectClauses := make([]string, len(metrics))
+		for i, m := range metrics {
+			selectClauses[i] = fmt.Sprintf("mean(%s)", m)
+		}
+		query = fmt.Sprintf("SELECT %s from cpu where time >= '%s' and time < '%s' group by time(1h),hostname", strings.Join(selectClauses, ","), interval.StartString(), interval.EndString())
+	} else {
+		fieldClauses := make([]string, len(metrics))
+		for i, m := range metrics {
+			fieldClauses[i] = fmt.Sprintf(`r._field == "%s"`, m)
+		}
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._measurement == "cpu" and (%s)) `+
+			`|> keep(columns:["_start", "_stop", "_time", "_field", "_value", "hostname"]) `+
+			`|> window(every:1h) `+
+			`|> mean() `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString(),
+			strings.Join(fieldClauses, " or "))
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) mean of %d cpu fields, all hosts, rand 12h by 1h", d.language.String(), nmetrics)
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
+
+// TopFiveHostsByMemory populates a Query with a query that looks like:
+// SELECT top(max_used_percent, hostname, 5) from (SELECT max(used_percent) as max_used_percent from mem where time >= '$START' and time < '$END' group by hostname)
+func (d *InfluxDevops) TopFiveHostsByMemory(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	var query string
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT top(max_used_percent, hostname, 5) from (SELECT max(used_percent) as max_used_percent from mem where time >= '%s' and time < '%s' group by hostname)", interval.StartString(), interval.EndString())
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._measurement == "mem" and r._field == "used_percent") `+
+			`|> group(by:["hostname"]) `+
+			`|> max() `+
+			`|> group() `+
+			`|> sort(cols:["_value"], desc:true) `+
+			`|> limit(n:5) `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString())
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) top 5 hosts by max memory used, rand 1h", d.language.String())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	bulkDataGenIot "github.com/influxdata/influxdb-comparisons/bulk_data_gen/iot"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -40,7 +39,7 @@ func (d *InfluxIot) AverageTemperatureDayByHourOneHome(q bulkQuerygen.Query) {
 // SELECT avg(temperature) from air_condition_room where (home_id = '$HHOME_ID_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1h)
 func (d *InfluxIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query, nHomes int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nHomes]
+	nn := d.RandomHostIndexes(nHomes)
 
 	homes := []string{}
 	for _, n := range nn {
@@ -78,3 +77,154 @@ func (d *InfluxIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query, nHo
 	q := qi.(*bulkQuerygen.HTTPQuery)
 	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
 }
+
+// NewInfluxQLIotQuery returns a maker of generators producing InfluxQL
+// queries of the single iot type populated by fill.
+func NewInfluxQLIotQuery(fill bulkQuerygen.IotQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return newInfluxIotQuery(InfluxQL, fill)
+}
+
+// NewFluxIotQuery returns a maker of generators producing Flux queries of
+// the single iot type populated by fill.
+func NewFluxIotQuery(fill bulkQuerygen.IotQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return newInfluxIotQuery(Flux, fill)
+}
+
+func newInfluxIotQuery(lang Language, fill bulkQuerygen.IotQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.IotSingleQuery{
+			Iot:      NewInfluxIotCommon(lang, dbConfig, interval, duration, scaleVar).(*InfluxIot),
+			NewQuery: func() bulkQuerygen.Query { return bulkQuerygen.NewHTTPQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+// OpenDoorsWindowsOneHome populates a Query with a query that looks like:
+// SELECT count(state) from door_state, window_state_room where home_id = '$HOME_ID' and state = 1 and time >= '$START' and time < '$END' group by door_id, room_id, window_id
+func (d *InfluxIot) OpenDoorsWindowsOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	var query string
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT count(state) from door_state, window_state_room where home_id = '%s' and state = 1 and time >= '%s' and time < '%s' group by door_id, room_id, window_id", home, interval.StartString(), interval.EndString())
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => (r._measurement == "door_state" or r._measurement == "window_state_room") and r._field == "state" and r.home_id == "%s" and r._value == 1.0) `+
+			`|> count() `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString(),
+			home)
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) open doors and windows, rand    1 homes, rand 12h", d.language.String())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
+
+// WaterLeakageAllHomes populates a Query with a query that looks like:
+// SELECT count(leakage) from water_leakage_room where leakage = 1 and time >= '$START' and time < '$END' group by home_id, room_id
+func (d *InfluxIot) WaterLeakageAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	var query string
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT count(leakage) from water_leakage_room where leakage = 1 and time >= '%s' and time < '%s' group by home_id, room_id", interval.StartString(), interval.EndString())
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._measurement == "water_leakage_room" and r._field == "leakage" and r._value == 1.0) `+
+			`|> group(by:["home_id", "room_id"]) `+
+			`|> count() `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString())
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) water leakage, all homes, rand 1h", d.language.String())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
+
+// RadiatorValveVsTemperatureOneHome populates a Query with a query that looks like:
+// SELECT mean(opening_level) from radiator_valve_room where home_id = '$HOME_ID' and time >= '$START' and time < '$END' group by time(1h), room_id;
+// SELECT mean(temperature) from air_condition_room where home_id = '$HOME_ID' and time >= '$START' and time < '$END' group by time(1h), room_id
+func (d *InfluxIot) RadiatorValveVsTemperatureOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	var query string
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT mean(opening_level) from radiator_valve_room where home_id = '%[1]s' and time >= '%[2]s' and time < '%[3]s' group by time(1h), room_id; "+
+			"SELECT mean(temperature) from air_condition_room where home_id = '%[1]s' and time >= '%[2]s' and time < '%[3]s' group by time(1h), room_id",
+			home, interval.StartString(), interval.EndString())
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => ((r._measurement == "radiator_valve_room" and r._field == "opening_level") or (r._measurement == "air_condition_room" and r._field == "temperature")) and r.home_id == "%s") `+
+			`|> group(by:["_measurement", "_field", "room_id"]) `+
+			`|> window(every:1h) `+
+			`|> mean() `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString(),
+			home)
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) radiator valve vs temperature, rand    1 homes, rand 12h by 1h", d.language.String())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
+
+// BatteryLowAllHomes populates a Query with a query that looks like:
+// SELECT min(battery_voltage) from air_condition_room, ... where battery_voltage < 2.0 and time >= '$START' and time < '$END' group by home_id, sensor_id
+func (d *InfluxIot) BatteryLowAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	var query string
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT min(battery_voltage) from %s where battery_voltage < %g and time >= '%s' and time < '%s' group by home_id, sensor_id", strings.Join(bulkQuerygen.IotBatteryMeasurements, ", "), bulkQuerygen.IotBatteryLowThreshold, interval.StartString(), interval.EndString())
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._field == "battery_voltage" and r._value < %g) `+
+			`|> group(by:["home_id", "sensor_id"]) `+
+			`|> min() `+
+			`|> yield()`,
+			d.DatabaseName,
+			interval.StartString(), interval.EndString(),
+			bulkQuerygen.IotBatteryLowThreshold)
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) battery low sensors, all homes, rand 1h", d.language.String())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, interval.StartString(), query, q)
+}
+
+// LastStatePerRoomOneHome populates a Query with a query that looks like:
+// SELECT last(*) from air_condition_room where home_id = '$HOME_ID' group by room_id
+func (d *InfluxIot) LastStatePerRoomOneHome(qi bulkQuerygen.Query) {
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	var query string
+	if d.language == InfluxQL {
+		query = fmt.Sprintf("SELECT last(*) from air_condition_room where home_id = '%s' group by room_id", home)
+	} else {
+		query = fmt.Sprintf(`from(db:"%s") `+
+			`|> range(start:%s, stop:%s) `+
+			`|> filter(fn:(r) => r._measurement == "air_condition_room" and r.home_id == "%s") `+
+			`|> group(by:["room_id", "_field"]) `+
+			`|> last() `+
+			`|> yield()`,
+			d.DatabaseName,
+			d.AllInterval.StartString(), d.AllInterval.EndString(),
+			home)
+	}
+
+	humanLabel := fmt.Sprintf("InfluxDB (%s) last state per room, rand    1 homes", d.language.String())
+	q := qi.(*bulkQuerygen.HTTPQuery)
+	d.getHttpQuery(humanLabel, d.AllInterval.StartString(), query, q)
+}
@@ -1,47 +1,96 @@
 package bulk_query_gen
 
+import (
+	bulkDataGenIot "github.com/influxdata/influxdb-comparisons/bulk_data_gen/iot"
+)
+
+// IotBatteryLowThreshold is the battery_voltage below which a sensor battery
+// is considered low: the lowest quarter of the generated voltage range.
+const IotBatteryLowThreshold = bulkDataGenIot.BatteryVoltageMin + (bulkDataGenIot.BatteryVoltageMax-bulkDataGenIot.BatteryVoltageMin)/4
+
+// IotBatteryMeasurements are the iot measurements reporting battery_voltage.
+var IotBatteryMeasurements = []string{
+	"air_condition_room",
+	"air_condition_outdoor",
+	"air_quality_room",
+	"camera_detection",
+	"door_state",
+	"light_level_room",
+	"radiator_valve_room",
+	"water_leakage_room",
+	"water_level",
+	"weather_outdoor",
+	"window_state_room",
+}
+
 // Devops describes a devops query generator.
 type Iot interface {
 	AverageTemperatureDayByHourOneHome(Query)
 
+	// OpenDoorsWindowsOneHome counts the open state readings of every door
+	// and window of a random home during random 12 hours.
+	OpenDoorsWindowsOneHome(Query)
+
+	// WaterLeakageAllHomes counts the leakage readings per home and room
+	// during random 1 hour.
+	WaterLeakageAllHomes(Query)
+
+	// RadiatorValveVsTemperatureOneHome selects mean radiator valve opening
+	// and mean indoor temperature per room of a random home during random
+	// 12 hours by 1 hour.
+	RadiatorValveVsTemperatureOneHome(Query)
+
+	// BatteryLowAllHomes selects the minimal battery_voltage of the sensors
+	// reporting less than IotBatteryLowThreshold during random 1 hour.
+	BatteryLowAllHomes(Query)
+
+	// LastStatePerRoomOneHome selects the last air condition of every room
+	// of a random home.
+	LastStatePerRoomOneHome(Query)
+
 	Dispatch(int) Query
 }
 
-// devopsDispatchAll round-robins through the different devops queries.
+// IotQueryFunc populates a Query using one of the Iot methods, e.g.
+// Iot.WaterLeakageAllHomes.
+type IotQueryFunc func(Iot, Query)
+
+// IotSingleQuery is a QueryGenerator producing iot queries of one type only.
+type IotSingleQuery struct {
+	Iot      Iot
+	NewQuery func() Query
+	Fill     IotQueryFunc
+}
+
+// Dispatch fulfills the QueryGenerator interface.
+func (g *IotSingleQuery) Dispatch(i int) Query {
+	q := g.NewQuery()
+	g.Fill(g.Iot, q)
+	return q
+}
+
+// IotDispatchAll round-robins through the different iot queries. Unlike the
+// devops queries of 2 to 32 hosts, which the scale var limits, every iot query
+// reads either one home or all of them, so all are possible as soon as there
+// is a home.
 func IotDispatchAll(d Iot, iteration int, q Query, scaleVar int) {
 	if scaleVar <= 0 {
 		panic("logic error: bad scalevar")
 	}
-	mod := 1
-	if scaleVar >= 2 {
-		mod++
-	}
-	if scaleVar >= 4 {
-		mod++
-	}
-	if scaleVar >= 8 {
-		mod++
-	}
-	if scaleVar >= 16 {
-		mod++
-	}
-	if scaleVar >= 32 {
-		mod++
-	}
 
-	switch iteration % mod {
+	switch iteration % 6 {
 	case 0:
 		d.AverageTemperatureDayByHourOneHome(q)
-	//case 1:
-	//	d.MaxCPUUsageHourByMinuteTwoHosts(q, scaleVar)
-	//case 2:
-	//	d.MaxCPUUsageHourByMinuteFourHosts(q, scaleVar)
-	//case 3:
-	//	d.MaxCPUUsageHourByMinuteEightHosts(q, scaleVar)
-	//case 4:
-	//	d.MaxCPUUsageHourByMinuteSixteenHosts(q, scaleVar)
-	//case 5:
-	//	d.MaxCPUUsageHourByMinuteThirtyTwoHosts(q, scaleVar)
+	case 1:
+		d.OpenDoorsWindowsOneHome(q)
+	case 2:
+		d.WaterLeakageAllHomes(q)
+	case 3:
+		d.RadiatorValveVsTemperatureOneHome(q)
+	case 4:
+		d.BatteryLowAllHomes(q)
+	case 5:
+		d.LastStatePerRoomOneHome(q)
 	default:
 		panic("logic error in switch statement")
 	}
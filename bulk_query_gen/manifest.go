@@ -0,0 +1,82 @@
+package bulk_query_gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+const QueryManifestVersion = 1
+
+// QueryManifest describes a generated query file: the parameters it was
+// generated with and the number of queries written. The query benchmarkers
+// read it to record the parameters in their report tags.
+type QueryManifest struct {
+	Version                      int    `json:"version"`
+	Seed                         int64  `json:"seed"`
+	UseCase                      string `json:"use_case"`
+	QueryType                    string `json:"query_type,omitempty"`
+	QueryMix                     string `json:"query_mix,omitempty"`
+	QueryTemplate                string `json:"query_template,omitempty"`
+	Format                       string `json:"format"`
+	Encoding                     string `json:"encoding"`
+	ScaleVar                     int    `json:"scale_var"`
+	HostDistribution             string `json:"host_distribution"`
+	TimestampStart               string `json:"timestamp_start"`
+	TimestampEnd                 string `json:"timestamp_end"`
+	QueryInterval                string `json:"query_interval"`
+	QueryIntervalType            string `json:"query_interval_type"`
+	InterleavedGenerationGroupID uint   `json:"interleaved_generation_group_id"`
+	InterleavedGenerationGroups  uint   `json:"interleaved_generation_groups"`
+	Queries                      int64  `json:"queries"`
+}
+
+// WriteFile writes the manifest as indented JSON.
+func (m *QueryManifest) WriteFile(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ReadQueryManifest reads a manifest written by bulk_query_gen.
+func ReadQueryManifest(path string) (*QueryManifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m := &QueryManifest{}
+	if err := json.NewDecoder(f).Decode(m); err != nil {
+		return nil, fmt.Errorf("cannot parse query manifest %s: %v", path, err)
+	}
+	if m.Version != QueryManifestVersion {
+		return nil, fmt.Errorf("unsupported query manifest version %d", m.Version)
+	}
+	return m, nil
+}
+
+// ReportTags returns the manifest parameters as report tags.
+func (m *QueryManifest) ReportTags() [][2]string {
+	tags := [][2]string{
+		{"queries_use_case", m.UseCase},
+		{"queries_format", m.Format},
+		{"queries_seed", fmt.Sprintf("%d", m.Seed)},
+		{"queries_scale_var", fmt.Sprintf("%d", m.ScaleVar)},
+		{"host_distribution", m.HostDistribution},
+	}
+	switch {
+	case m.QueryTemplate != "":
+		tags = append(tags, [2]string{"queries_template", m.QueryTemplate})
+	case m.QueryMix != "":
+		tags = append(tags, [2]string{"queries_mix", m.QueryMix})
+	default:
+		tags = append(tags, [2]string{"queries_type", m.QueryType})
+	}
+	if m.InterleavedGenerationGroups > 1 {
+		tags = append(tags, [2]string{"queries_group", fmt.Sprintf("%d/%d", m.InterleavedGenerationGroupID, m.InterleavedGenerationGroups)})
+	}
+	return tags
+}
@@ -3,7 +3,6 @@ package mongodb
 import (
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"time"
 )
 
@@ -68,7 +67,7 @@ func (d *MongoDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Query) {
 
 func (d *MongoDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	hostnames := []string{}
 	for _, n := range nn {
@@ -177,3 +176,215 @@ func (d *MongoDevops) MeanCPUUsageDayByHourAllHostsGroupbyHost(qi bulkQuerygen.Q
 	//	q.Path = []byte("/cpu/_search")
 	//	q.Body = body.Bytes()
 }
+
+// NewMongoDevopsQuery returns a maker of generators producing Mongo
+// aggregation pipelines of the single type populated by fill.
+func NewMongoDevopsQuery(fill bulkQuerygen.DevopsQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.DevopsSingleQuery{
+			Devops:   NewMongoDevops(dbConfig, interval, duration, scaleVar).(*MongoDevops),
+			NewQuery: func() bulkQuerygen.Query { return NewMongoQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+// tagMatch returns a condition on the tags array matching the points with
+// the tag key set to any of the values. $elemMatch is used as the equality
+// match of embedded documents depends on their key order.
+func tagMatch(key string, values []string) M {
+	if DocumentFormat == SimpleArraysFormat {
+		return M{"$elemMatch": M{key: M{"$in": values}}}
+	}
+	return M{"$elemMatch": M{"key": key, "val": M{"$in": values}}}
+}
+
+// arrayToObjectExpr returns an expression converting the tags or fields array
+// into a single document, e.g. {"hostname": "host_0", "region": ...}.
+func arrayToObjectExpr(path string) M {
+	if DocumentFormat == SimpleArraysFormat {
+		return M{"$reduce": M{"input": path, "initialValue": M{}, "in": M{"$mergeObjects": S{"$$value", "$$this"}}}}
+	}
+	return M{"$arrayToObject": M{"$map": M{"input": path, "as": "kv", "in": M{"k": "$$kv.key", "v": "$$kv.val"}}}}
+}
+
+// matchStage returns the $match stage selecting the measurement points in the
+// interval, restricted to the hostnames if any.
+func matchStage(measurement string, interval bulkQuerygen.TimeInterval, hostnames []string) M {
+	match := M{
+		"measurement": measurement,
+		"timestamp_ns": M{
+			"$gte": interval.StartUnixNano(),
+			"$lt":  interval.EndUnixNano(),
+		},
+	}
+	if len(hostnames) > 0 {
+		match["tags"] = tagMatch("hostname", hostnames)
+	}
+	return M{"$match": match}
+}
+
+// flattenStage returns the stage adding the tags and fields as documents "t"
+// and "f" and the start of the hourly time bucket as "time_bucket".
+func flattenStage() M {
+	bucketNano := time.Hour.Nanoseconds()
+	return M{
+		"$addFields": M{
+			"t": arrayToObjectExpr("$tags"),
+			"f": arrayToObjectExpr("$fields"),
+			"time_bucket": M{
+				"$subtract": S{
+					"$timestamp_ns",
+					M{"$mod": S{"$timestamp_ns", bucketNano}},
+				},
+			},
+		},
+	}
+}
+
+func fillPipelineQuery(qi bulkQuerygen.Query, databaseName, humanLabel, measurement string, interval bulkQuerygen.TimeInterval, groupBy time.Duration, pipelineQuery []M) {
+	q := qi.(*MongoQuery)
+	q.HumanLabel = []byte(humanLabel)
+	q.BsonDoc = pipelineQuery
+	q.DatabaseName = []byte(databaseName)
+	q.CollectionName = []byte("point_data")
+	q.MeasurementName = []byte(measurement)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s, %s, %s)", humanLabel, interval.StartString(), q.DatabaseName, q.CollectionName, q.MeasurementName))
+	q.TimeStart = interval.Start
+	q.TimeEnd = interval.End
+	q.GroupByDuration = groupBy
+}
+
+// LastPointPerHost populates a Query with the latest cpu point of every host.
+func (d *MongoDevops) LastPointPerHost(qi bulkQuerygen.Query) {
+	pipelineQuery := []M{
+		{"$match": M{"measurement": "cpu"}},
+		{"$sort": M{"timestamp_ns": -1}},
+		{"$addFields": M{"t": arrayToObjectExpr("$tags")}},
+		{
+			"$group": M{
+				"_id":          "$t.hostname",
+				"timestamp_ns": M{"$first": "$timestamp_ns"},
+				"fields":       M{"$first": "$fields"},
+			},
+		},
+	}
+
+	humanLabel := "Mongo last cpu point, all hosts"
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "cpu", d.AllInterval, 0, pipelineQuery)
+}
+
+func (d *MongoDevops) HighCPUOneHost(q bulkQuerygen.Query) {
+	d.highCPU(q, d.RandomHostnames(1))
+}
+
+func (d *MongoDevops) HighCPUAllHosts(q bulkQuerygen.Query) {
+	d.highCPU(q, nil)
+}
+
+// highCPU populates a Query with the cpu points with usage_user over the
+// threshold.
+func (d *MongoDevops) highCPU(qi bulkQuerygen.Query, hostnames []string) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+
+	pipelineQuery := []M{
+		matchStage("cpu", interval, hostnames),
+		{"$addFields": M{"f": arrayToObjectExpr("$fields")}},
+		{"$match": M{"f.usage_user": M{"$gt": bulkQuerygen.HighCPUThreshold}}},
+		{"$project": M{"_id": 0, "timestamp_ns": 1, "tags": 1, "f": 1}},
+	}
+
+	hosts := "all hosts"
+	if len(hostnames) > 0 {
+		hosts = fmt.Sprintf("rand %4d hosts", len(hostnames))
+	}
+	humanLabel := fmt.Sprintf("Mongo high cpu, %s, rand 12h", hosts)
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "cpu", interval, 0, pipelineQuery)
+}
+
+func (d *MongoDevops) CPUMaxAllOneHost(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 1)
+}
+
+func (d *MongoDevops) CPUMaxAllEightHosts(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 8)
+}
+
+// cpuMaxAll populates a Query with the hourly maxima of all cpu fields of
+// random hosts.
+func (d *MongoDevops) cpuMaxAll(qi bulkQuerygen.Query, nhosts int) {
+	interval := d.AllInterval.RandWindow(8 * time.Hour)
+
+	group := M{"_id": "$time_bucket"}
+	for _, m := range bulkQuerygen.CPUMetrics {
+		group["max_"+m] = M{"$max": "$f." + m}
+	}
+	pipelineQuery := []M{
+		matchStage("cpu", interval, d.RandomHostnames(nhosts)),
+		flattenStage(),
+		{"$group": group},
+		{"$sort": M{"_id": 1}},
+	}
+
+	humanLabel := fmt.Sprintf("Mongo max of all cpu fields, rand %4d hosts, rand 8h by 1h", nhosts)
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "cpu", interval, time.Hour, pipelineQuery)
+}
+
+func (d *MongoDevops) DoubleGroupByOneMetric(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 1)
+}
+
+func (d *MongoDevops) DoubleGroupByFiveMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 5)
+}
+
+func (d *MongoDevops) DoubleGroupByAllMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, len(bulkQuerygen.CPUMetrics))
+}
+
+// doubleGroupBy populates a Query with the hourly means of cpu fields of all
+// hosts, grouped by hour and hostname.
+func (d *MongoDevops) doubleGroupBy(qi bulkQuerygen.Query, nmetrics int) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+
+	group := M{"_id": M{"time_bucket": "$time_bucket", "hostname": "$t.hostname"}}
+	for _, m := range bulkQuerygen.CPUMetrics[:nmetrics] {
+		group["avg_"+m] = M{"$avg": "$f." + m}
+	}
+	pipelineQuery := []M{
+		matchStage("cpu", interval, nil),
+		flattenStage(),
+		{"$group": group},
+		{"$sort": M{"_id.time_bucket": 1}},
+	}
+
+	humanLabel := fmt.Sprintf("Mongo mean of %d cpu fields, all hosts, rand 12h by 1h", nmetrics)
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "cpu", interval, time.Hour, pipelineQuery)
+}
+
+// TopFiveHostsByMemory populates a Query with the five hosts of the highest
+// maximum memory used_percent.
+func (d *MongoDevops) TopFiveHostsByMemory(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	pipelineQuery := []M{
+		matchStage("mem", interval, nil),
+		{
+			"$addFields": M{
+				"t": arrayToObjectExpr("$tags"),
+				"f": arrayToObjectExpr("$fields"),
+			},
+		},
+		{
+			"$group": M{
+				"_id":              "$t.hostname",
+				"max_used_percent": M{"$max": "$f.used_percent"},
+			},
+		},
+		{"$sort": M{"max_used_percent": -1}},
+		{"$limit": 5},
+	}
+
+	humanLabel := "Mongo top 5 hosts by max memory used, rand 1h"
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "mem", interval, 0, pipelineQuery)
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	bulkDataGenIot "github.com/influxdata/influxdb-comparisons/bulk_data_gen/iot"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"time"
 )
 
@@ -37,7 +36,7 @@ func (d *MongoIot) AverageTemperatureDayByHourOneHome(q bulkQuerygen.Query) {
 
 func (d *MongoIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query, nHomes int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nHomes]
+	nn := d.RandomHostIndexes(nHomes)
 
 	homes := []string{}
 	for _, n := range nn {
@@ -119,3 +118,173 @@ func (d *MongoIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query, nHom
 	q.TimeEnd = interval.End
 	q.GroupByDuration = time.Hour
 }
+
+// NewMongoIotQuery returns a maker of generators producing Mongo aggregation
+// pipelines of the single iot type populated by fill.
+func NewMongoIotQuery(fill bulkQuerygen.IotQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.IotSingleQuery{
+			Iot:      NewMongoIot(dbConfig, interval, duration, scaleVar).(*MongoIot),
+			NewQuery: func() bulkQuerygen.Query { return NewMongoQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+// OpenDoorsWindowsOneHome populates a Query counting the open state readings
+// of every door and window of one home.
+func (d *MongoIot) OpenDoorsWindowsOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	pipelineQuery := []M{
+		{
+			"$match": M{
+				"measurement": M{"$in": []string{"door_state", "window_state_room"}},
+				"timestamp_ns": M{
+					"$gte": interval.StartUnixNano(),
+					"$lt":  interval.EndUnixNano(),
+				},
+				"tags": tagMatch("home_id", []string{home}),
+			},
+		},
+		{
+			"$addFields": M{
+				"t": arrayToObjectExpr("$tags"),
+				"f": arrayToObjectExpr("$fields"),
+			},
+		},
+		{"$match": M{"f.state": 1.0}},
+		{
+			"$group": M{
+				"_id":   M{"measurement": "$measurement", "door_id": "$t.door_id", "room_id": "$t.room_id", "window_id": "$t.window_id"},
+				"count": M{"$sum": 1},
+			},
+		},
+	}
+
+	humanLabel := "Mongo open doors and windows, rand    1 homes, rand 12h"
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "door_state,window_state_room", interval, 0, pipelineQuery)
+}
+
+// WaterLeakageAllHomes populates a Query counting the leakage readings per
+// home and room.
+func (d *MongoIot) WaterLeakageAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	pipelineQuery := []M{
+		{
+			"$match": M{
+				"measurement": "water_leakage_room",
+				"timestamp_ns": M{
+					"$gte": interval.StartUnixNano(),
+					"$lt":  interval.EndUnixNano(),
+				},
+			},
+		},
+		{
+			"$addFields": M{
+				"t": arrayToObjectExpr("$tags"),
+				"f": arrayToObjectExpr("$fields"),
+			},
+		},
+		{"$match": M{"f.leakage": 1.0}},
+		{
+			"$group": M{
+				"_id":   M{"home_id": "$t.home_id", "room_id": "$t.room_id"},
+				"count": M{"$sum": 1},
+			},
+		},
+	}
+
+	humanLabel := "Mongo water leakage, all homes, rand 1h"
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "water_leakage_room", interval, 0, pipelineQuery)
+}
+
+// RadiatorValveVsTemperatureOneHome populates a Query with the hourly means
+// of radiator valve opening and indoor temperature per room of one home.
+func (d *MongoIot) RadiatorValveVsTemperatureOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	pipelineQuery := []M{
+		{
+			"$match": M{
+				"measurement": M{"$in": []string{"radiator_valve_room", "air_condition_room"}},
+				"timestamp_ns": M{
+					"$gte": interval.StartUnixNano(),
+					"$lt":  interval.EndUnixNano(),
+				},
+				"tags": tagMatch("home_id", []string{home}),
+			},
+		},
+		flattenStage(),
+		{
+			"$group": M{
+				"_id":           M{"time_bucket": "$time_bucket", "room_id": "$t.room_id"},
+				"opening_level": M{"$avg": "$f.opening_level"},
+				"temperature":   M{"$avg": "$f.temperature"},
+			},
+		},
+		{"$sort": M{"_id.time_bucket": 1}},
+	}
+
+	humanLabel := "Mongo radiator valve vs temperature, rand    1 homes, rand 12h by 1h"
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "radiator_valve_room,air_condition_room", interval, time.Hour, pipelineQuery)
+}
+
+// BatteryLowAllHomes populates a Query with the minimal battery_voltage of
+// the sensors reporting a low battery.
+func (d *MongoIot) BatteryLowAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	pipelineQuery := []M{
+		{
+			"$match": M{
+				"measurement": M{"$in": bulkQuerygen.IotBatteryMeasurements},
+				"timestamp_ns": M{
+					"$gte": interval.StartUnixNano(),
+					"$lt":  interval.EndUnixNano(),
+				},
+			},
+		},
+		{
+			"$addFields": M{
+				"t": arrayToObjectExpr("$tags"),
+				"f": arrayToObjectExpr("$fields"),
+			},
+		},
+		{"$match": M{"f.battery_voltage": M{"$lt": bulkQuerygen.IotBatteryLowThreshold}}},
+		{
+			"$group": M{
+				"_id":             M{"home_id": "$t.home_id", "sensor_id": "$t.sensor_id"},
+				"battery_voltage": M{"$min": "$f.battery_voltage"},
+			},
+		},
+	}
+
+	humanLabel := "Mongo battery low sensors, all homes, rand 1h"
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "*", interval, 0, pipelineQuery)
+}
+
+// LastStatePerRoomOneHome populates a Query with the latest air condition of
+// every room of one home.
+func (d *MongoIot) LastStatePerRoomOneHome(qi bulkQuerygen.Query) {
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	pipelineQuery := []M{
+		{"$match": M{"measurement": "air_condition_room", "tags": tagMatch("home_id", []string{home})}},
+		{"$sort": M{"timestamp_ns": -1}},
+		{"$addFields": M{"t": arrayToObjectExpr("$tags")}},
+		{
+			"$group": M{
+				"_id":          "$t.room_id",
+				"timestamp_ns": M{"$first": "$timestamp_ns"},
+				"fields":       M{"$first": "$fields"},
+			},
+		},
+	}
+
+	humanLabel := "Mongo last state per room, rand    1 homes"
+	fillPipelineQuery(qi, d.DatabaseName, humanLabel, "air_condition_room", d.AllInterval, 0, pipelineQuery)
+}
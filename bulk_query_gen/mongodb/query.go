@@ -65,6 +65,9 @@ func (q *MongoQuery) String() string {
 func (q *MongoQuery) HumanLabelName() []byte {
 	return q.HumanLabel
 }
+func (q *MongoQuery) SetHumanLabel(label []byte) {
+	q.HumanLabel = label
+}
 func (q *MongoQuery) HumanDescriptionName() []byte {
 	return q.HumanDescription
 }
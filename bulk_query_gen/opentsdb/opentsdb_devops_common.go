@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"net/url"
 	"strings"
 	"text/template"
@@ -14,6 +13,7 @@ import (
 // OpenTSDBDevops produces OpenTSDB-specific queries for all the devops query types.
 type OpenTSDBDevops struct {
 	bulkQuerygen.CommonParams
+	bulkQuerygen.DevopsNotImplemented
 }
 
 // NewOpenTSDBDevops makes an OpenTSDBDevops object ready to generate Queries.
@@ -63,7 +63,7 @@ func (d *OpenTSDBDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Query)
 // SELECT max(usage_user) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1m)
 func (d *OpenTSDBDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	hostnames := []string{}
 	for _, n := range nn {
@@ -11,6 +11,7 @@ const DefaultQueryInterval = time.Hour
 type Query interface {
 	Release()
 	HumanLabelName() []byte
+	SetHumanLabel(label []byte)
 	HumanDescriptionName() []byte
 	fmt.Stringer
 }
@@ -39,6 +40,7 @@ type HTTPQuery struct {
 	Body             []byte
 	StartTimestamp   int64
 	EndTimestamp     int64
+	Expected         *ExpectedResult // nil unless results verification is enabled
 }
 
 func NewHTTPQuery() *HTTPQuery {
@@ -53,6 +55,9 @@ func (q *HTTPQuery) String() string {
 func (q *HTTPQuery) HumanLabelName() []byte {
 	return q.HumanLabel
 }
+func (q *HTTPQuery) SetHumanLabel(label []byte) {
+	q.HumanLabel = label
+}
 func (q *HTTPQuery) HumanDescriptionName() []byte {
 	return q.HumanDescription
 }
@@ -65,6 +70,7 @@ func (q *HTTPQuery) Release() {
 	q.Body = q.Body[:0]
 	q.StartTimestamp = 0
 	q.EndTimestamp = 0
+	q.Expected = nil
 
 	HTTPQueryPool.Put(q)
 }
@@ -0,0 +1,91 @@
+package bulk_query_gen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QueryMixEntry is a query type of a query mix along with its weight.
+type QueryMixEntry struct {
+	UseCase   string
+	QueryType string
+	Weight    int
+}
+
+// ParseQueryMix parses a query mix specification: a comma separated list of
+// [use-case/]query-type:weight items, e.g. "1-host-1-hr:50,groupby:10".
+// Items without a use case belong to defaultUseCase.
+func ParseQueryMix(spec, defaultUseCase string) ([]QueryMixEntry, error) {
+	var entries []QueryMixEntry
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		sep := strings.LastIndex(item, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf("query mix item '%s' has no weight", item)
+		}
+		weight, err := strconv.Atoi(item[sep+1:])
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("query mix item '%s' has invalid weight", item)
+		}
+		entry := QueryMixEntry{UseCase: defaultUseCase, QueryType: item[:sep], Weight: weight}
+		if slash := strings.Index(entry.QueryType, "/"); slash >= 0 {
+			entry.UseCase, entry.QueryType = entry.QueryType[:slash], entry.QueryType[slash+1:]
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("empty query mix '%s'", spec)
+	}
+	return entries, nil
+}
+
+// QueryMix is a QueryGenerator interleaving the queries of several generators
+// according to their weights. It uses smooth weighted round-robin, so every
+// window of total weight queries holds exactly weight queries of every
+// generator, spread as evenly as possible.
+type QueryMix struct {
+	gens    []QueryGenerator
+	weights []int
+	current []int
+	counts  []int
+	total   int
+}
+
+// NewQueryMix makes a QueryMix of gens, weighted by the respective weights.
+func NewQueryMix(gens []QueryGenerator, weights []int) *QueryMix {
+	if len(gens) == 0 || len(gens) != len(weights) {
+		panic("logic error: bad query mix")
+	}
+	m := &QueryMix{
+		gens:    gens,
+		weights: weights,
+		current: make([]int, len(gens)),
+		counts:  make([]int, len(gens)),
+	}
+	for _, w := range weights {
+		m.total += w
+	}
+	return m
+}
+
+// Dispatch fulfills the QueryGenerator interface. The chosen generator is
+// passed its own query counter, so that generators cycling through several
+// queries keep doing so within the mix.
+func (m *QueryMix) Dispatch(_ int) Query {
+	next := 0
+	for k, w := range m.weights {
+		m.current[k] += w
+		if m.current[k] > m.current[next] {
+			next = k
+		}
+	}
+	m.current[next] -= m.total
+
+	q := m.gens[next].Dispatch(m.counts[next])
+	m.counts[next]++
+	return q
+}
@@ -0,0 +1,104 @@
+package bulk_query_gen
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseQueryMix(t *testing.T) {
+	cases := []struct {
+		name     string
+		spec     string
+		expected []QueryMixEntry
+		err      bool
+	}{
+		{
+			name:     "single",
+			spec:     "1-host-1-hr:1",
+			expected: []QueryMixEntry{{UseCase: "devops", QueryType: "1-host-1-hr", Weight: 1}},
+		},
+		{
+			name: "weights and spaces",
+			spec: " 1-host-1-hr:50 , groupby:10,",
+			expected: []QueryMixEntry{
+				{UseCase: "devops", QueryType: "1-host-1-hr", Weight: 50},
+				{UseCase: "devops", QueryType: "groupby", Weight: 10},
+			},
+		},
+		{
+			name: "other use case",
+			spec: "lastpoint:3,iot/battery-low:5",
+			expected: []QueryMixEntry{
+				{UseCase: "devops", QueryType: "lastpoint", Weight: 3},
+				{UseCase: "iot", QueryType: "battery-low", Weight: 5},
+			},
+		},
+		{name: "missing weight", spec: "groupby", err: true},
+		{name: "zero weight", spec: "groupby:0", err: true},
+		{name: "negative weight", spec: "groupby:-1", err: true},
+		{name: "invalid weight", spec: "groupby:ten", err: true},
+		{name: "empty", spec: " , ", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries, err := ParseQueryMix(c.spec, "devops")
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, entries)
+		})
+	}
+}
+
+// labelGenerator generates queries labeled by its name and the query counter
+// passed to Dispatch.
+type labelGenerator string
+
+func (g labelGenerator) Dispatch(i int) Query {
+	q := NewHTTPQuery()
+	q.HumanLabel = []byte(fmt.Sprintf("%s%d", g, i))
+	return q
+}
+
+func TestQueryMix(t *testing.T) {
+	cases := []struct {
+		name     string
+		weights  []int
+		expected string
+	}{
+		{name: "single", weights: []int{1}, expected: "a0 a1"},
+		{name: "equal", weights: []int{1, 1}, expected: "a0 b0 a1 b1"},
+		{name: "smooth", weights: []int{5, 1, 1}, expected: "a0 a1 b0 a2 c0 a3 a4 a5 a6 b1 a7 c1 a8 a9"},
+		{name: "uneven", weights: []int{3, 2}, expected: "a0 b0 a1 b1 a2 a3 b2 a4 b3 a5"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gens := make([]QueryGenerator, len(c.weights))
+			total := 0
+			for i, w := range c.weights {
+				gens[i] = labelGenerator('a' + rune(i))
+				total += w
+			}
+			m := NewQueryMix(gens, c.weights)
+			var labels []string
+			for i := 0; i < 2*total; i++ {
+				labels = append(labels, string(m.Dispatch(i).HumanLabelName()))
+			}
+			require.Equal(t, c.expected, strings.Join(labels, " "))
+
+			// every window of total weight queries holds weight queries of
+			// every generator
+			for start := 0; start+total <= len(labels); start++ {
+				counts := make([]int, len(c.weights))
+				for _, l := range labels[start : start+total] {
+					counts[l[0]-'a']++
+				}
+				require.Equal(t, c.weights, counts)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package splunk
 import (
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -11,6 +10,7 @@ import (
 // SplunkDevops produces Influx-specific queries for all the devops query types.
 type SplunkDevops struct {
 	SplunkCommon
+	bulkQuerygen.DevopsNotImplemented
 	DatabaseName string
 }
 
@@ -61,7 +61,7 @@ func (d *SplunkDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Query) {
 // SELECT max(usage_user) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1m)
 func (d *SplunkDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	var hostnames []string
 	for _, n := range nn {
@@ -35,6 +35,9 @@ func (q *SQLQuery) String() string {
 func (q *SQLQuery) HumanLabelName() []byte {
 	return q.HumanLabel
 }
+func (q *SQLQuery) SetHumanLabel(label []byte) {
+	q.HumanLabel = label
+}
 func (q *SQLQuery) HumanDescriptionName() []byte {
 	return q.HumanDescription
 }
@@ -0,0 +1,187 @@
+package timescaledb
+
+import (
+	"fmt"
+	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
+	"time"
+)
+
+// nowNanos is the SQL expression of the current time in the bigint
+// nanosecond representation of the time column.
+const nowNanos = "(extract(epoch from now()) * 1000000000)::bigint"
+
+// TimescaleDashboard produces Timescale-specific queries for all the dashboard query types.
+type TimescaleDashboard struct {
+	bulkQuerygen.DashboardCommon
+	DatabaseName string
+}
+
+// newTimescaleDashboardCommon makes a TimescaleDashboard object ready to generate Queries.
+func newTimescaleDashboardCommon(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) *TimescaleDashboard {
+	if _, ok := dbConfig[bulkQuerygen.DatabaseName]; !ok {
+		panic("need timescale database name")
+	}
+
+	return &TimescaleDashboard{
+		DashboardCommon: *bulkQuerygen.NewDashboardCommon(interval, duration, scaleVar),
+		DatabaseName:    dbConfig[bulkQuerygen.DatabaseName],
+	}
+}
+
+// NewTimescaleDashboardQuery returns a maker of generators producing SQL
+// queries of the single dashboard panel populated by fill.
+func NewTimescaleDashboardQuery(fill bulkQuerygen.DashboardQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.DashboardSingleQuery{
+			Dashboard: newTimescaleDashboardCommon(dbConfig, interval, duration, scaleVar),
+			NewQuery:  func() bulkQuerygen.Query { return NewSQLQuery() },
+			Fill:      fill,
+		}
+	}
+}
+
+// NewTimescaleDashboardAll produces SQL queries of all the dashboard panels.
+var NewTimescaleDashboardAll = bulkQuerygen.NewDashboardAllQuery(NewTimescaleDashboardQuery)
+
+// timeConstraint returns the condition on the time column selecting the
+// interval, honoring the query interval type.
+func (d *TimescaleDashboard) timeConstraint(interval *bulkQuerygen.TimeInterval) string {
+	var s string
+	switch bulkQuerygen.QueryIntervalType {
+	case "window":
+		s = fmt.Sprintf("time >= %d and time < %d", interval.StartUnixNano(), interval.EndUnixNano())
+	case "last":
+		s = fmt.Sprintf("time >= %s - %d and time < %s - %d", nowNanos, 2*interval.Duration().Nanoseconds(), nowNanos, interval.Duration().Nanoseconds())
+	case "recent":
+		s = fmt.Sprintf("time >= %s - %d and time < %s - %d", nowNanos, (interval.Duration() + 24*time.Hour).Nanoseconds(), nowNanos, (24 * time.Hour).Nanoseconds())
+	}
+	return s
+}
+
+// fillDashboardQuery populates the query of a panel with sql, formatted
+// with the cluster condition (when clustered) and the time constraint.
+func (d *TimescaleDashboard) fillDashboardQuery(qi bulkQuerygen.Query, panel string, clustered bool, sql string) {
+	interval := d.NextInterval()
+	conditions := d.timeConstraint(&interval)
+	cluster := ""
+	if clustered {
+		conditions = fmt.Sprintf("cluster_id = '%s' and %s", d.RandomClusterId(), conditions)
+		cluster = ", rand cluster"
+	}
+
+	humanLabel := fmt.Sprintf("Timescale %s%s in %s", panel, cluster, interval.Duration())
+	q := qi.(*SQLQuery)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, interval.StartString()))
+	q.QuerySQL = []byte(fmt.Sprintf(sql, conditions))
+}
+
+// Availability populates a Query with a query that looks like:
+// select sum(service_up)::float8 / count(service_up) * 100 as up_time from status where cluster_id = '$CLUSTER' and $TIME
+func (d *TimescaleDashboard) Availability(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "availability (percent)", true, "select sum(service_up)::float8 / count(service_up) * 100 as up_time from status where %s")
+}
+
+// CpuNum selects the last of the per minute maximal numbers of cpus.
+func (d *TimescaleDashboard) CpuNum(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "cpu (number) by 1m", true, "select max_n_cpus from (select time_bucket(60000000000,time) as time1min,max(n_cpus) as max_n_cpus from system where %s group by time1min) t order by time1min desc limit 1")
+}
+
+// CpuUtilization selects mean usage_user per host by minute.
+func (d *TimescaleDashboard) CpuUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "cpu utilization (percent) by host, 1m", true, "select time_bucket(60000000000,time) as time1min,hostname,avg(usage_user) from cpu where %s group by time1min,hostname order by time1min")
+}
+
+// DiskAllocated selects the last of the per 2 minutes maximal disk sizes of
+// the data nodes in GB.
+func (d *TimescaleDashboard) DiskAllocated(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "disk allocated (GB) by 120s", true, "select max_total from (select time_bucket(120000000000,time) as time2min,max(total)/1073741824.0 as max_total from disk where %s and hostname like 'data%%' group by time2min) t order by time2min desc limit 1")
+}
+
+// DiskUsage selects the last used_percent of the data nodes disks.
+func (d *TimescaleDashboard) DiskUsage(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "disk usage (GB)", true, "select used_percent as mean_used_percent from disk where %s and hostname like 'data%%' order by time desc limit 1")
+}
+
+// DiskUtilization selects maximal used_percent of the data nodes system disk
+// per host by minute.
+func (d *TimescaleDashboard) DiskUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "disk utilization (percent) by 1m", true, "select time_bucket(60000000000,time) as time1min,hostname,max(used_percent) from disk where %s and path = '/dev/sda1' and hostname like 'data%%' group by time1min,hostname order by time1min")
+}
+
+// HttpRequestDuration divides the non negative difference of the per minute
+// 99th percentile of uptime_in_seconds by the non negative difference of the
+// per minute maximal total_connections_received, per host.
+func (d *TimescaleDashboard) HttpRequestDuration(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "http request duration (99th %) by host, 1m", true, "select time1min,hostname,greatest(p99 - lag(p99) over w, 0) / nullif(greatest(connections - lag(connections) over w, 0), 0) from "+
+		"(select time_bucket(60000000000,time) as time1min,hostname,percentile_cont(0.99) within group (order by uptime_in_seconds) as p99,max(total_connections_received) as connections from redis where %s group by time1min,hostname) t "+
+		"window w as (partition by hostname order by time1min) order by time1min")
+}
+
+// HttpRequests selects the non negative rate per 10 seconds of the per minute
+// mean requests, per host.
+func (d *TimescaleDashboard) HttpRequests(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "http requests/min (number) by 1m, host", true, nonNegativeDerivativeSQL("avg(requests)", "nginx", 10*time.Second))
+}
+
+// KapaCpu selects the used cpu of the kapacitor host.
+func (d *TimescaleDashboard) KapaCpu(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "kapa cpu", false, "select time,100 - usage_idle from cpu where hostname = 'kapacitor_1' and %s")
+}
+
+// KapaLoad selects the load averages of the kapacitor host.
+func (d *TimescaleDashboard) KapaLoad(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "kapa load 1,5,15", false, "select time,load5,load15,load1 from system where hostname = 'kapacitor_1' and %s")
+}
+
+// KapaRam selects the used memory of the kapacitor host.
+func (d *TimescaleDashboard) KapaRam(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "kapa mem used", false, "select time,used_percent from mem where hostname = 'kapacitor_1' and %s")
+}
+
+// MemoryTotal selects the last of the per minute maximal memory sizes of the
+// data nodes in GB.
+func (d *TimescaleDashboard) MemoryTotal(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "memory (MB) by 1m", true, "select max_total from (select time_bucket(60000000000,time) as time1min,hostname,max(total)/1073741824.0 as max_total from mem where %s and hostname like 'data%%' group by time1min,hostname) t order by time1min desc limit 1")
+}
+
+// MemoryUtilization selects mean used_percent per host by minute.
+func (d *TimescaleDashboard) MemoryUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "memory utilization (percent) by 1m", true, "select time_bucket(60000000000,time) as time1min,hostname,avg(used_percent) from mem where %s group by time1min,hostname order by time1min")
+}
+
+// NginxRequests selects the non negative rate per second of the per minute
+// mean accepts, per host.
+func (d *TimescaleDashboard) NginxRequests(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "queries executed (number) by 1m, host", true, nonNegativeDerivativeSQL("avg(accepts)", "nginx", time.Second))
+}
+
+// QueueBytes selects mean temp_files per host by minute.
+func (d *TimescaleDashboard) QueueBytes(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "hinted handoff queue size (MB) by 1m", true, "select time_bucket(60000000000,time) as time1min,hostname,avg(temp_files) from postgresl where %s group by time1min,hostname order by time1min")
+}
+
+// RedisMemoryUtilization selects mean used_memory per host and server by minute.
+func (d *TimescaleDashboard) RedisMemoryUtilization(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "redis memory utilization by 1m", true, "select time_bucket(60000000000,time) as time1min,hostname,server,avg(used_memory) from redis where %s group by time1min,hostname,server order by time1min")
+}
+
+// SystemLoad selects maximal load5 and n_cpus per host by minute.
+func (d *TimescaleDashboard) SystemLoad(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "system load (load5) by 1m", true, "select time_bucket(60000000000,time) as time1min,hostname,max(load5),max(n_cpus) from system where %s group by time1min,hostname order by time1min")
+}
+
+// Throughput selects the non negative rate per 10 seconds of the per minute
+// maximal keyspace_hits, per host.
+func (d *TimescaleDashboard) Throughput(qi bulkQuerygen.Query) {
+	d.fillDashboardQuery(qi, "per-host point throughput (number) by 1m", true, nonNegativeDerivativeSQL("max(keyspace_hits)", "redis", 10*time.Second))
+}
+
+// nonNegativeDerivativeSQL returns a query computing the non negative rate
+// per unit of the per minute aggregate of table, per host, leaving a
+// placeholder for the conditions.
+func nonNegativeDerivativeSQL(aggregate, table string, unit time.Duration) string {
+	return fmt.Sprintf("select time1min,hostname,greatest(value - lag(value) over w, 0) * %d / 60000000000.0 from "+
+		"(select time_bucket(60000000000,time) as time1min,hostname,%s as value from %s where %%s group by time1min,hostname) t "+
+		"window w as (partition by hostname order by time1min) order by time1min", unit.Nanoseconds(), aggregate, table)
+}
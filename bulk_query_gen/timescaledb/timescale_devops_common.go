@@ -3,7 +3,6 @@ package timescaledb
 import (
 	"fmt"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -65,7 +64,7 @@ func (d *TimescaleDevops) MaxCPUUsage12HoursByMinuteOneHost(q bulkQuerygen.Query
 // select time_bucket(60000000000,time) as time1min,max(usage_user) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >=$HOUR_START and time < $HOUR_END group by time1min order by time1min;
 func (d *TimescaleDevops) maxCPUUsageHourByMinuteNHosts(qi bulkQuerygen.Query, nhosts int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nhosts]
+	nn := d.RandomHostIndexes(nhosts)
 
 	hostnames := []string{}
 	for _, n := range nn {
@@ -100,3 +99,120 @@ func (d *TimescaleDevops) MeanCPUUsageDayByHourAllHostsGroupbyHost(qi bulkQueryg
 
 	q.QuerySQL = []byte(fmt.Sprintf("select time_bucket(3600000000000,time) as time1hour,avg(usage_user) from cpu where time >=%d and time < %d group by time1hour,hostname order by time1hour", interval.StartUnixNano(), interval.EndUnixNano()))
 }
+
+// NewTimescaleDevopsQuery returns a maker of generators producing SQL queries
+// of the single type populated by fill.
+func NewTimescaleDevopsQuery(fill bulkQuerygen.DevopsQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.DevopsSingleQuery{
+			Devops:   newTimescaleDevopsCommon(dbConfig, interval, duration, scaleVar).(*TimescaleDevops),
+			NewQuery: func() bulkQuerygen.Query { return NewSQLQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+func (d *TimescaleDevops) fillSQLQuery(qi bulkQuerygen.Query, humanLabel, start, sql string) {
+	q := qi.(*SQLQuery)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, start))
+	q.QuerySQL = []byte(sql)
+}
+
+func hostnamesClause(hostnames []string) string {
+	hostnameClauses := []string{}
+	for _, s := range hostnames {
+		hostnameClauses = append(hostnameClauses, fmt.Sprintf("hostname = '%s'", s))
+	}
+	return strings.Join(hostnameClauses, " or ")
+}
+
+// LastPointPerHost populates a Query with a query that looks like:
+// select distinct on (hostname) * from cpu order by hostname, time desc
+func (d *TimescaleDevops) LastPointPerHost(qi bulkQuerygen.Query) {
+	humanLabel := "Timescale last cpu point, all hosts"
+	d.fillSQLQuery(qi, humanLabel, d.AllInterval.StartString(), "select distinct on (hostname) * from cpu order by hostname, time desc")
+}
+
+func (d *TimescaleDevops) HighCPUOneHost(q bulkQuerygen.Query) {
+	d.highCPU(q, d.RandomHostnames(1))
+}
+
+func (d *TimescaleDevops) HighCPUAllHosts(q bulkQuerygen.Query) {
+	d.highCPU(q, nil)
+}
+
+// highCPU populates a Query with a query that looks like:
+// select * from cpu where usage_user > 90.0 and (hostname = '$HOSTNAME') and time >=$START and time < $END
+func (d *TimescaleDevops) highCPU(qi bulkQuerygen.Query, hostnames []string) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+
+	hosts := "all hosts"
+	hostCondition := ""
+	if len(hostnames) > 0 {
+		hostCondition = fmt.Sprintf(" and (%s)", hostnamesClause(hostnames))
+		hosts = fmt.Sprintf("rand %4d hosts", len(hostnames))
+	}
+
+	humanLabel := fmt.Sprintf("Timescale high cpu, %s, rand 12h", hosts)
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select * from cpu where usage_user > %.1f%s and time >=%d and time < %d", bulkQuerygen.HighCPUThreshold, hostCondition, interval.StartUnixNano(), interval.EndUnixNano()))
+}
+
+func (d *TimescaleDevops) CPUMaxAllOneHost(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 1)
+}
+
+func (d *TimescaleDevops) CPUMaxAllEightHosts(q bulkQuerygen.Query) {
+	d.cpuMaxAll(q, 8)
+}
+
+// cpuMaxAll populates a Query with a query that looks like:
+// select time_bucket(3600000000000,time) as time1hour,max(usage_user),...,max(usage_guest_nice) from cpu where (hostname = '$HOSTNAME_1' or ... or hostname = '$HOSTNAME_N') and time >=$START and time < $END group by time1hour order by time1hour
+func (d *TimescaleDevops) cpuMaxAll(qi bulkQuerygen.Query, nhosts int) {
+	interval := d.AllInterval.RandWindow(8 * time.Hour)
+	combinedHostnameClause := hostnamesClause(d.RandomHostnames(nhosts))
+
+	selectClauses := make([]string, len(bulkQuerygen.CPUMetrics))
+	for i, m := range bulkQuerygen.CPUMetrics {
+		selectClauses[i] = fmt.Sprintf("max(%s)", m)
+	}
+
+	humanLabel := fmt.Sprintf("Timescale max of all cpu fields, rand %4d hosts, rand 8h by 1h", nhosts)
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select time_bucket(3600000000000,time) as time1hour,%s from cpu where (%s) and time >=%d and time < %d group by time1hour order by time1hour", strings.Join(selectClauses, ","), combinedHostnameClause, interval.StartUnixNano(), interval.EndUnixNano()))
+}
+
+func (d *TimescaleDevops) DoubleGroupByOneMetric(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 1)
+}
+
+func (d *TimescaleDevops) DoubleGroupByFiveMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, 5)
+}
+
+func (d *TimescaleDevops) DoubleGroupByAllMetrics(q bulkQuerygen.Query) {
+	d.doubleGroupBy(q, len(bulkQuerygen.CPUMetrics))
+}
+
+// doubleGroupBy populates a Query with a query that looks like:
+// select time_bucket(3600000000000,time) as time1hour,hostname,avg(usage_user),...,avg($METRIC_N) from cpu where time >=$START and time < $END group by time1hour,hostname order by time1hour,hostname
+func (d *TimescaleDevops) doubleGroupBy(qi bulkQuerygen.Query, nmetrics int) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	metrics := bulkQuerygen.CPUMetrics[:nmetrics]
+
+	selectClauses := make([]string, len(metrics))
+	for i, m := range metrics {
+		selectClauses[i] = fmt.Sprintf("avg(%s)", m)
+	}
+
+	humanLabel := fmt.Sprintf("Timescale mean of %d cpu fields, all hosts, rand 12h by 1h", nmetrics)
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select time_bucket(3600000000000,time) as time1hour,hostname,%s from cpu where time >=%d and time < %d group by time1hour,hostname order by time1hour,hostname", strings.Join(selectClauses, ","), interval.StartUnixNano(), interval.EndUnixNano()))
+}
+
+// TopFiveHostsByMemory populates a Query with a query that looks like:
+// select hostname,max(used_percent) as max_used_percent from mem where time >=$START and time < $END group by hostname order by max_used_percent desc limit 5
+func (d *TimescaleDevops) TopFiveHostsByMemory(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	humanLabel := "Timescale top 5 hosts by max memory used, rand 1h"
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select hostname,max(used_percent) as max_used_percent from mem where time >=%d and time < %d group by hostname order by max_used_percent desc limit 5", interval.StartUnixNano(), interval.EndUnixNano()))
+}
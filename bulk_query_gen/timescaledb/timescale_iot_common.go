@@ -4,7 +4,6 @@ import (
 	"fmt"
 	bulkDataGenIot "github.com/influxdata/influxdb-comparisons/bulk_data_gen/iot"
 	bulkQuerygen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
-	"math/rand"
 	"strings"
 	"time"
 )
@@ -42,7 +41,7 @@ func (d *TimescaleIot) AverageTemperatureDayByHourOneHome(q bulkQuerygen.Query)
 // SELECT avg(temperature) from air_condition_room where (home_id = '$HHOME_ID_1' or ... or hostname = '$HOSTNAME_N') and time >= '$HOUR_START' and time < '$HOUR_END' group by time(1h)
 func (d *TimescaleIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query, nHomes int, timeRange time.Duration) {
 	interval := d.AllInterval.RandWindow(timeRange)
-	nn := rand.Perm(d.ScaleVar)[:nHomes]
+	nn := d.RandomHostIndexes(nHomes)
 
 	homes := []string{}
 	for _, n := range nn {
@@ -80,3 +79,81 @@ func (d *TimescaleIot) averageTemperatureDayByHourNHomes(qi bulkQuerygen.Query,
 //	q.Path = []byte(fmt.Sprintf("/query?%s", v.Encode()))
 //	q.Body = nil
 //}
+
+// NewTimescaleIotQuery returns a maker of generators producing SQL queries
+// of the single iot type populated by fill.
+func NewTimescaleIotQuery(fill bulkQuerygen.IotQueryFunc) bulkQuerygen.QueryGeneratorMaker {
+	return func(dbConfig bulkQuerygen.DatabaseConfig, interval bulkQuerygen.TimeInterval, duration time.Duration, scaleVar int) bulkQuerygen.QueryGenerator {
+		return &bulkQuerygen.IotSingleQuery{
+			Iot:      NewTimescaleIotCommon(dbConfig, interval, duration, scaleVar).(*TimescaleIot),
+			NewQuery: func() bulkQuerygen.Query { return NewSQLQuery() },
+			Fill:     fill,
+		}
+	}
+}
+
+func (d *TimescaleIot) fillSQLQuery(qi bulkQuerygen.Query, humanLabel, start, sql string) {
+	q := qi.(*SQLQuery)
+	q.HumanLabel = []byte(humanLabel)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, start))
+	q.QuerySQL = []byte(sql)
+}
+
+// OpenDoorsWindowsOneHome populates a Query with a query that looks like:
+// select 'door' as kind,door_id as id,null as room_id,count(*) from door_state where home_id = '$HOME_ID' and state = 1 and time >=$START and time < $END group by door_id
+// union all select 'window',window_id,room_id,count(*) from window_state_room where ... group by window_id,room_id
+func (d *TimescaleIot) OpenDoorsWindowsOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	humanLabel := "Timescale open doors and windows, rand    1 homes, rand 12h"
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select 'door' as kind,door_id as id,null as room_id,count(*) from door_state where home_id = '%[1]s' and state = 1 and time >=%[2]d and time < %[3]d group by door_id "+
+		"union all select 'window',window_id,room_id,count(*) from window_state_room where home_id = '%[1]s' and state = 1 and time >=%[2]d and time < %[3]d group by window_id,room_id",
+		home, interval.StartUnixNano(), interval.EndUnixNano()))
+}
+
+// WaterLeakageAllHomes populates a Query with a query that looks like:
+// select home_id,room_id,count(*) from water_leakage_room where leakage = 1 and time >=$START and time < $END group by home_id,room_id
+func (d *TimescaleIot) WaterLeakageAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	humanLabel := "Timescale water leakage, all homes, rand 1h"
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select home_id,room_id,count(*) from water_leakage_room where leakage = 1 and time >=%d and time < %d group by home_id,room_id", interval.StartUnixNano(), interval.EndUnixNano()))
+}
+
+// RadiatorValveVsTemperatureOneHome populates a Query with a query joining
+// hourly mean radiator valve opening and hourly mean temperature per room.
+func (d *TimescaleIot) RadiatorValveVsTemperatureOneHome(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(12 * time.Hour)
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	humanLabel := "Timescale radiator valve vs temperature, rand    1 homes, rand 12h by 1h"
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select r.time1hour,r.room_id,r.opening_level,t.temperature from "+
+		"(select time_bucket(3600000000000,time) as time1hour,room_id,avg(opening_level) as opening_level from radiator_valve_room where home_id = '%[1]s' and time >=%[2]d and time < %[3]d group by time1hour,room_id) r "+
+		"join (select time_bucket(3600000000000,time) as time1hour,room_id,avg(temperature) as temperature from air_condition_room where home_id = '%[1]s' and time >=%[2]d and time < %[3]d group by time1hour,room_id) t "+
+		"on r.time1hour = t.time1hour and r.room_id = t.room_id order by r.time1hour,r.room_id",
+		home, interval.StartUnixNano(), interval.EndUnixNano()))
+}
+
+// BatteryLowAllHomes populates a Query with a query that looks like:
+// select home_id,sensor_id,min(battery_voltage) from (select home_id,sensor_id,battery_voltage from air_condition_room where time >=$START and time < $END union all ...) b where battery_voltage < 2.0 group by home_id,sensor_id
+func (d *TimescaleIot) BatteryLowAllHomes(qi bulkQuerygen.Query) {
+	interval := d.AllInterval.RandWindow(time.Hour)
+
+	selects := make([]string, len(bulkQuerygen.IotBatteryMeasurements))
+	for i, m := range bulkQuerygen.IotBatteryMeasurements {
+		selects[i] = fmt.Sprintf("select home_id,sensor_id,battery_voltage from %s where time >=%d and time < %d", m, interval.StartUnixNano(), interval.EndUnixNano())
+	}
+
+	humanLabel := "Timescale battery low sensors, all homes, rand 1h"
+	d.fillSQLQuery(qi, humanLabel, interval.StartString(), fmt.Sprintf("select home_id,sensor_id,min(battery_voltage) from (%s) b where battery_voltage < %g group by home_id,sensor_id", strings.Join(selects, " union all "), bulkQuerygen.IotBatteryLowThreshold))
+}
+
+// LastStatePerRoomOneHome populates a Query with a query that looks like:
+// select distinct on (room_id) * from air_condition_room where home_id = '$HOME_ID' order by room_id, time desc
+func (d *TimescaleIot) LastStatePerRoomOneHome(qi bulkQuerygen.Query) {
+	home := fmt.Sprintf(bulkDataGenIot.SmartHomeIdFormat, d.RandomHostIndex())
+
+	humanLabel := "Timescale last state per room, rand    1 homes"
+	d.fillSQLQuery(qi, humanLabel, d.AllInterval.StartString(), fmt.Sprintf("select distinct on (room_id) * from air_condition_room where home_id = '%s' order by room_id, time desc", home))
+}
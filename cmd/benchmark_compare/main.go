@@ -0,0 +1,181 @@
+// benchmark_compare compares two sets of benchmark results, e.g. before and
+// after a database upgrade, and prints the per query label mean latency and
+// the load throughput deltas. When a set holds repeated runs, the deltas are
+// tested for significance with Welch's t-test.
+//
+// The result sets are either JSON summaries written with -summary-file
+// (comma separated lists of files or globs):
+//
+//	benchmark_compare -base 'before-*.json' -new 'after-*.json'
+//
+// or the results of the report database, selected by InfluxQL conditions:
+//
+//	benchmark_compare -report-host http://localhost:8086 -base "\"version\"='1.7'" -new "\"version\"='1.8'"
+//
+// With -threshold, it exits with status 1 if a metric got worse by more than
+// the threshold percentage (and significantly, when that can be tested).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/util/stats"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Program option vars:
+var (
+	baseResults    string
+	newResults     string
+	reportHost     string
+	reportDatabase string
+	reportUser     string
+	reportPassword string
+	threshold      float64
+	alpha          float64
+)
+
+// resultSet holds the results of the runs of a set, per metric.
+type resultSet struct {
+	// mean latency in milliseconds of every run, per query label
+	queries map[string][]float64
+	// throughput of every run, per load metric
+	load map[string][]float64
+}
+
+func newResultSet() *resultSet {
+	return &resultSet{
+		queries: make(map[string][]float64),
+		load:    make(map[string][]float64),
+	}
+}
+
+// Load metrics, higher is better:
+const (
+	valuesRate = "values_rate"
+	bytesRate  = "bytes_rate"
+)
+
+// Parse args:
+func init() {
+	flag.StringVar(&baseResults, "base", "", "Baseline results: comma separated summary files or globs, or an InfluxQL condition selecting them in the report database.")
+	flag.StringVar(&newResults, "new", "", "New results: comma separated summary files or globs, or an InfluxQL condition selecting them in the report database.")
+	flag.StringVar(&reportHost, "report-host", "", "Report database host to read the results from, instead of summary files.")
+	flag.StringVar(&reportDatabase, "report-database", "database_benchmarks", "Report database name.")
+	flag.StringVar(&reportUser, "report-user", "", "User of the report database.")
+	flag.StringVar(&reportPassword, "report-password", "", "User password of the report database.")
+	flag.Float64Var(&threshold, "threshold", 0, "Regression threshold in percent: exit with status 1 if a metric got worse by more than this (0 to disable).")
+	flag.Float64Var(&alpha, "alpha", 0.05, "Significance level of the t-test.")
+
+	flag.Parse()
+
+	if baseResults == "" || newResults == "" {
+		log.Fatal("both -base and -new results are required")
+	}
+	if threshold < 0 {
+		log.Fatalf("invalid threshold: %f", threshold)
+	}
+	if alpha <= 0 || alpha >= 1 {
+		log.Fatalf("invalid significance level: %f", alpha)
+	}
+}
+
+func main() {
+	var baseSet, newSet *resultSet
+	var err error
+	if reportHost != "" {
+		db := &reportDB{host: reportHost, database: reportDatabase, user: reportUser, password: reportPassword}
+		if baseSet, err = db.readResults(baseResults); err == nil {
+			newSet, err = db.readResults(newResults)
+		}
+	} else {
+		if baseSet, err = readSummaries(baseResults); err == nil {
+			newSet, err = readSummaries(newResults)
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	regressions := 0
+	if len(baseSet.queries) > 0 || len(newSet.queries) > 0 {
+		fmt.Println("query mean latency (ms), lower is better:")
+		regressions += compare(baseSet.queries, newSet.queries, false)
+	}
+	if len(baseSet.load) > 0 || len(newSet.load) > 0 {
+		fmt.Println("load throughput (per sec), higher is better:")
+		regressions += compare(baseSet.load, newSet.load, true)
+	}
+
+	if regressions > 0 {
+		fmt.Printf("%d regressions beyond %.2f%%\n", regressions, threshold)
+		os.Exit(1)
+	}
+}
+
+// compare prints the deltas of the metrics of two sets and returns the number
+// of regressions.
+func compare(baseMetrics, newMetrics map[string][]float64, higherIsBetter bool) int {
+	names := make([]string, 0, len(baseMetrics))
+	maxLength := 0
+	for name := range baseMetrics {
+		names = append(names, name)
+	}
+	for name := range newMetrics {
+		if _, ok := baseMetrics[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if len(name) > maxLength {
+			maxLength = len(name)
+		}
+	}
+	sort.Strings(names)
+
+	regressions := 0
+	for _, name := range names {
+		b, n := baseMetrics[name], newMetrics[name]
+		paddedName := name + strings.Repeat(" ", maxLength-len(name))
+		if len(b) == 0 || len(n) == 0 {
+			side := "new"
+			if len(n) == 0 {
+				side = "base"
+			}
+			fmt.Printf("  %s : only in %s results\n", paddedName, side)
+			continue
+		}
+		bMean, nMean := stats.Mean(b), stats.Mean(n)
+		delta := 0.0
+		if bMean != 0 {
+			delta = (nMean - bMean) / bMean * 100
+		}
+		significance := "     n/a"
+		significant := true
+		if p, ok := stats.WelchTTest(b, n); ok {
+			significance = fmt.Sprintf("%8.4f", p)
+			significant = p < alpha
+		}
+		worse := delta > 0
+		if higherIsBetter {
+			worse = delta < 0
+		}
+		verdict := ""
+		if threshold > 0 && worse && significant && abs(delta) > threshold {
+			verdict = " REGRESSION"
+			regressions++
+		}
+		fmt.Printf("  %s : base: %10.2f (n=%d), new: %10.2f (n=%d), delta: %+8.2f%%, p: %s%s\n", paddedName, bMean, len(b), nMean, len(n), delta, significance, verdict)
+	}
+	return regressions
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
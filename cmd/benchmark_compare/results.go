@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/util/report"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// readSummaries reads the results of the summary files matching a comma
+// separated list of files or globs.
+func readSummaries(patterns string) (*resultSet, error) {
+	set := newResultSet()
+	for _, pattern := range strings.Split(patterns, ",") {
+		matches, err := filepath.Glob(strings.TrimSpace(pattern))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no summary file matches %s", pattern)
+		}
+		for _, path := range matches {
+			if err := set.addSummary(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return set, nil
+}
+
+func (s *resultSet) addSummary(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var summary report.Summary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if summary.Version != report.SummaryVersion {
+		return fmt.Errorf("%s: unsupported summary version %d", path, summary.Version)
+	}
+	switch summary.Kind {
+	case report.SummaryLoad:
+		if summary.Load != nil {
+			s.load[valuesRate] = append(s.load[valuesRate], summary.Load.ValuesRate)
+			s.load[bytesRate] = append(s.load[bytesRate], summary.Load.BytesRate)
+		}
+	case report.SummaryQuery:
+		for _, q := range summary.Queries {
+			if q.Count > 0 {
+				s.queries[q.Label] = append(s.queries[q.Label], q.Mean)
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unknown summary kind %s", path, summary.Kind)
+	}
+	return nil
+}
+
+// reportDB reads the results written to an InfluxDB 1.x report database by
+// report.ReportQueryResult and report.ReportLoadResult.
+type reportDB struct {
+	host     string
+	database string
+	user     string
+	password string
+}
+
+type influxResponse struct {
+	Results []struct {
+		Series []struct {
+			Tags   map[string]string `json:"tags"`
+			Values [][]interface{}   `json:"values"`
+		} `json:"series"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// readResults reads the query and load results matching an InfluxQL
+// condition, every point being a run.
+func (db *reportDB) readResults(condition string) (*resultSet, error) {
+	set := newResultSet()
+
+	resp, err := db.query(fmt.Sprintf(`SELECT "mean_time" FROM "query_benchmarks" WHERE %s GROUP BY "query_name"`, condition))
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range resp.Results {
+		for _, series := range result.Series {
+			label := report.Unescape(series.Tags["query_name"])
+			for _, row := range series.Values {
+				if v, ok := row[1].(float64); ok {
+					set.queries[label] = append(set.queries[label], v)
+				}
+			}
+		}
+	}
+
+	resp, err = db.query(fmt.Sprintf(`SELECT "values_rate", "input_rate" FROM "load_benchmarks" WHERE %s`, condition))
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range resp.Results {
+		for _, series := range result.Series {
+			for _, row := range series.Values {
+				if v, ok := row[1].(float64); ok {
+					set.load[valuesRate] = append(set.load[valuesRate], v)
+				}
+				if v, ok := row[2].(float64); ok {
+					set.load[bytesRate] = append(set.load[bytesRate], v)
+				}
+			}
+		}
+	}
+
+	if len(set.queries) == 0 && len(set.load) == 0 {
+		return nil, fmt.Errorf("no results match %s", condition)
+	}
+	return set, nil
+}
+
+func (db *reportDB) query(q string) (*influxResponse, error) {
+	params := url.Values{}
+	params.Set("db", db.database)
+	params.Set("q", q)
+	req, err := http.NewRequest("GET", db.host+"/query?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if db.user != "" {
+		req.SetBasicAuth(db.user, db.password)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("report database error: unexpected status code %d: %s", resp.StatusCode, body)
+	}
+	var r influxResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, err
+	}
+	if r.Error != "" {
+		return nil, fmt.Errorf("report database error: %s", r.Error)
+	}
+	for _, result := range r.Results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("report database error: %s", result.Error)
+		}
+	}
+	return &r, nil
+}
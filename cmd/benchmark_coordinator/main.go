@@ -0,0 +1,312 @@
+// benchmark_coordinator coordinates a bulk load or a query benchmark run on
+// many client machines. The bulk loaders or query benchmarkers started with
+// -coordinator <host:port> register with it as agents and receive their
+// shard: the client index, which is also the interleaved generation group of
+// the data they load, and optionally their number of workers. Once all the
+// agents registered and are ready, they start together, stream their progress back and
+// finally send their results, which are merged into a single report:
+//
+//	benchmark_coordinator -kind load -clients 4 -listen :8765
+//	bulk_load_influx -coordinator coordinator:8765 -file data-{group}.txt -urls ...   # on each client
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/util/coordinator"
+	"github.com/influxdata/influxdb-comparisons/util/report"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Program option vars:
+var (
+	listenAddr       string
+	kind             string
+	clients          int
+	workers          int
+	progressInterval time.Duration
+	timeout          time.Duration
+	summaryFile      string
+	reportHost       string
+	reportDatabase   string
+	reportUser       string
+	reportPassword   string
+	reportTagsCSV    string
+	reportSinks      string
+)
+
+// Parse args:
+func init() {
+	flag.StringVar(&listenAddr, "listen", ":8765", "Address to listen for agents on.")
+	flag.StringVar(&kind, "kind", "", "Kind of the agents: "+coordinator.KindLoad+" or "+coordinator.KindQuery+".")
+	flag.IntVar(&clients, "clients", 0, "Number of agents to wait for before starting.")
+	flag.IntVar(&workers, "workers", 0, "Number of workers of every agent (0 to keep their own).")
+	flag.DurationVar(&progressInterval, "progress-interval", 10*time.Second, "Interval of printing the merged progress of the agents (0 to disable).")
+	flag.DurationVar(&timeout, "timeout", 0, "Maximum duration to wait for the results of the agents once started (0 for no limit).")
+	flag.StringVar(&summaryFile, "summary-file", "", "Write a JSON summary of the merged results to this file on completion.")
+	flag.StringVar(&reportHost, "report-host", "", "Host to send the merged result metrics.")
+	flag.StringVar(&reportDatabase, "report-database", "database_benchmarks", "Database name where to store result metrics.")
+	flag.StringVar(&reportUser, "report-user", "", "User for host to send result metrics.")
+	flag.StringVar(&reportPassword, "report-password", "", "User password for host to send result metrics.")
+	flag.StringVar(&reportTagsCSV, "report-tags", "", "Comma separated k:v tags to send alongside result metrics.")
+	flag.StringVar(&reportSinks, "report-sink", "", "Comma separated additional destinations of result metrics: json=<file>, csv=<file>, prometheus=<listen address>, stdout.")
+
+	flag.Parse()
+
+	if kind != coordinator.KindLoad && kind != coordinator.KindQuery {
+		log.Fatalf("invalid kind: %s", kind)
+	}
+	if clients < 1 {
+		log.Fatalf("invalid number of clients: %d", clients)
+	}
+	if workers < 0 {
+		log.Fatalf("invalid number of workers: %d", workers)
+	}
+}
+
+func main() {
+	c := coordinator.NewCoordinator(kind, clients, workers)
+	if err := c.Listen(listenAddr); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("waiting for %d %s agents on %s\n", clients, kind, listenAddr)
+	<-c.Started()
+
+	var progressTicks <-chan time.Time
+	if progressInterval > 0 {
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		progressTicks = ticker.C
+	}
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timeoutC = time.After(timeout)
+	}
+	timedOut := false
+loop:
+	for {
+		select {
+		case <-c.Done():
+			break loop
+		case <-progressTicks:
+			printProgress(c.Progress())
+		case <-timeoutC:
+			log.Printf("timeout waiting for the results of the agents")
+			timedOut = true
+			break loop
+		}
+	}
+
+	results, took := c.Results()
+	end := time.Now()
+	exitCode := 0
+	var errors []string
+	for i, r := range results {
+		if r == nil {
+			errors = append(errors, fmt.Sprintf("agent %d: no result", i))
+			continue
+		}
+		for _, e := range r.Errors {
+			errors = append(errors, fmt.Sprintf("agent %d: %s", i, e))
+		}
+	}
+	for _, e := range errors {
+		fmt.Println(e)
+	}
+	if len(errors) > 0 {
+		exitCode = 1
+	}
+
+	reportParams := report.ReportParams{
+		ReportDatabaseName: reportDatabase,
+		ReportHost:         reportHost,
+		ReportUser:         reportUser,
+		ReportPassword:     reportPassword,
+		ReportTags:         reportTags(),
+		Hostname:           report.Escape(strings.Join(c.Hostnames(), ",")),
+		ItemLimit:          -1,
+	}
+	sink := openReportSink()
+	reportParams.Sink = sink
+	var summary *report.Summary
+	if summaryFile != "" {
+		summary = report.NewSummary(kind, end.Add(-took), end)
+		summary.Errors = append(summary.Errors, errors...)
+		if timedOut {
+			summary.PrematureEnd = "Timeout waiting for agents"
+		}
+		summary.ExitCode = exitCode
+	}
+
+	if kind == coordinator.KindLoad {
+		mergeLoad(results, took, &reportParams, sink, summary)
+	} else {
+		mergeQueries(results, took, &reportParams, sink, summary)
+	}
+
+	if sink != nil {
+		if err := sink.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if summary != nil {
+		summary.SetParams(&reportParams)
+		if err := summary.WriteFile(summaryFile); err != nil {
+			log.Fatalf("Error writing summary: %v\n", err)
+		}
+	}
+	os.Exit(exitCode)
+}
+
+func reportTags() [][2]string {
+	tags := [][2]string{{"clients", fmt.Sprintf("%d", clients)}}
+	if reportTagsCSV != "" {
+		for _, pair := range strings.Split(reportTagsCSV, ",") {
+			fields := strings.SplitN(pair, ":", 2)
+			if len(fields) != 2 {
+				log.Fatalf("invalid report tag: %s", pair)
+			}
+			tags = append(tags, [2]string{fields[0], fields[1]})
+		}
+	}
+	return tags
+}
+
+// openReportSink creates the destinations of result metrics, nil if none.
+func openReportSink() report.Sink {
+	sinks, err := report.NewSinks(reportSinks, 0)
+	if err != nil {
+		log.Fatalf("Error creating report sinks: %v\n", err)
+	}
+	if reportHost != "" {
+		s, err := report.NewInfluxSink(reportHost, reportDatabase, reportUser, reportPassword)
+		if err != nil {
+			log.Fatalf("Error creating report db: %v\n", err)
+		}
+		sinks = append(sinks, s)
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	return report.MultiSink(sinks)
+}
+
+func printProgress(progress []coordinator.Progress) {
+	if kind == coordinator.KindLoad {
+		values, rate := 0.0, 0.0
+		for _, p := range progress {
+			values += p.Values
+			rate += p.IngestRate
+		}
+		fmt.Printf("progress: %.0f values loaded, moving mean rate %.2f values/sec\n", values, rate)
+		return
+	}
+	var queries int64
+	meanSum, running := 0.0, 0
+	for _, p := range progress {
+		queries += p.Queries
+		if p.Queries > 0 {
+			meanSum += p.MeanResponseTime
+			running++
+		}
+	}
+	meanTime := 0.0
+	if running > 0 {
+		meanTime = meanSum / float64(running)
+	}
+	fmt.Printf("progress: %d queries, moving mean response time %.2fms\n", queries, meanTime)
+}
+
+// mergeLoad sums the throughput of the load agents over the time from the
+// start to the last result.
+func mergeLoad(results []*coordinator.Result, took time.Duration, params *report.ReportParams, sink report.Sink, summary *report.Summary) {
+	var items, values, bytes int64
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		items += r.Items
+		values += r.Values
+		bytes += r.Bytes
+		params.Workers += r.Workers
+	}
+	itemsRate := float64(items) / took.Seconds()
+	valuesRate := float64(values) / took.Seconds()
+	bytesRate := float64(bytes) / took.Seconds()
+	fmt.Printf("loaded %d items in %fsec with %d agents and %d workers (mean point rate %f/sec, mean value rate %f/s, %.2fMB/sec)\n", items, took.Seconds(), clients, params.Workers, itemsRate, valuesRate, bytesRate/(1<<20))
+
+	if sink != nil {
+		if err := report.ReportLoadResult(&report.LoadReportParams{ReportParams: *params}, items, valuesRate, bytesRate, took); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if summary != nil {
+		summary.Load = &report.LoadSummary{
+			Items:      items,
+			Values:     values,
+			Bytes:      bytes,
+			ItemsRate:  itemsRate,
+			ValuesRate: valuesRate,
+			BytesRate:  bytesRate,
+		}
+	}
+}
+
+// mergeQueries merges the latency statistics of the query agents per label.
+func mergeQueries(results []*coordinator.Result, took time.Duration, params *report.ReportParams, sink report.Sink, summary *report.Summary) {
+	merged := make(map[string]*coordinator.QueryStat)
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		params.Workers += r.Workers
+		for _, q := range r.Queries {
+			if q.Count == 0 {
+				continue
+			}
+			m, ok := merged[q.Label]
+			if !ok {
+				m = &coordinator.QueryStat{Label: q.Label, Min: q.Min, Max: q.Max}
+				merged[q.Label] = m
+			}
+			if q.Min < m.Min {
+				m.Min = q.Min
+			}
+			if q.Max > m.Max {
+				m.Max = q.Max
+			}
+			m.Count += q.Count
+			m.Sum += q.Sum
+		}
+	}
+	labels := make([]string, 0, len(merged))
+	maxLength := 0
+	for label := range merged {
+		labels = append(labels, label)
+		if len(label) > maxLength {
+			maxLength = len(label)
+		}
+	}
+	sort.Strings(labels)
+
+	fmt.Printf("ran queries in %fsec with %d agents and %d workers:\n", took.Seconds(), clients, params.Workers)
+	for _, label := range labels {
+		m := merged[label]
+		mean := m.Sum / float64(m.Count)
+		paddedLabel := label + strings.Repeat(" ", maxLength-len(label))
+		fmt.Printf("%s : min: %8.2fms (%7.2f/sec), mean: %8.2fms (%7.2f/sec), max: %7.2fms (%6.2f/sec), count: %8d, sum: %5.1fsec \n", paddedLabel, m.Min, 1000/m.Min, mean, 1000/mean, m.Max, 1000/m.Max, m.Count, m.Sum/1e3)
+
+		if sink != nil {
+			if err := report.ReportQueryResult(&report.QueryReportParams{ReportParams: *params}, label, m.Min, mean, m.Max, m.Count, took); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if summary != nil {
+			summary.AddQuery(label, m.Count, m.Min, mean, m.Max, m.Sum, nil)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// benchmark_mixed runs a bulk loader and one or more query benchmarkers
+// concurrently against the same database for a fixed duration, and reports
+// the query latency under ingest alongside the ingest throughput under query
+// load.
+//
+// The loader reads -load-file from its stdin, fed by benchmark_mixed at up to
+// -write-rate lines per second until the duration elapses. The query
+// benchmarkers read their -file over and over for the duration:
+//
+//	benchmark_mixed -duration 5m -write-rate 50000 -load-file data.txt \
+//	    -load "bulk_load_influx -urls http://localhost:8086 -workers 4" \
+//	    -query "query_benchmarker_influxdb -urls http://localhost:8086 -workers 2 -file lastpoint.gob" \
+//	    -query "query_benchmarker_influxdb -urls http://localhost:8086 -workers 2 -file groupby.gob"
+//
+// Commands are split on white space, without any shell quoting. The results
+// are read from the -summary-file of every run, which are kept in
+// -summary-dir.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/util/mixed"
+	"github.com/influxdata/influxdb-comparisons/util/report"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// commandList is a repeatable command line flag.
+type commandList []string
+
+func (l *commandList) String() string {
+	return strings.Join(*l, "; ")
+}
+
+func (l *commandList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// Program option vars:
+var (
+	loadCommand    string
+	queryCommands  commandList
+	loadFile       string
+	writeRate      float64
+	duration       time.Duration
+	summaryDir     string
+	keepSummaryDir bool
+)
+
+// run is a benchmark process.
+type run struct {
+	name        string
+	args        []string
+	summaryFile string
+	cmd         *exec.Cmd
+	output      *mixed.PrefixWriter
+	err         error
+}
+
+// Parse args:
+func init() {
+	flag.StringVar(&loadCommand, "load", "", "Bulk loader command line, e.g. \"bulk_load_influx -urls http://localhost:8086\". It must read its input from stdin.")
+	flag.Var(&queryCommands, "query", "Query benchmarker command line reading its queries with -file (repeatable).")
+	flag.StringVar(&loadFile, "load-file", "", "Input of the bulk loader, fed to its stdin.")
+	flag.Float64Var(&writeRate, "write-rate", 0, "Maximum number of input lines fed to the bulk loader per second (0 for no limit).")
+	flag.DurationVar(&duration, "duration", time.Minute, "Duration of the mixed workload.")
+	flag.StringVar(&summaryDir, "summary-dir", "", "Directory where to keep the summary files of the runs (default: a temporary directory, removed on exit).")
+
+	flag.Parse()
+
+	if loadCommand == "" || len(queryCommands) == 0 {
+		log.Fatal("a -load command and at least one -query command are required")
+	}
+	if loadFile == "" {
+		log.Fatal("missing -load-file")
+	}
+	if writeRate < 0 {
+		log.Fatalf("invalid write rate: %f", writeRate)
+	}
+	if duration <= 0 {
+		log.Fatalf("invalid duration: %s", duration)
+	}
+	if mixed.HasFlag(strings.Fields(loadCommand), "file") {
+		log.Fatal("the -load command must read its input from stdin, use -load-file")
+	}
+	for _, c := range queryCommands {
+		if !mixed.HasFlag(strings.Fields(c), "file") {
+			log.Fatalf("the -query command must read its queries with -file: %s", c)
+		}
+	}
+	if summaryDir == "" {
+		dir, err := ioutil.TempDir("", "benchmark_mixed")
+		if err != nil {
+			log.Fatal(err)
+		}
+		summaryDir = dir
+	} else {
+		if err := os.MkdirAll(summaryDir, 0755); err != nil {
+			log.Fatal(err)
+		}
+		keepSummaryDir = true
+	}
+}
+
+func main() {
+	if !keepSummaryDir {
+		defer os.RemoveAll(summaryDir)
+	}
+
+	input, err := os.Open(loadFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer input.Close()
+
+	// the loader ends once its input is closed, the time limit only bounds
+	// its draining
+	loader := newRun("load", loadCommand, "-time-limit", (duration + duration/2).String())
+	queriers := make([]*run, len(queryCommands))
+	for i, c := range queryCommands {
+		queriers[i] = newRun(fmt.Sprintf("query-%d", i+1), c, "-benchmark-duration", duration.String())
+	}
+
+	loaderStdin, err := loader.cmd.StdinPipe()
+	if err != nil {
+		log.Fatal(err)
+	}
+	runs := append([]*run{loader}, queriers...)
+	for i, r := range runs {
+		if err := r.cmd.Start(); err != nil {
+			for _, started := range runs[:i] {
+				started.cmd.Process.Kill()
+			}
+			log.Fatalf("cannot start %s: %v", r.name, err)
+		}
+	}
+	fmt.Printf("mixed workload: running %d query benchmarkers for %s, write rate: %s\n", len(queriers), duration, rateString(writeRate))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	wait := func(r *run) {
+		defer wg.Done()
+		r.err = r.cmd.Wait()
+		r.output.Flush()
+	}
+	for _, r := range queriers {
+		wg.Add(1)
+		go wait(r)
+	}
+	lines, err := mixed.Feed(loaderStdin, input, writeRate, start.Add(duration))
+	if err != nil {
+		log.Printf("feeding the loader stopped: %v", err)
+	}
+	// Wait closes the stdin pipe, so the loader is waited for only once fed
+	loaderStdin.Close()
+	wg.Add(1)
+	go wait(loader)
+	fed := time.Since(start)
+	if fed < duration {
+		fmt.Printf("load input exhausted after %s: ingest ended before the end of the mixed workload\n", fed)
+	}
+	wg.Wait()
+
+	exitCode := 0
+	for _, r := range runs {
+		if r.err != nil {
+			log.Printf("%s failed: %v", r.name, r.err)
+			exitCode = 1
+		}
+	}
+	fmt.Printf("fed %d lines to the loader in %s (%.2f/sec)\n", lines, fed, float64(lines)/fed.Seconds())
+	if err := printResults(loader, queriers); err != nil {
+		log.Print(err)
+		exitCode = 1
+	}
+	if keepSummaryDir {
+		fmt.Printf("summary files kept in %s\n", summaryDir)
+	}
+	if exitCode != 0 {
+		if !keepSummaryDir {
+			os.RemoveAll(summaryDir)
+		}
+		os.Exit(exitCode)
+	}
+}
+
+func newRun(name, command string, extraArgs ...string) *run {
+	args := strings.Fields(command)
+	r := &run{name: name, summaryFile: filepath.Join(summaryDir, name+".json")}
+	r.args = append(args, extraArgs...)
+	r.args = append(r.args, "-summary-file", r.summaryFile)
+	r.output = mixed.NewPrefixWriter("["+name+"] ", os.Stdout)
+	r.cmd = exec.Command(r.args[0], r.args[1:]...)
+	r.cmd.Stdout = r.output
+	r.cmd.Stderr = r.output
+	return r
+}
+
+func rateString(rate float64) string {
+	if rate == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.0f lines/sec", rate)
+}
+
+func readSummary(r *run) (*report.Summary, error) {
+	data, err := ioutil.ReadFile(r.summaryFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: no summary: %v", r.name, err)
+	}
+	var s report.Summary
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("%s: %v", r.name, err)
+	}
+	if s.Version != report.SummaryVersion {
+		return nil, fmt.Errorf("%s: unsupported summary version %d", r.name, s.Version)
+	}
+	return &s, nil
+}
+
+// printResults prints the ingest throughput under query load and the query
+// latency under ingest.
+func printResults(loader *run, queriers []*run) error {
+	var firstErr error
+	s, err := readSummary(loader)
+	if err != nil {
+		firstErr = err
+	} else if s.Load != nil {
+		fmt.Printf("ingest under query load: %d items in %.2fsec with %d workers (mean point rate %.2f/sec, mean value rate %.2f/s, %.2fMB/sec)\n",
+			s.Load.Items, s.Duration, s.Workers, s.Load.ItemsRate, s.Load.ValuesRate, s.Load.BytesRate/(1<<20))
+	}
+
+	fmt.Println("query latency under ingest (ms):")
+	for _, r := range queriers {
+		s, err := readSummary(r)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		maxLength := 0
+		for _, q := range s.Queries {
+			if len(q.Label) > maxLength {
+				maxLength = len(q.Label)
+			}
+		}
+		for _, q := range s.Queries {
+			paddedLabel := q.Label + strings.Repeat(" ", maxLength-len(q.Label))
+			fmt.Printf("  [%s] %s : min: %8.2f, mean: %8.2f, max: %8.2f, count: %8d\n", r.name, paddedLabel, q.Min, q.Mean, q.Max, q.Count)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,128 @@
+// bulk_data_convert converts data generated by bulk_data_gen in the binary
+// intermediate format into any other supported format. Converting a single
+// generated dataset guarantees that all databases are loaded with the same
+// logical data.
+//
+// Usage:
+//
+//	bulk_data_gen -format binary ... > data.bin
+//	bulk_data_convert -format influx-bulk < data.bin > data.influx
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/columnar"
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
+)
+
+// formatChoices are the formats of the common serializers and the columnar
+// formats.
+var formatChoices = append(append([]string{}, common.FormatChoices...), columnar.FormatChoices...)
+
+// Program option vars:
+var (
+	format            string
+	inputFile         string
+	columnarOutputDir string
+	rowGroupSize      int
+
+	timestampPrecisionStr string
+)
+
+// Parse args:
+func init() {
+	flag.StringVar(&format, "format", formatChoices[0], fmt.Sprintf("Format to emit. (choices: %s)", strings.Join(formatChoices, ", ")))
+	flag.StringVar(&inputFile, "file", "", "Input file in the binary intermediate format (default stdin).")
+	flag.StringVar(&columnarOutputDir, "columnar-output-dir", ".", "Directory to write per-measurement files to (parquet and arrow-ipc formats only).")
+	flag.IntVar(&rowGroupSize, "row-group-size", columnar.DefaultRowGroupSize, "Rows per row group or record batch (parquet and arrow-ipc formats only).")
+	flag.StringVar(&timestampPrecisionStr, "timestamp-precision", "", fmt.Sprintf("Timestamp precision, timestamps of all formats are truncated to it and influx-bulk timestamps are written in this unit. A precision finer than the timestamps of the format is rejected. (choices: %s) (default: the unit of the format's timestamps)", strings.Join(common.TimestampPrecisionChoices, ", ")))
+
+	flag.Parse()
+}
+
+func main() {
+	timestampPrecisionStr, err := common.FormatTimestampPrecision(format, timestampPrecisionStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	timestampPrecision, err := common.ParseTimestampPrecision(timestampPrecisionStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var serializer common.Serializer
+	if columnar.IsFormat(format) {
+		serializer, err = columnar.NewSerializerForFormat(format, columnarOutputDir, rowGroupSize)
+	} else {
+		serializer, err = common.NewSerializerForFormat(format, common.SerializerOptions{
+			TimestampPrecision: timestampPrecision,
+		})
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var in io.Reader = os.Stdin
+	if inputFile != "" {
+		f, err := os.Open(inputFile)
+		if err != nil {
+			log.Fatalf("cannot open input file: %v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	r, err := common.NewBinaryPointReader(in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := bufio.NewWriterSize(os.Stdout, 4<<24)
+	defer out.Flush()
+
+	timestamps := common.NewTimestampAdjuster(timestampPrecision, 0, 0)
+
+	t := time.Now()
+	point := common.MakeUsablePoint()
+	n := int64(0)
+	nValues := int64(0)
+	for {
+		err := r.Next(point)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error reading point %d: %v", n, err)
+		}
+		timestamps.Adjust(point)
+		if err := serializer.SerializePoint(out, point); err != nil {
+			log.Fatal(err)
+		}
+		nValues += int64(len(point.FieldValues))
+		point.Reset()
+		n++
+	}
+
+	// the size record of an interleaved group holds the dataset totals, which
+	// are kept in the output, and the group's own counts
+	writtenPoints, writtenValues, _ := r.Written()
+	if n != writtenPoints || nValues != writtenValues {
+		log.Fatalf("Logic error, read %d points, %d values, input has %d points, %d values", n, nValues, writtenPoints, writtenValues)
+	}
+	points, values, _ := r.Size()
+	if err := serializer.SerializeSize(out, points, values); err != nil {
+		log.Fatal(err)
+	}
+	err = out.Flush()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	log.Printf("Converted %d points, %d values, took %0f seconds\n", n, nValues, time.Now().Sub(t).Seconds())
+}
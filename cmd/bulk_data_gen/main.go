@@ -9,6 +9,13 @@
 // TimescaleDB SQL INSERT and binary COPY FROM
 // Graphite plaintext format
 // Splunk JSON format
+// CSV and InfluxDB annotated CSV
+// Apache Parquet and Arrow IPC stream (one file per measurement)
+// Binary intermediate format (see bulk_data_convert)
+//
+// Timestamps are generated in UTC. The formats write them as Unix epoch
+// values, or as RFC3339 UTC times for csv, so there is no time zone option: a
+// time zone would only change how the same instants are displayed.
 //
 // Supported use cases:
 // Devops: scale_var is the number of hosts to simulate, with log messages
@@ -19,20 +26,24 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
 	"strings"
 	"time"
 
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/columnar"
 	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
 	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/dashboard"
 	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/devops"
 	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/iot"
 )
 
-// Output data format choices:
-var formatChoices = []string{"influx-bulk", "es-bulk", "es-bulk6x", "es-bulk7x", "cassandra", "mongo", "opentsdb", "timescaledb-sql", "timescaledb-copyFrom", "graphite-line", "splunk-json"}
+// formatChoices are the formats of the common serializers and the columnar
+// formats.
+var formatChoices = append(append([]string{}, common.FormatChoices...), columnar.FormatChoices...)
 
 // Program option vars:
 var (
@@ -46,6 +57,10 @@ var (
 	scaleVarOffset   int64
 	cardinality      int64
 	samplingInterval time.Duration
+	samplingJitter   time.Duration
+
+	timestampPrecisionStr string
+	timestampPrecision    time.Duration
 
 	timestampStartStr string
 	timestampEndStr   string
@@ -60,6 +75,11 @@ var (
 	debug int
 
 	cpuProfile string
+
+	columnarOutputDir string
+	rowGroupSize      int
+
+	manifestFile string
 )
 
 const NHostSims = 9
@@ -83,6 +103,8 @@ func init() {
 	flag.Int64Var(&cardinality, "cardinality", 1, "Target measures/tags unique counts (over writes the 'scale-var').")
 	flag.Int64Var(&scaleVarOffset, "scale-var-offset", 0, "Scaling variable offset specific to the use case.")
 	flag.DurationVar(&samplingInterval, "sampling-interval", devops.EpochDuration, "Simulated sampling interval.")
+	flag.DurationVar(&samplingJitter, "sampling-jitter", 0, "Maximum random offset added to each point timestamp (must be less than sampling interval).")
+	flag.StringVar(&timestampPrecisionStr, "timestamp-precision", "", fmt.Sprintf("Timestamp precision, timestamps of all formats are truncated to it and influx-bulk timestamps are written in this unit. A precision finer than the timestamps of the format is rejected. (choices: %s) (default: the unit of the format's timestamps)", strings.Join(common.TimestampPrecisionChoices, ", ")))
 	flag.StringVar(&configFile, "config-file", "", "Simulator config file in TOML format (experimental)")
 
 	flag.StringVar(&timestampStartStr, "timestamp-start", common.DefaultDateTimeStart, "Beginning timestamp (RFC3339).")
@@ -95,6 +117,10 @@ func init() {
 	flag.UintVar(&interleavedGenerationGroups, "interleaved-generation-groups", 1, "The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 
 	flag.StringVar(&cpuProfile, "cpu-profile", "", "Write CPU profile to `file`")
+	flag.StringVar(&manifestFile, "manifest-file", "", "Write JSON dataset manifest to `file` (parameters, counts and content hash).")
+
+	flag.StringVar(&columnarOutputDir, "columnar-output-dir", ".", "Directory to write per-measurement files to (parquet and arrow-ipc formats only).")
+	flag.IntVar(&rowGroupSize, "row-group-size", columnar.DefaultRowGroupSize, "Rows per row group or record batch (parquet and arrow-ipc formats only).")
 
 	flag.Parse()
 
@@ -138,6 +164,22 @@ func init() {
 	devops.EpochDuration = samplingInterval
 	log.Printf("Using sampling interval %v\n", devops.EpochDuration)
 
+	if samplingJitter < 0 || samplingJitter >= samplingInterval {
+		log.Fatal("Invalid sampling jitter")
+	}
+	if samplingJitter > 0 {
+		log.Printf("Using sampling jitter %v\n", samplingJitter)
+	}
+
+	timestampPrecisionStr, err = common.FormatTimestampPrecision(format, timestampPrecisionStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	timestampPrecision, err = common.ParseTimestampPrecision(timestampPrecisionStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	if isFlagPassed("cardinality") == true {
 		scaleVar = cardinality / NHostSims
 	} else {
@@ -146,6 +188,15 @@ func init() {
 	log.Printf("Using cardinality of %v\n", cardinality)
 }
 
+// columnarDir returns the directory for per-measurement output files. Each
+// interleaved generation group gets its own subdirectory.
+func columnarDir() string {
+	if interleavedGenerationGroups > 1 {
+		return filepath.Join(columnarOutputDir, fmt.Sprintf("group_%d", interleavedGenerationGroupID))
+	}
+	return columnarOutputDir
+}
+
 func timeTrack(start time.Time, name string) {
 	elapsed := time.Since(start)
 	log.Printf("%s took %s", name, elapsed)
@@ -215,33 +266,31 @@ func main() {
 	}
 
 	var serializer common.Serializer
-	switch format {
-	case "influx-bulk":
-		serializer = common.NewSerializerInflux()
-	case "es-bulk":
-		serializer = common.NewSerializerElastic("5x")
-	case "es-bulk6x":
-		serializer = common.NewSerializerElastic("6x")
-	case "es-bulk7x":
-		serializer = common.NewSerializerElastic("7x")
-	case "cassandra":
-		serializer = common.NewSerializerCassandra()
-	case "mongo":
-		serializer = common.NewSerializerMongo()
-	case "opentsdb":
-		serializer = common.NewSerializerOpenTSDB()
-	case "timescaledb-sql":
-		serializer = common.NewSerializerTimescaleSql()
-	case "timescaledb-copyFrom":
-		serializer = common.NewSerializerTimescaleBin()
-	case "graphite-line":
-		serializer = common.NewSerializerGraphiteLine()
-	case "splunk-json":
-		serializer = common.NewSerializerSplunkJson()
-	default:
-		panic("unreachable")
+	var err error
+	if columnar.IsFormat(format) {
+		serializer, err = columnar.NewSerializerForFormat(format, columnarDir(), rowGroupSize)
+	} else {
+		serializer, err = common.NewSerializerForFormat(format, common.SerializerOptions{
+			TimestampPrecision: timestampPrecision,
+		})
+	}
+	if err != nil {
+		log.Fatal(err)
 	}
 
+	// w is what serializers write to, it also feeds the manifest content hash
+	// unless the output goes into columnar files
+	var w io.Writer = out
+	var manifest *common.ManifestBuilder
+	if manifestFile != "" {
+		manifest = common.NewManifestBuilder()
+		if !columnar.IsFormat(format) {
+			w = io.MultiWriter(out, manifest)
+		}
+	}
+
+	timestamps := common.NewTimestampAdjuster(timestampPrecision, samplingJitter, seed)
+
 	var currentInterleavedGroup uint = 0
 
 	t := time.Now()
@@ -249,14 +298,19 @@ func main() {
 	n := int64(0)
 	for !sim.Finished() {
 		sim.Next(point)
+		// jitter is applied to all points so that groups are consistent
+		timestamps.Adjust(point)
 		n++
 		// in the default case this is always true
 		if currentInterleavedGroup == interleavedGenerationGroupID {
 			//println("printing")
-			err := serializer.SerializePoint(out, point)
+			err := serializer.SerializePoint(w, point)
 			if err != nil {
 				log.Fatal(err)
 			}
+			if manifest != nil {
+				manifest.AddPoint(point)
+			}
 
 		}
 		point.Reset()
@@ -269,11 +323,35 @@ func main() {
 	if n != sim.SeenPoints() {
 		panic(fmt.Sprintf("Logic error, written %d points, generated %d points", n, sim.SeenPoints()))
 	}
-	serializer.SerializeSize(out, sim.SeenPoints(), sim.SeenValues())
-	err := out.Flush()
+	serializer.SerializeSize(w, sim.SeenPoints(), sim.SeenValues())
+	err = out.Flush()
 	dur := time.Now().Sub(t)
 	log.Printf("Written %d points, %d values, took %0f seconds\n", n, sim.SeenValues(), dur.Seconds())
 	if err != nil {
 		log.Fatal(err.Error())
 	}
+
+	if manifest != nil {
+		manifest.Manifest.Seed = seed
+		manifest.Manifest.UseCase = useCase
+		manifest.Manifest.ConfigFile = configFile
+		manifest.Manifest.ScaleVar = scaleVar
+		manifest.Manifest.ScaleVarOffset = scaleVarOffset
+		manifest.Manifest.SamplingInterval = samplingInterval.String()
+		manifest.Manifest.SamplingJitter = samplingJitter.String()
+		manifest.Manifest.TimestampPrecision = timestampPrecisionStr
+		manifest.Manifest.TimestampStart = timestampStart.Format(time.RFC3339)
+		manifest.Manifest.TimestampEnd = timestampEnd.Format(time.RFC3339)
+		manifest.Manifest.Format = format
+		manifest.Manifest.InterleavedGenerationGroupID = interleavedGenerationGroupID
+		manifest.Manifest.InterleavedGenerationGroups = interleavedGenerationGroups
+		m := manifest.Finish(sim.SeenPoints(), sim.SeenValues())
+		if columnar.IsFormat(format) {
+			m.ContentHash = ""
+		}
+		if err := m.WriteFile(manifestFile); err != nil {
+			log.Fatalf("cannot write manifest: %v", err)
+		}
+		log.Printf("Manifest written to %s\n", manifestFile)
+	}
 }
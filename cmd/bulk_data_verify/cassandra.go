@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// cassandraVerifier counts the rows of the table of every measurement, whose
+// time column holds nanoseconds. The values are not counted. Rows are keyed by
+// their primary key, so duplicated points overwrite each other. Only tables
+// keyed by the hostname and time hold a row per point, as a devops host has
+// a single series per measurement; the IoT tables are keyed by the home and
+// time, so the points of the rooms and sensors of a home collapse into one row
+// and their points are not counted.
+type cassandraVerifier struct {
+	session *gocql.Session
+}
+
+func newCassandraVerifier(daemonUrl string) (*cassandraVerifier, error) {
+	cluster := gocql.NewCluster(daemonUrl)
+	cluster.Keyspace = "measurements"
+	cluster.Timeout = 5 * time.Minute
+	cluster.Consistency = gocql.Quorum
+	cluster.ProtoVersion = 4
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return nil, err
+	}
+	return &cassandraVerifier{session: session}, nil
+}
+
+func (v *cassandraVerifier) Count(measurement string, start, end time.Time) (int64, int64, error) {
+	table := strings.ToLower(measurement)
+	key, err := v.primaryKey(table)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(key) == 0 {
+		// no table: nothing loaded
+		return 0, -1, nil
+	}
+	if strings.Join(key, ",") != "hostname,time" {
+		return 0, -1, &pointsNotCountedError{reason: fmt.Sprintf("table %s is keyed by %s, the points of several series share a row", table, strings.Join(key, ", "))}
+	}
+
+	var points int64
+	q := fmt.Sprintf("SELECT count(*) FROM %s WHERE time >= ? AND time < ? ALLOW FILTERING", table)
+	if err := v.session.Query(q, start.UnixNano(), end.UnixNano()).Scan(&points); err != nil {
+		return 0, 0, err
+	}
+	return points, -1, nil
+}
+
+// primaryKey returns the sorted primary key columns of a table, none if there
+// is no such table.
+func (v *cassandraVerifier) primaryKey(table string) ([]string, error) {
+	var key []string
+	var column, kind string
+	iter := v.session.Query("SELECT column_name, kind FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?", "measurements", table).Iter()
+	for iter.Scan(&column, &kind) {
+		if kind == "partition_key" || kind == "clustering" {
+			key = append(key, column)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	sort.Strings(key)
+	return key, nil
+}
+
+func (v *cassandraVerifier) Close() {
+	v.session.Close()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// elasticVerifier counts the documents of the index of every measurement,
+// whose timestamp field holds milliseconds. The values are not counted.
+type elasticVerifier struct {
+	client http.Client
+	url    string
+}
+
+// newElasticVerifier refreshes the indices first, to count the documents
+// loaded since the last periodic refresh too.
+func newElasticVerifier(daemonUrl string) (*elasticVerifier, error) {
+	v := &elasticVerifier{
+		client: http.Client{Timeout: 5 * time.Minute},
+		url:    daemonUrl,
+	}
+	if _, _, err := v.post("/_refresh", nil); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (v *elasticVerifier) post(path string, body []byte) (int, []byte, error) {
+	resp, err := v.client.Post(v.url+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return 0, nil, fmt.Errorf("%s response (status %d): %s", path, resp.StatusCode, respBody)
+	}
+	return resp.StatusCode, respBody, nil
+}
+
+func (v *elasticVerifier) Count(measurement string, start, end time.Time) (int64, int64, error) {
+	query := fmt.Sprintf(`{"query": {"range": {"timestamp": {"gte": %d, "lt": %d}}}}`, start.UnixNano()/1e6, end.UnixNano()/1e6)
+	status, body, err := v.post("/"+measurement+"/_count", []byte(query))
+	if err != nil {
+		return 0, 0, err
+	}
+	if status == http.StatusNotFound {
+		// no index: nothing loaded
+		return 0, -1, nil
+	}
+	var r struct {
+		Count int64 `json:"count"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return 0, 0, err
+	}
+	return r.Count, -1, nil
+}
+
+func (v *elasticVerifier) Close() {
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// influxVerifier counts with the InfluxQL count(*) of every field: the values
+// are their sum and the points their maximum.
+type influxVerifier struct {
+	client    http.Client
+	url       string
+	db        string
+	user      string
+	password  string
+	authToken string
+}
+
+func newInfluxVerifier(daemonUrl, db, user, password, authToken string) *influxVerifier {
+	return &influxVerifier{
+		client:    http.Client{Timeout: 5 * time.Minute},
+		url:       daemonUrl,
+		db:        db,
+		user:      user,
+		password:  password,
+		authToken: authToken,
+	}
+}
+
+type influxResponse struct {
+	Results []struct {
+		Series []struct {
+			Values [][]interface{} `json:"values"`
+		} `json:"series"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+func (v *influxVerifier) Count(measurement string, start, end time.Time) (int64, int64, error) {
+	q := fmt.Sprintf(`SELECT count(*) FROM "%s" WHERE time >= '%s' AND time < '%s'`, measurement, start.UTC().Format(time.RFC3339Nano), end.UTC().Format(time.RFC3339Nano))
+	params := url.Values{"db": {v.db}, "q": {q}}
+	if v.user != "" {
+		params.Set("u", v.user)
+		params.Set("p", v.password)
+	}
+	req, err := http.NewRequest("GET", v.url+"/query?"+params.Encode(), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	if v.authToken != "" {
+		req.Header.Set("Authorization", "Token "+v.authToken)
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("query response (status %d): %s", resp.StatusCode, body)
+	}
+	var r influxResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return 0, 0, err
+	}
+	if r.Error != "" {
+		return 0, 0, fmt.Errorf("query error: %s", r.Error)
+	}
+	var points, values int64
+	for _, result := range r.Results {
+		if result.Error != "" {
+			return 0, 0, fmt.Errorf("query error: %s", result.Error)
+		}
+		for _, s := range result.Series {
+			for _, row := range s.Values {
+				// the first column is the time
+				for _, c := range row[1:] {
+					n, ok := c.(float64)
+					if !ok {
+						continue
+					}
+					values += int64(n)
+					if int64(n) > points {
+						points = int64(n)
+					}
+				}
+			}
+		}
+	}
+	return points, values, nil
+}
+
+func (v *influxVerifier) Close() {
+}
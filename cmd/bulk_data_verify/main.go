@@ -0,0 +1,195 @@
+// bulk_data_verify checks that a database holds the data described by the
+// dataset manifests written by bulk_data_gen (-manifest-file). It counts the
+// points, and the values where the database can count them, of every
+// measurement within the time range of the dataset, compares them with the
+// counts of the manifests and reports the missing or duplicated data:
+//
+//	bulk_data_verify -db-type influx -url http://localhost:8086 -manifest data.manifest.json
+//
+// When the dataset was generated in interleaved groups, pass the manifests of
+// all the groups, comma separated.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/util/verify"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Verifier counts the data loaded in a database.
+type Verifier interface {
+	// Count returns the number of points of a measurement with timestamps
+	// in [start, end), and their number of values, -1 if not counted. It
+	// returns a *pointsNotCountedError if the points cannot be counted.
+	Count(measurement string, start, end time.Time) (points, values int64, err error)
+	Close()
+}
+
+// pointsNotCountedError is returned by Verifier.Count when the points of
+// a measurement cannot be counted, e.g. as the table keeps a single row for
+// the points of several series.
+type pointsNotCountedError struct {
+	reason string
+}
+
+func (e *pointsNotCountedError) Error() string {
+	return "points not counted: " + e.reason
+}
+
+// Program option vars:
+var (
+	dbType    string
+	daemonUrl string
+	dbName    string
+	user      string
+	password  string
+	authToken string
+	manifests string
+	startStr  string
+	endStr    string
+)
+
+var dbTypes = []string{"influx", "timescale", "es", "cassandra", "mongo"}
+
+// Parse args:
+func init() {
+	flag.StringVar(&dbType, "db-type", "", "Type of the database: "+strings.Join(dbTypes, ", ")+".")
+	flag.StringVar(&daemonUrl, "url", "", "Database URL (default depends on the database type).")
+	flag.StringVar(&dbName, "db", "benchmark_db", "Database name (influx, timescale, mongo).")
+	flag.StringVar(&user, "user", "", "Database user (influx, timescale).")
+	flag.StringVar(&password, "password", "", "Database password (influx, timescale).")
+	flag.StringVar(&authToken, "auth-token", "", "Authentication token (InfluxDB 2, using its 1.x query API).")
+	flag.StringVar(&manifests, "manifest", "", "Comma separated dataset manifests written by bulk_data_gen.")
+	flag.StringVar(&startStr, "start", "", "Start of the verified time range, RFC3339 (default from the manifest, minus the sampling jitter).")
+	flag.StringVar(&endStr, "end", "", "End of the verified time range, RFC3339 (default from the manifest, plus the sampling jitter).")
+
+	flag.Parse()
+
+	if manifests == "" {
+		log.Fatal("missing -manifest")
+	}
+}
+
+func main() {
+	expected, datasetPoints, start, end, err := verify.ReadManifests(strings.Split(manifests, ","))
+	if err != nil {
+		log.Fatalf("Error reading manifests: %v\n", err)
+	}
+	if startStr != "" {
+		start = parseTime(startStr)
+	}
+	if endStr != "" {
+		end = parseTime(endStr)
+	}
+
+	v, err := newVerifier()
+	if err != nil {
+		log.Fatalf("Error connecting to %s: %v\n", dbType, err)
+	}
+	defer v.Close()
+
+	names := make([]string, 0, len(expected))
+	maxLength := len("measurement")
+	for name := range expected {
+		names = append(names, name)
+		if len(name) > maxLength {
+			maxLength = len(name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Printf("verifying %d measurements in %s from %s to %s\n", len(names), dbType, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	// the total is of the measurements whose points were counted
+	var total verify.Counts
+	var manifestPoints int64
+	valuesCounted := true
+	failed := false
+	printRow := func(name string, c *verify.Counts) {
+		status := c.Compare()
+		if status != "ok" {
+			failed = true
+		}
+		if c.Note != "" {
+			status += " (" + c.Note + ")"
+		}
+		points, values := "-", "-"
+		if c.Points >= 0 {
+			points = fmt.Sprintf("%d", c.Points)
+		}
+		if c.Values >= 0 {
+			values = fmt.Sprintf("%d", c.Values)
+		}
+		fmt.Printf("%-*s  points: %12s of %12d  values: %12s of %12d  %s\n", maxLength, name, points, c.Expected.Points, values, c.Expected.Values, status)
+	}
+	for _, name := range names {
+		c := expected[name]
+		manifestPoints += c.Expected.Points
+		c.Points, c.Values, err = v.Count(name, start, end)
+		var notCounted *pointsNotCountedError
+		if errors.As(err, &notCounted) {
+			c.Points, c.Values, c.Note = -1, -1, err.Error()
+			printRow(name, c)
+			continue
+		}
+		if err != nil {
+			log.Fatalf("Error counting %s: %v\n", name, err)
+		}
+		printRow(name, c)
+		total.Expected.Points += c.Expected.Points
+		total.Expected.Values += c.Expected.Values
+		total.Points += c.Points
+		total.Values += c.Values
+		valuesCounted = valuesCounted && c.Values >= 0
+	}
+	if !valuesCounted {
+		total.Values = -1
+	}
+	printRow("total", &total)
+
+	if manifestPoints != datasetPoints {
+		fmt.Printf("warning: the manifests describe %d of the %d points of the dataset, the manifests of some interleaved groups are missing\n", manifestPoints, datasetPoints)
+	}
+	if failed {
+		fmt.Println("verification failed")
+		os.Exit(1)
+	}
+	fmt.Println("verification succeeded")
+}
+
+func parseTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		log.Fatalf("invalid time %s: %v\n", s, err)
+	}
+	return t
+}
+
+func newVerifier() (Verifier, error) {
+	switch dbType {
+	case "influx":
+		return newInfluxVerifier(defaultUrl("http://localhost:8086"), dbName, user, password, authToken), nil
+	case "timescale":
+		return newTimescaleVerifier(defaultUrl("localhost:5432"), dbName, user, password)
+	case "es":
+		return newElasticVerifier(defaultUrl("http://localhost:9200"))
+	case "cassandra":
+		return newCassandraVerifier(defaultUrl("localhost:9042"))
+	case "mongo":
+		return newMongoVerifier(defaultUrl("localhost:27017"), dbName)
+	}
+	log.Fatalf("invalid db type: %s (one of %s)", dbType, strings.Join(dbTypes, ", "))
+	return nil, nil
+}
+
+func defaultUrl(url string) string {
+	if daemonUrl != "" {
+		return daemonUrl
+	}
+	return url
+}
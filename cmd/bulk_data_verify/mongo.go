@@ -0,0 +1,56 @@
+package main
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// pointCollectionName is the collection bulk_load_mongo loads the points in.
+const pointCollectionName = "point_data"
+
+// mongoVerifier counts the documents of every measurement and their fields.
+type mongoVerifier struct {
+	session *mgo.Session
+	db      string
+}
+
+func newMongoVerifier(daemonUrl, db string) (*mongoVerifier, error) {
+	session, err := mgo.Dial(daemonUrl)
+	if err != nil {
+		return nil, err
+	}
+	session.SetSocketTimeout(5 * time.Minute)
+	return &mongoVerifier{session: session, db: db}, nil
+}
+
+func (v *mongoVerifier) Count(measurement string, start, end time.Time) (int64, int64, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"measurement":  measurement,
+			"timestamp_ns": bson.M{"$gte": start.UnixNano(), "$lt": end.UnixNano()},
+		}},
+		{"$group": bson.M{
+			"_id":    nil,
+			"points": bson.M{"$sum": 1},
+			"values": bson.M{"$sum": bson.M{"$size": "$fields"}},
+		}},
+	}
+	var result struct {
+		Points int64 `bson:"points"`
+		Values int64 `bson:"values"`
+	}
+	err := v.session.DB(v.db).C(pointCollectionName).Pipe(pipeline).One(&result)
+	if err == mgo.ErrNotFound {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return result.Points, result.Values, nil
+}
+
+func (v *mongoVerifier) Close() {
+	v.session.Close()
+}
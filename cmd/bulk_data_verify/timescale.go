@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+// timescaleVerifier counts the rows of the table of every measurement, whose
+// time column holds nanoseconds. The values are not counted.
+type timescaleVerifier struct {
+	conn *pgx.Conn
+}
+
+func newTimescaleVerifier(daemonUrl, db, user, password string) (*timescaleVerifier, error) {
+	host, portStr, err := net.SplitHostPort(daemonUrl)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, err
+	}
+	// the defaults of bulk_load_timescale
+	if user == "" {
+		user = "postgres"
+	}
+	if password == "" {
+		password = "password"
+	}
+	config, err := pgx.ParseConfig(fmt.Sprintf("host=%s port=%d user=%s password=%s database=%s", host, port, user, password, db))
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pgx.ConnectConfig(context.Background(), config)
+	if err != nil {
+		return nil, err
+	}
+	return &timescaleVerifier{conn: conn}, nil
+}
+
+func (v *timescaleVerifier) Count(measurement string, start, end time.Time) (int64, int64, error) {
+	var points int64
+	err := v.conn.QueryRow(context.Background(), fmt.Sprintf("SELECT count(*) FROM %s WHERE time >= $1 AND time < $2", pgx.Identifier{measurement}.Sanitize()), start.UnixNano(), end.UnixNano()).Scan(&points)
+	if pgErr, ok := err.(interface{ SQLState() string }); ok && pgErr.SQLState() == "42P01" {
+		// undefined table: nothing loaded
+		return 0, -1, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return points, -1, nil
+}
+
+func (v *timescaleVerifier) Close() {
+	v.conn.Close(context.Background())
+}
@@ -173,6 +173,8 @@ func (l *CassandraBulkLoad) RunScanner(r io.Reader, syncChanDone chan int) {
 	var n int
 	var err error
 	var totalPoints, totalValues int64
+	// items skipped on resume, not counted as read
+	var skipped int64
 
 	var deadline time.Time
 	if bulk_load.Runner.TimeLimit > 0 {
@@ -185,11 +187,16 @@ outer:
 		line := scanner.Text()
 		totalPoints, totalValues, err = common.CheckTotalValues(line)
 		if totalPoints > 0 || totalValues > 0 {
+			bulk_load.Runner.CheckDatasetSize(totalPoints, totalValues)
 			continue
 		}
 		if err != nil {
 			log.Fatal(err)
 		}
+		if bulk_load.Runner.DoLoad && bulk_load.Runner.SkipItem() {
+			skipped++
+			continue
+		}
 		l.itemsRead++
 		l.bytesRead += int64(len(scanner.Bytes()))
 
@@ -201,6 +208,7 @@ outer:
 
 		n++
 		if n >= bulk_load.Runner.BatchSize {
+			bulk_load.Runner.BatchSent(batch)
 			l.batchChan <- batch
 			batch = l.session.NewBatch(gocql.LoggedBatch)
 			n = 0
@@ -222,17 +230,18 @@ outer:
 
 	// Finished reading input, make sure last batch goes out.
 	if n > 0 {
+		bulk_load.Runner.BatchSent(batch)
 		l.batchChan <- batch
 	}
 
 	// Closing inputDone signals to the application that we've read everything and can now shut down.
 	close(l.inputDone)
 
-	l.valuesRead = totalValues
+	l.valuesRead = bulk_load.LoadedValues(totalValues, l.itemsRead, skipped)
 	//cassandra's schema stores each value separately, point is represented in series_id
-	if l.itemsRead != totalPoints {
+	if l.itemsRead+skipped != totalPoints {
 		if !bulk_load.Runner.HasEndedPrematurely() {
-			log.Fatalf("Incorrent number of read items: %d, expected: %d:", l.itemsRead, totalPoints)
+			log.Fatalf("Incorrent number of read items: %d, expected: %d:", l.itemsRead+skipped, totalPoints)
 		} else {
 			totalValues = int64(float64(l.itemsRead) * bulk_load.ValuesPerMeasurement) // needed for statistics summary
 		}
@@ -250,16 +259,40 @@ func (l *CassandraBulkLoad) processBatches(session *gocql.Session, waitGroup *sy
 		}
 
 		// Write the batch.
-		err := session.ExecuteBatch(batch)
+		err := bulk_load.Runner.WriteBatch(func() error {
+			return session.ExecuteBatch(batch)
+		}, retryable, func(dl io.Writer, _ error) error {
+			for _, entry := range batch.Entries {
+				if _, err := fmt.Fprintln(dl, entry.Stmt); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 		if err != nil {
 			rerr = fmt.Errorf("Error writing: %s\n", err.Error())
 			break
 		}
+		bulk_load.Runner.BatchWritten(batch)
 	}
 	waitGroup.Done()
 	return rerr
 }
 
+// retryable tells whether a batch which failed may succeed when retried: on
+// timeouts, overloaded or unavailable nodes and lost connections.
+func retryable(err error) bool {
+	if re, ok := err.(gocql.RequestError); ok {
+		switch re.Code() {
+		case gocql.ErrCodeServer, gocql.ErrCodeOverloaded, gocql.ErrCodeBootstrapping, gocql.ErrCodeUnavailable, gocql.ErrCodeWriteTimeout:
+			return true
+		}
+		return false
+	}
+	// errors of the driver: timeouts, no connections
+	return true
+}
+
 var tableOptionsFmt = "with compaction = {'class': 'TimeWindowCompactionStrategy', 'compaction_window_size': 1, 'compaction_window_unit': 'DAYS'} and compression = {'class':'%s'}"
 
 var createTablesCQLDevops = []string{
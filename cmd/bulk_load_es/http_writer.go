@@ -8,9 +8,23 @@ import (
 	//"net/url"
 	"time"
 
+	"github.com/influxdata/influxdb-comparisons/bulk_load"
 	"github.com/valyala/fasthttp"
 )
 
+// BulkItemsError is the error of a bulk request of which some documents were
+// rejected: Rejected holds the indices of their items among the Items of the
+// request. It is not retryable, the other documents were written.
+type BulkItemsError struct {
+	Items    int
+	Rejected []int
+	Message  string
+}
+
+func (e *BulkItemsError) Error() string {
+	return e.Message
+}
+
 // HTTPWriterConfig is the configuration used to create an HTTPWriter.
 type HTTPWriterConfig struct {
 	// URL of the host, in form "http://example.com:8086"
@@ -70,22 +84,35 @@ func (w *HTTPWriter) WriteLineProtocol(body []byte, isGzip bool) (int64, error)
 	if err == nil {
 		sc := resp.StatusCode()
 		if sc != 200 {
-			err = fmt.Errorf("Invalid write response (status %d): %s", sc, resp.Body())
+			err = &bulk_load.StatusError{StatusCode: sc, Message: fmt.Sprintf("Invalid write response (status %d): %s", sc, resp.Body())}
 		}
 	}
 
-	// anonymous type to get the 'errors' field from the response:
-	errorFlag := struct {
+	// anonymous type to get the 'errors' field and the item statuses from
+	// the response:
+	bulkResponse := struct {
 		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			Status int `json:"status"`
+		} `json:"items"`
 	}{}
 
 	if err == nil {
-		err = json.Unmarshal(resp.Body(), &errorFlag)
+		err = json.Unmarshal(resp.Body(), &bulkResponse)
 	}
 
 	if err == nil {
-		if errorFlag.Errors {
-			err = fmt.Errorf("Write response set the errors field to true (status 200): %s", resp.Body())
+		if bulkResponse.Errors {
+			// some documents were rejected, retrying would duplicate the others
+			itemsErr := &BulkItemsError{Items: len(bulkResponse.Items), Message: fmt.Sprintf("Write response set the errors field to true (status 200): %s", resp.Body())}
+			for i, item := range bulkResponse.Items {
+				for _, result := range item {
+					if result.Status < 200 || result.Status >= 300 {
+						itemsErr.Rejected = append(itemsErr.Rejected, i)
+					}
+				}
+			}
+			err = itemsErr
 		}
 	}
 
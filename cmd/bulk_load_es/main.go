@@ -439,6 +439,8 @@ func (l *ElasticBulkLoad) RunScanner(r io.Reader, syncChanDone chan int) {
 	var linesRead int64
 	var err error
 	var totalPoints, totalValues int64
+	// items skipped on resume, not counted as read
+	var skipped int64
 
 	var itemsThisBatch int
 	scanner := bufio.NewScanner(r)
@@ -452,6 +454,7 @@ outer:
 
 		totalPoints, totalValues, err = common.CheckTotalValues(scanner.Text())
 		if totalPoints > 0 || totalValues > 0 {
+			bulk_load.Runner.CheckDatasetSize(totalPoints, totalValues)
 			continue
 		}
 		if err != nil {
@@ -465,14 +468,21 @@ outer:
 
 		//n++
 		if linesRead%2 == 0 {
-			l.itemsRead++
-			itemsThisBatch++
+			if bulk_load.Runner.SkipItem() {
+				// skipped items come first, buf holds only theirs
+				buf.Reset()
+				skipped++
+			} else {
+				l.itemsRead++
+				itemsThisBatch++
+			}
 		}
 
 		hitLimit := bulk_load.Runner.ItemLimit >= 0 && l.itemsRead >= bulk_load.Runner.ItemLimit
 
-		if itemsThisBatch == bulk_load.Runner.BatchSize || hitLimit {
+		if itemsThisBatch == bulk_load.Runner.BatchSize || (hitLimit && itemsThisBatch > 0) {
 			l.bytesRead += int64(buf.Len())
+			bulk_load.Runner.BatchSent(buf)
 			l.batchChan <- buf
 			buf = l.bufPool.Get().(*bytes.Buffer)
 			itemsThisBatch = 0
@@ -498,6 +508,7 @@ outer:
 
 	// Finished reading input, make sure last batch goes out.
 	if itemsThisBatch > 0 {
+		bulk_load.Runner.BatchSent(buf)
 		l.batchChan <- buf
 	}
 
@@ -508,11 +519,11 @@ outer:
 	if linesRead%2 != 0 {
 		log.Fatalf("the number of lines read was not a multiple of 2, which indicates a bad bulk format for Elastic")
 	}
-	l.valuesRead = totalValues
+	l.valuesRead = bulk_load.LoadedValues(totalValues, l.itemsRead, skipped)
 
-	if l.itemsRead != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
+	if l.itemsRead+skipped != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
 		if !bulk_load.Runner.HasEndedPrematurely() {
-			log.Fatalf("Incorrent number of read items: %d, expected: %d:", l.itemsRead, totalPoints)
+			log.Fatalf("Incorrent number of read items: %d, expected: %d:", l.itemsRead+skipped, totalPoints)
 		} else {
 			totalValues = int64(float64(l.itemsRead) * bulk_load.ValuesPerMeasurement) // needed for statistics summary
 		}
@@ -528,30 +539,39 @@ func (l *ElasticBulkLoad) processBatches(w *HTTPWriter, workersGroup *sync.WaitG
 	for batch := range l.batchChan {
 		batchesSeen++
 		if !bulk_load.Runner.DoLoad {
+			// nothing to write, the batch counts as written
+			bulk_load.Runner.BatchWritten(batch)
+			batch.Reset()
+			l.bufPool.Put(batch)
 			continue
 		}
 
-		var err error
 		var bodySize int
 
 		// Write the batch.
-		if l.useGzip {
-			compressedBatch := l.bufPool.Get().(*bytes.Buffer)
-			fasthttp.WriteGzip(compressedBatch, batch.Bytes())
-			bodySize = len(compressedBatch.Bytes())
-			_, err = w.WriteLineProtocol(compressedBatch.Bytes(), true)
-			// Return the compressed batch buffer to the pool.
-			compressedBatch.Reset()
-			l.bufPool.Put(compressedBatch)
-		} else {
-			bodySize = len(batch.Bytes())
-			_, err = w.WriteLineProtocol(batch.Bytes(), false)
-		}
-
+		err := bulk_load.Runner.WriteBatch(func() error {
+			var err error
+			if l.useGzip {
+				compressedBatch := l.bufPool.Get().(*bytes.Buffer)
+				fasthttp.WriteGzip(compressedBatch, batch.Bytes())
+				bodySize = len(compressedBatch.Bytes())
+				_, err = w.WriteLineProtocol(compressedBatch.Bytes(), true)
+				// Return the compressed batch buffer to the pool.
+				compressedBatch.Reset()
+				l.bufPool.Put(compressedBatch)
+			} else {
+				bodySize = len(batch.Bytes())
+				_, err = w.WriteLineProtocol(batch.Bytes(), false)
+			}
+			return err
+		}, retryable, func(dl io.Writer, err error) error {
+			return writeDeadLetters(dl, batch.Bytes(), err)
+		})
 		if err != nil {
 			rerr = fmt.Errorf("Error writing: %s\n", err.Error())
 			break
 		}
+		bulk_load.Runner.BatchWritten(batch)
 
 		// Return the batch buffer to the pool.
 		batch.Reset()
@@ -574,6 +594,44 @@ func (l *ElasticBulkLoad) processBatches(w *HTTPWriter, workersGroup *sync.WaitG
 	return rerr
 }
 
+// retryable tells whether a batch which failed may succeed when retried. The
+// batches of which some documents were rejected are not, as the others were
+// written.
+func retryable(err error) bool {
+	if _, ok := err.(*BulkItemsError); ok {
+		return false
+	}
+	return bulk_load.IsRetryable(err)
+}
+
+// writeDeadLetters writes the items of a batch which were not written: the
+// rejected ones when the response tells them, all of them otherwise.
+func writeDeadLetters(w io.Writer, batch []byte, err error) error {
+	itemsErr, ok := err.(*BulkItemsError)
+	if !ok {
+		_, err := w.Write(batch)
+		return err
+	}
+	// the bulk format uses 2 lines per item: the action and the document
+	lines := bytes.SplitAfter(batch, []byte("\n"))
+	if len(lines) > 0 && len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) != 2*itemsErr.Items {
+		_, err := w.Write(batch)
+		return err
+	}
+	for _, i := range itemsErr.Rejected {
+		if _, err := w.Write(lines[2*i]); err != nil {
+			return err
+		}
+		if _, err := w.Write(lines[2*i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // createESTemplate uses a Go text/template to create an ElasticSearch index
 // template. (This terminological conflict is mostly unavoidable).
 func createESTemplate(daemonUrl, indexTemplateName string, indexTemplateBodyTemplate []byte, numberOfReplicas, numberOfShards uint) error {
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/influxdata/influxdb-comparisons/bulk_load"
 	"github.com/valyala/fasthttp"
 )
 
@@ -33,6 +34,10 @@ type HTTPWriterConfig struct {
 	// Name of the target database into which points will be written.
 	Database string
 
+	// Precision of written timestamps, as accepted by the write endpoint
+	// (n, u, ms, s). Empty means nanoseconds.
+	Precision string
+
 	BackingOffChan chan bool
 	BackingOffDone chan struct{}
 
@@ -50,6 +55,10 @@ type HTTPWriter struct {
 
 // NewHTTPWriter returns a new HTTPWriter from the supplied HTTPWriterConfig.
 func NewHTTPWriter(c HTTPWriterConfig, consistency string) *HTTPWriter {
+	u := c.Host + "/write?consistency=" + consistency + "&db=" + url.QueryEscape(c.Database)
+	if c.Precision != "" {
+		u += "&precision=" + c.Precision
+	}
 	return &HTTPWriter{
 		client: fasthttp.Client{
 			Name: "bulk_load_influx",
@@ -57,7 +66,7 @@ func NewHTTPWriter(c HTTPWriterConfig, consistency string) *HTTPWriter {
 		},
 
 		c:   c,
-		url: []byte(c.Host + "/write?consistency=" + consistency + "&db=" + url.QueryEscape(c.Database)),
+		url: []byte(u),
 	}
 }
 
@@ -89,7 +98,7 @@ func (w *HTTPWriter) WriteLineProtocol(body []byte, isGzip bool) (int64, error)
 			err = BackoffError
 			log.Printf("backoff suggested, reason: %s", resp.Body())
 		} else if sc != fasthttp.StatusNoContent {
-			err = fmt.Errorf("[DebugInfo: %s] Invalid write response (status %d): %s", w.c.DebugInfo, sc, resp.Body())
+			err = &bulk_load.StatusError{StatusCode: sc, Message: fmt.Sprintf("[DebugInfo: %s] Invalid write response (status %d): %s", w.c.DebugInfo, sc, resp.Body())}
 		}
 	}
 
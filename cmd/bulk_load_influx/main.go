@@ -42,6 +42,7 @@ type InfluxBulkLoad struct {
 	backoffTimeOut    time.Duration
 	useGzip           bool
 	consistency       string
+	precision         string
 	clientIndex       int
 	//runtime vars
 	bufPool               sync.Pool
@@ -66,6 +67,15 @@ var consistencyChoices = map[string]struct{}{
 	"all":    {},
 }
 
+// precisionChoices maps bulk_data_gen timestamp precisions to the values of
+// the write endpoint precision parameter.
+var precisionChoices = map[string]string{
+	"ns": "n",
+	"us": "u",
+	"ms": "ms",
+	"s":  "s",
+}
+
 type batch struct {
 	Buffer *bytes.Buffer
 	Items  int
@@ -102,6 +112,7 @@ func (l *InfluxBulkLoad) Init() {
 	flag.StringVar(&l.csvDaemonUrls, "urls", "http://localhost:8086", "InfluxDB URLs, comma-separated. Will be used in a round-robin fashion.")
 	flag.IntVar(&l.replicationFactor, "replication-factor", 1, "Cluster replication factor (only applies to clustered databases).")
 	flag.StringVar(&l.consistency, "consistency", "one", "Write consistency. Must be one of: any, one, quorum, all.")
+	flag.StringVar(&l.precision, "precision", "ns", "Timestamp precision of the input, must match bulk_data_gen -timestamp-precision. Must be one of: ns, us, ms, s.")
 	flag.DurationVar(&l.backoff, "backoff", time.Second, "Time to sleep between requests when server indicates backpressure is needed.")
 	flag.DurationVar(&l.backoffTimeOut, "backoff-timeout", time.Minute*30, "Maximum time to spent when dealing with backoff messages in one shot")
 	flag.BoolVar(&l.useGzip, "gzip", true, "Whether to gzip encode requests (default true).")
@@ -113,12 +124,18 @@ func (l *InfluxBulkLoad) Validate() {
 	if _, ok := consistencyChoices[l.consistency]; !ok {
 		log.Fatalf("invalid consistency settings")
 	}
+	if _, ok := precisionChoices[l.precision]; !ok {
+		log.Fatalf("invalid precision settings")
+	}
 
 	l.daemonUrls = strings.Split(l.csvDaemonUrls, ",")
 	if len(l.daemonUrls) == 0 {
 		log.Fatal("missing 'urls' flag")
 	}
 	fmt.Printf("daemon URLs: %v\n", l.daemonUrls)
+	if shard := bulk_load.Runner.Shard(); shard != nil {
+		l.clientIndex = shard.ClientIndex
+	}
 
 	if l.ingestRateLimit > 0 {
 		l.ingestionRateGran = (float64(l.ingestRateLimit) / float64(bulk_load.Runner.Workers)) / (float64(1000) / float64(RateControlGranularity))
@@ -223,6 +240,7 @@ func (l *InfluxBulkLoad) PrepareProcess(i int) {
 		DebugInfo:      fmt.Sprintf("worker #%d, dest url: %s", i, l.configs[i].url),
 		Host:           l.configs[i].url,
 		Database:       bulk_load.Runner.DbName,
+		Precision:      precisionChoices[l.precision],
 		BackingOffChan: l.configs[i].backingOffChan,
 		BackingOffDone: l.configs[i].backingOffDone,
 	}, l.consistency)
@@ -239,6 +257,7 @@ func (l *InfluxBulkLoad) UpdateReport(params *report.LoadReportParams) (reportTa
 
 	reportTags = [][2]string{{"back_off", strconv.Itoa(int(l.backoff.Seconds()))}}
 	reportTags = append(reportTags, [2]string{"consistency", l.consistency})
+	reportTags = append(reportTags, [2]string{"precision", l.precision})
 
 	extraVals = make([]report.ExtraVal, 0)
 
@@ -278,6 +297,8 @@ func (l *InfluxBulkLoad) RunScanner(r io.Reader, syncChanDone chan int) {
 
 	var n, values int
 	var totalPoints, totalValues, totalValuesCounted int64
+	// items and values skipped on resume, not counted as read
+	var skipped, skippedValues int64
 
 	newline := []byte("\n")
 	var deadline time.Time
@@ -297,6 +318,7 @@ outer:
 		line := scanner.Text()
 		totalPoints, totalValues, err = common.CheckTotalValues(line)
 		if totalPoints > 0 || totalValues > 0 {
+			bulk_load.Runner.CheckDatasetSize(totalPoints, totalValues)
 			continue
 		} else {
 			fieldCnt := countFields(line)
@@ -306,6 +328,13 @@ outer:
 		if err != nil {
 			log.Fatal(err)
 		}
+		if bulk_load.Runner.SkipItem() {
+			// skipped items come first, values counts only theirs
+			skipped++
+			skippedValues += int64(values)
+			values = 0
+			continue
+		}
 		l.itemsRead++
 		batchItemCount++
 
@@ -318,6 +347,7 @@ outer:
 			batchItemCount = 0
 
 			l.bytesRead += int64(buf.Len())
+			bulk_load.Runner.BatchSent(buf)
 			l.batchChan <- batch{buf, n, values}
 			buf = l.bufPool.Get().(*bytes.Buffer)
 			n = 0
@@ -355,19 +385,20 @@ outer:
 
 	// Finished reading input, make sure last batch goes out.
 	if n > 0 {
+		bulk_load.Runner.BatchSent(buf)
 		l.batchChan <- batch{buf, n, values}
 	}
 
 	// Closing inputDone signals to the application that we've read everything and can now shut down.
 	close(l.inputDone)
 
-	l.valuesRead = totalValues
+	l.valuesRead = totalValues - skippedValues
 	if totalValues == 0 {
-		l.valuesRead = totalValuesCounted
+		l.valuesRead = totalValuesCounted - skippedValues
 	}
-	if l.itemsRead != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
+	if l.itemsRead+skipped != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
 		if !bulk_load.Runner.HasEndedPrematurely() {
-			log.Fatalf("Incorrent number of read points: %d, expected: %d:", l.itemsRead, totalPoints)
+			log.Fatalf("Incorrent number of read points: %d, expected: %d:", l.itemsRead+skipped, totalPoints)
 		}
 	}
 	l.scanFinished = true
@@ -393,59 +424,67 @@ func (l *InfluxBulkLoad) processBatches(w *HTTPWriter, backoffSrc chan bool, tel
 			gvStart = time.Now()
 		}
 
-		// Write the batch: try until backoff is not needed.
+		// Write the batch: try until backoff is not needed, then retry the
+		// failures according to the retry policy.
 		if bulk_load.Runner.DoLoad {
-			var err error
-			sleepTime := l.backoff
-			timeStart := time.Now()
-			for {
-				if l.useGzip {
-					compressedBatch := l.bufPool.Get().(*bytes.Buffer)
-					fasthttp.WriteGzip(compressedBatch, batch.Buffer.Bytes())
-					//bodySize = len(compressedBatch.Bytes())
-					_, err = w.WriteLineProtocol(compressedBatch.Bytes(), true)
-					// Return the compressed batch buffer to the pool.
-					compressedBatch.Reset()
-					l.bufPool.Put(compressedBatch)
-				} else {
-					//bodySize = len(batch.Bytes())
-					_, err = w.WriteLineProtocol(batch.Buffer.Bytes(), false)
-				}
+			err := bulk_load.Runner.WriteBatch(func() error {
+				var err error
+				sleepTime := l.backoff
+				timeStart := time.Now()
+				for {
+					if l.useGzip {
+						compressedBatch := l.bufPool.Get().(*bytes.Buffer)
+						fasthttp.WriteGzip(compressedBatch, batch.Buffer.Bytes())
+						//bodySize = len(compressedBatch.Bytes())
+						_, err = w.WriteLineProtocol(compressedBatch.Bytes(), true)
+						// Return the compressed batch buffer to the pool.
+						compressedBatch.Reset()
+						l.bufPool.Put(compressedBatch)
+					} else {
+						//bodySize = len(batch.Bytes())
+						_, err = w.WriteLineProtocol(batch.Buffer.Bytes(), false)
+					}
 
-				if err == BackoffError {
-					backoffSrc <- true
-					// Report telemetry, if applicable:
-					if telemetrySink != nil {
-						p := report.GetPointFromGlobalPool()
-						p.Init("benchmarks_telemetry", ts)
-						for _, tagpair := range reportTags {
-							p.AddTag(tagpair[0], tagpair[1])
+					if err == BackoffError {
+						backoffSrc <- true
+						// Report telemetry, if applicable:
+						if telemetrySink != nil {
+							p := report.GetPointFromGlobalPool()
+							p.Init("benchmarks_telemetry", ts)
+							for _, tagpair := range reportTags {
+								p.AddTag(tagpair[0], tagpair[1])
+							}
+							p.AddTag("client_type", "load")
+							p.AddTag("worker", telemetryWorkerLabel)
+							p.AddBoolField("backoff", true)
+							telemetrySink <- p
 						}
-						p.AddTag("client_type", "load")
-						p.AddTag("worker", telemetryWorkerLabel)
-						p.AddBoolField("backoff", true)
-						telemetrySink <- p
-					}
-					time.Sleep(sleepTime)
-					sleepTime += l.backoff        // sleep longer if backpressure comes again
-					if sleepTime > 10*l.backoff { // but not longer than 10x default backoff time
-						log.Printf("[worker %s] sleeping on backoff response way too long (10x %v)", telemetryWorkerLabel, l.backoff)
-						sleepTime = 10 * l.backoff
-					}
-					checkTime := time.Now()
-					if timeStart.Add(l.backoffTimeOut).Before(checkTime) {
-						log.Printf("[worker %s] Spent too much time in backoff: %ds\n", telemetryWorkerLabel, int64(checkTime.Sub(timeStart).Seconds()))
+						time.Sleep(sleepTime)
+						sleepTime += l.backoff        // sleep longer if backpressure comes again
+						if sleepTime > 10*l.backoff { // but not longer than 10x default backoff time
+							log.Printf("[worker %s] sleeping on backoff response way too long (10x %v)", telemetryWorkerLabel, l.backoff)
+							sleepTime = 10 * l.backoff
+						}
+						checkTime := time.Now()
+						if timeStart.Add(l.backoffTimeOut).Before(checkTime) {
+							log.Printf("[worker %s] Spent too much time in backoff: %ds\n", telemetryWorkerLabel, int64(checkTime.Sub(timeStart).Seconds()))
+							break
+						}
+					} else {
+						backoffSrc <- false
 						break
 					}
-				} else {
-					backoffSrc <- false
-					break
 				}
-			}
+				return err
+			}, retryable, func(dl io.Writer, _ error) error {
+				_, err := dl.Write(batch.Buffer.Bytes())
+				return err
+			})
 			if err != nil {
 				return fmt.Errorf("Error writing: %s\n", err.Error())
 			}
 		}
+		bulk_load.Runner.BatchWritten(batch.Buffer)
 
 		// lagMillis intentionally includes backoff time,
 		// and incidentally includes compression time:
@@ -496,6 +535,16 @@ func (l *InfluxBulkLoad) processBatches(w *HTTPWriter, backoffSrc chan bool, tel
 	return nil
 }
 
+// retryable tells whether a batch which failed may succeed when retried. A
+// BackoffError is not: it is returned only once the backoff timeout is
+// exhausted.
+func retryable(err error) bool {
+	if err == BackoffError {
+		return false
+	}
+	return bulk_load.IsRetryable(err)
+}
+
 func processBackoffMessages(workerId int, src chan bool, dst chan struct{}) float64 {
 	var totalBackoffSecs float64
 	var start time.Time
@@ -80,6 +80,9 @@ func (l *MongoBulkLoad) Init() {
 }
 
 func (l *MongoBulkLoad) Validate() {
+	if bulk_load.Runner.HasManifest() {
+		log.Fatal("-manifest is not supported, the mongo format has no dataset size marker to check")
+	}
 	if l.documentFormat == mongodb.SimpleArraysFormat {
 		log.Printf("Using '%s' document serialization", l.documentFormat)
 	}
@@ -228,13 +231,19 @@ outer:
 			panic(fmt.Sprintf("reader/writer logic error, %d != %d", n, len(itemBuf)))
 		}
 
-		*batch = append(*batch, itemBuf)
-
+		// items skipped on resume are not counted as read
+		if bulk_load.Runner.SkipItem() {
+			l.bufPool.Put(itemBuf)
+			continue
+		}
 		l.itemsRead++
+
+		*batch = append(*batch, itemBuf)
 		n++
 
 		if n >= bulk_load.Runner.BatchSize {
 			l.bytesRead += int64(len(itemBuf))
+			bulk_load.Runner.BatchSent(batch)
 			l.batchChan <- batch
 			n = 0
 			batch = l.batchPool.Get().(*Batch)
@@ -253,6 +262,12 @@ outer:
 		}
 	}
 
+	// Finished reading input, make sure last batch goes out.
+	if n > 0 {
+		bulk_load.Runner.BatchSent(batch)
+		l.batchChan <- batch
+	}
+
 	// Closing inputDone signals to the application that we've read everything and can now shut down.
 	close(l.inputDone)
 	l.scanFinished = true
@@ -293,8 +308,6 @@ func (l *MongoBulkLoad) RunProcess(i int, workersGroup *sync.WaitGroup, telemetr
 	destField := &mongo_serialization.Field{}
 	collection := db.C(pointCollectionName)
 	for batch := range l.batchChan {
-		bulk := collection.Bulk()
-
 		if cap(pvs) < len(*batch) {
 			pvs = make([]interface{}, len(*batch))
 		}
@@ -360,16 +373,34 @@ func (l *MongoBulkLoad) RunProcess(i int, workersGroup *sync.WaitGroup, telemetr
 			pvs[i] = x
 			workerValuesRead += int64(fieldLength)
 		}
-		bulk.Insert(pvs...)
 
 		if bulk_load.Runner.DoLoad {
-			_, err := bulk.Run()
+			err := bulk_load.Runner.WriteBatch(func() error {
+				bulk := collection.Bulk()
+				bulk.Insert(pvs...)
+				_, err := bulk.Run()
+				return err
+			}, bulk_load.IsNetError, func(dl io.Writer, _ error) error {
+				// in the input framing format
+				lenBuf := make([]byte, 8)
+				for _, itemBuf := range *batch {
+					binary.LittleEndian.PutUint64(lenBuf, uint64(len(itemBuf)))
+					if _, err := dl.Write(lenBuf); err != nil {
+						return err
+					}
+					if _, err := dl.Write(itemBuf); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
 			if err != nil {
 				rerr = fmt.Errorf("Bulk err: %s\n", err.Error())
 				break
 			}
 
 		}
+		bulk_load.Runner.BatchWritten(batch)
 
 		// cleanup pvs
 		for _, x := range pvs {
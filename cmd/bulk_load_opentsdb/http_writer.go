@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/influxdata/influxdb-comparisons/bulk_load"
 	"github.com/valyala/fasthttp"
 )
 
@@ -74,7 +75,7 @@ func (w *HTTPWriter) WriteLineProtocol(body []byte) (int64, error) {
 		//if sc == 500 && backpressurePred(resp.Body()) {
 		//	err = BackoffError
 		if (sc != fasthttp.StatusNoContent && sc != fasthttp.StatusOK) {
-			err = fmt.Errorf("Invalid write response (status %d): %s", sc, resp.Body())
+			err = &bulk_load.StatusError{StatusCode: sc, Message: fmt.Sprintf("Invalid write response (status %d): %s", sc, resp.Body())}
 		}
 	}
 
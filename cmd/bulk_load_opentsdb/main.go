@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_load"
 	"io"
+	"io/ioutil"
 	"log"
 	"strings"
 	"sync"
@@ -62,6 +63,9 @@ func (l *OpenTsdbBulkLoad) Init() {
 }
 
 func (l *OpenTsdbBulkLoad) Validate() {
+	if bulk_load.Runner.HasManifest() {
+		log.Fatal("-manifest is not supported, the opentsdb format has no dataset size marker to check")
+	}
 	l.daemonUrls = strings.Split(l.csvDaemonUrls, ",")
 	if len(l.daemonUrls) == 0 {
 		log.Fatal("missing 'urls' flag")
@@ -183,6 +187,10 @@ func (l *OpenTsdbBulkLoad) RunScanner(r io.Reader, syncChanDone chan int) {
 	}
 outer:
 	for scanner.Scan() {
+		// items skipped on resume are not counted as read
+		if bulk_load.Runner.SkipItem() {
+			continue
+		}
 		l.itemsRead++
 		if n > 0 {
 			zw.Write(commaspace)
@@ -197,6 +205,7 @@ outer:
 			zw.Write(closebracket)
 			zw.Close()
 
+			bulk_load.Runner.BatchSent(buf)
 			l.batchChan <- buf
 
 			buf = l.bufPool.Get().(*bytes.Buffer)
@@ -226,6 +235,7 @@ outer:
 		zw.Write(newline)
 		zw.Write(closebracket)
 		zw.Close()
+		bulk_load.Runner.BatchSent(buf)
 		l.batchChan <- buf
 	}
 
@@ -234,27 +244,62 @@ outer:
 	l.scanFinished = true
 }
 
+// writeBatchLines writes the input lines of a gzipped batch, without the
+// JSON array framing added by the scanner, so that dead letters can be
+// loaded again.
+func writeBatchLines(w io.Writer, batch []byte) error {
+	zr, err := gzip.NewReader(bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return err
+	}
+	body = bytes.TrimPrefix(body, []byte("[\n"))
+	body = bytes.TrimSuffix(body, []byte("\n]"))
+	for _, line := range bytes.Split(body, []byte(", \n")) {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte("\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // processBatches reads byte buffers from batchChan and writes them to the target server, while tracking stats on the write.
 func (l *OpenTsdbBulkLoad) processBatches(w LineProtocolWriter, workersGroup *sync.WaitGroup) error {
 	var rerr error
 	for batch := range l.batchChan {
-		// Write the batch: try until backoff is not needed.
+		// Write the batch: try until backoff is not needed, then retry the
+		// failures according to the retry policy.
+		var err error
 		if bulk_load.Runner.DoLoad {
-			var err error
-			for {
-				_, err = w.WriteLineProtocol(batch.Bytes())
-				if err == BackoffError {
-					l.backingOffChan <- true
-					time.Sleep(l.backoff)
-				} else {
-					l.backingOffChan <- false
-					break
+			err = bulk_load.Runner.WriteBatch(func() error {
+				var err error
+				for {
+					_, err = w.WriteLineProtocol(batch.Bytes())
+					if err == BackoffError {
+						l.backingOffChan <- true
+						time.Sleep(l.backoff)
+					} else {
+						l.backingOffChan <- false
+						break
+					}
 				}
-			}
+				return err
+			}, bulk_load.IsRetryable, func(dl io.Writer, _ error) error {
+				return writeBatchLines(dl, batch.Bytes())
+			})
 			if err != nil {
 				rerr = fmt.Errorf("Error writing: %s\n", err.Error())
 			}
 		}
+		if err == nil {
+			bulk_load.Runner.BatchWritten(batch)
+		}
 		//fmt.Println(string(batch.Bytes()))
 
 		// Return the batch buffer to the pool.
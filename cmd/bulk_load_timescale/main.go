@@ -223,6 +223,8 @@ func (l *TimescaleBulkLoad) scan(reader io.Reader, syncChanDone chan int) {
 	var n int
 	var totalPoints, totalValues int64
 	var err error
+	// items skipped on resume, not counted as read
+	var skipped int64
 
 	l.scanFinished = false
 	l.itemsRead = 0
@@ -241,11 +243,16 @@ outer:
 	for scanner.Scan() {
 		totalPoints, totalValues, err = common.CheckTotalValues(scanner.Text())
 		if totalPoints > 0 || totalValues > 0 {
+			bulk_load.Runner.CheckDatasetSize(totalPoints, totalValues)
 			continue
 		}
 		if err != nil {
 			log.Fatal(err)
 		}
+		if bulk_load.Runner.SkipItem() {
+			skipped++
+			continue
+		}
 		l.itemsRead++
 
 		buff.Write(scanner.Bytes())
@@ -254,6 +261,7 @@ outer:
 		n++
 		if n >= bulk_load.Runner.BatchSize {
 			l.bytesRead += int64(buff.Len())
+			bulk_load.Runner.BatchSent(buff)
 			l.batchChan <- buff
 			buff = l.bufPool.Get().(*bytes.Buffer)
 			n = 0
@@ -275,16 +283,17 @@ outer:
 
 	// Finished reading input, make sure last batch goes out.
 	if n > 0 {
+		bulk_load.Runner.BatchSent(buff)
 		l.batchChan <- buff
 	}
 
 	// Closing inputDone signals to the application that we've read everything and can now shut down.
 	close(l.inputDone)
 
-	l.valuesRead = totalValues
-	if l.itemsRead != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
+	l.valuesRead = bulk_load.LoadedValues(totalValues, l.itemsRead, skipped)
+	if l.itemsRead+skipped != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
 		if !bulk_load.Runner.HasEndedPrematurely() {
-			log.Fatalf("Incorrent number of read points: %d, expected: %d:", l.itemsRead, totalPoints)
+			log.Fatalf("Incorrent number of read points: %d, expected: %d:", l.itemsRead+skipped, totalPoints)
 		} else {
 			totalValues = int64(float64(l.itemsRead) * bulk_load.ValuesPerMeasurement) // needed for statistics summary
 		}
@@ -298,6 +307,8 @@ func (l *TimescaleBulkLoad) scanBatch(reader io.Reader, syncChanDone chan int) {
 	var n int
 	var err error
 	var totalPoints, totalValues int64
+	// items skipped on resume, not counted as read
+	var skipped int64
 	l.scanFinished = false
 	l.itemsRead = 0
 	l.bytesRead = 0
@@ -315,17 +326,23 @@ outer:
 
 		totalPoints, totalValues, err = common.CheckTotalValues(line)
 		if totalPoints > 0 || totalValues > 0 {
+			bulk_load.Runner.CheckDatasetSize(totalPoints, totalValues)
 			continue
 		}
 		if err != nil {
 			log.Fatal(err)
 		}
 
+		if bulk_load.Runner.SkipItem() {
+			skipped++
+			continue
+		}
 		l.itemsRead++
 		buff = append(buff, line)
 		l.bytesRead += int64(len(line))
 		n++
 		if n >= bulk_load.Runner.BatchSize {
+			bulk_load.Runner.BatchSent(&buff[0])
 			l.batchChanBatch <- buff
 			buff = make([]string, 0, bulk_load.Runner.BatchSize)
 			n = 0
@@ -347,20 +364,21 @@ outer:
 
 	// Finished reading input, make sure last batch goes out.
 	if n > 0 {
+		bulk_load.Runner.BatchSent(&buff[0])
 		l.batchChanBatch <- buff
 	}
 
 	// Closing inputDone signals to the application that we've read everything and can now shut down.
 	close(l.inputDone)
 
-	if l.itemsRead != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
+	if l.itemsRead+skipped != totalPoints { // totalPoints is unknown (0) when exiting prematurely due to time limit
 		if !bulk_load.Runner.HasEndedPrematurely() {
-			log.Fatalf("Incorrent number of read points: %d, expected: %d:", l.itemsRead, totalPoints)
+			log.Fatalf("Incorrent number of read points: %d, expected: %d:", l.itemsRead+skipped, totalPoints)
 		} else {
 			totalValues = int64(float64(l.itemsRead) * bulk_load.ValuesPerMeasurement) // needed for statistics summary
 		}
 	}
-	l.valuesRead = totalValues
+	l.valuesRead = bulk_load.LoadedValues(totalValues, l.itemsRead, skipped)
 	l.scanFinished = true
 }
 
@@ -441,6 +459,15 @@ outer:
 				log.Fatalf("invalid type of %d item: %d", l.itemsRead, f.Type)
 			}
 		}
+		// items loaded before the checkpoint come first, nothing is buffered
+		// yet, they are not counted as read
+		if bulk_load.Runner.SkipItem() {
+			lastMeasurement = p.MeasurementName
+			p = FlatPoint{}
+			tsfp = timescale_serialization.FlatPoint{}
+			continue
+		}
+
 		l.valuesRead += int64(len(tsfp.Values))
 
 		//log.Printf("Decoded %d point\n",itemsRead+1)
@@ -451,6 +478,7 @@ outer:
 			n++
 		}
 		if n > 0 && (n >= bulk_load.Runner.BatchSize || newMeasurement) {
+			bulk_load.Runner.BatchSent(&buff[0])
 			l.batchChanBin <- buff
 			n = 0
 			buff = nil
@@ -481,6 +509,7 @@ outer:
 
 	// Finished reading input, make sure last batch goes out.
 	if n > 0 {
+		bulk_load.Runner.BatchSent(&buff[0])
 		l.batchChanBin <- buff
 		buff = nil
 	}
@@ -499,11 +528,18 @@ func (l *TimescaleBulkLoad) processBatches(conn *pgx.Conn, workersGroup *sync.Wa
 		}
 
 		// Write the batch.
-		_, err := conn.Exec(context.Background(), string(batch.Bytes()))
+		err := bulk_load.Runner.WriteBatch(func() error {
+			_, err := conn.Exec(context.Background(), string(batch.Bytes()))
+			return err
+		}, retryable, func(dl io.Writer, _ error) error {
+			_, err := dl.Write(batch.Bytes())
+			return err
+		})
 		if err != nil {
 			rerr = fmt.Errorf("Error writing: %s\n", err.Error())
 			break
 		}
+		bulk_load.Runner.BatchWritten(batch)
 
 		// Return the batch buffer to the pool.
 		batch.Reset()
@@ -528,16 +564,85 @@ func (l *TimescaleBulkLoad) processBatchesBatch(conn *pgx.Conn, workersGroup *sy
 			sqlBatch.Queue(line, nil, nil, nil)
 		}
 
-		sqlBatchResults := l.pool.SendBatch(context.Background(), &sqlBatch)
-		if err := sqlBatchResults.Close(); err != nil {
-			log.Fatalf("failed to close a batch operation %v", err)
+		err := bulk_load.Runner.WriteBatch(func() error {
+			return l.pool.SendBatch(context.Background(), &sqlBatch).Close()
+		}, retryable, func(dl io.Writer, _ error) error {
+			for _, line := range batch {
+				if _, err := fmt.Fprintln(dl, line); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			rerr = fmt.Errorf("Error writing: %s\n", err.Error())
+			break
 		}
+		bulk_load.Runner.BatchWritten(&batch[0])
 		batches++
 	}
 	workersGroup.Done()
 	return rerr
 }
 
+// retryable tells whether a batch which failed may succeed when retried: on
+// lost connections and on the SQL states of the classes connection exception
+// (08), transaction rollback (40), insufficient resources (53) and operator
+// intervention (57).
+func retryable(err error) bool {
+	if pgErr, ok := err.(interface{ SQLState() string }); ok {
+		state := pgErr.SQLState()
+		if len(state) < 2 {
+			return false
+		}
+		switch state[:2] {
+		case "08", "40", "53", "57":
+			return true
+		}
+		return false
+	}
+	return bulk_load.IsNetError(err)
+}
+
+// writeFlatPoints writes points in the input format of timescaledb-copyFrom.
+func writeFlatPoints(w io.Writer, points []FlatPoint) error {
+	for _, p := range points {
+		tsfp := timescale_serialization.FlatPoint{
+			MeasurementName: p.MeasurementName,
+			Columns:         p.Columns,
+			Values:          make([]*timescale_serialization.FlatPoint_FlatPointValue, len(p.Values)),
+		}
+		for i, v := range p.Values {
+			fv := &timescale_serialization.FlatPoint_FlatPointValue{}
+			switch v := v.(type) {
+			case float64:
+				fv.Type = timescale_serialization.FlatPoint_FLOAT
+				fv.DoubleVal = v
+			case int64:
+				fv.Type = timescale_serialization.FlatPoint_INTEGER
+				fv.IntVal = v
+			case string:
+				fv.Type = timescale_serialization.FlatPoint_STRING
+				fv.StringVal = v
+			default:
+				return fmt.Errorf("invalid value type %T", v)
+			}
+			tsfp.Values[i] = fv
+		}
+		out, err := tsfp.Marshal()
+		if err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint64(len(out))); err != nil {
+			return err
+		}
+		if _, err := w.Write(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CopyFromPoint is implementation of the interface CopyFromSource  used by *Conn.CopyFrom as the source for copy data.
 // It wraps arrays of FlatPoints
 type CopyFromPoint struct {
@@ -583,17 +688,23 @@ func (l *TimescaleBulkLoad) processBatchesBin(conn *pgx.Conn, workersGroup *sync
 		}
 		//log.Printf("CopyFrom %d of %s\n", n, batch[0].MeasurementName)
 		// Write the batch.
-		c := NewCopyFromPoint(batch)
-		rows, err := conn.CopyFrom(context.Background(), pgx.Identifier{batch[0].MeasurementName}, batch[0].Columns, c)
+		var c *CopyFromPoint
+		err := bulk_load.Runner.WriteBatch(func() error {
+			c = NewCopyFromPoint(batch)
+			rows, err := conn.CopyFrom(context.Background(), pgx.Identifier{batch[0].MeasurementName}, batch[0].Columns, c)
+			if err == nil && rows != int64(len(batch)) {
+				err = fmt.Errorf("Written only %d rows of %d", rows, len(batch))
+			}
+			return err
+		}, retryable, func(dl io.Writer, _ error) error {
+			return writeFlatPoints(dl, batch)
+		})
 		//log.Println("CopyFrom End")
 		if err != nil {
 			rerr = fmt.Errorf("Error writing %d batch of '%s' of size %d in position %d: %s\n", n, batch[0].MeasurementName, len(batch), c.Position(), err.Error())
 			break
 		}
-		if rows != int64(len(batch)) {
-			rerr = fmt.Errorf("Problem writing of %d batch: Written only %d rows of %d", n, rows, len(batch))
-			break
-		}
+		bulk_load.Runner.BatchWritten(&batch[0])
 		n++
 	}
 	workersGroup.Done()
@@ -604,15 +715,17 @@ const createDatabaseSql = "create database " + DatabaseName + ";"
 const createExtensionSql = "CREATE EXTENSION IF NOT EXISTS timescaledb CASCADE;"
 
 var DevopsCreateTableSql = []string{
-	"CREATE table cpu(time bigint not null,hostname TEXT,region TEXT,datacenter TEXT,rack TEXT,os TEXT,arch TEXT,team TEXT,service TEXT,service_version TEXT,service_environment TEXT,usage_user float8,usage_system float8,usage_idle float8,usage_nice float8,usage_iowait float8,usage_irq float8,usage_softirq float8,usage_steal float8,usage_guest float8,usage_guest_nice float8);",
-	"CREATE table diskio(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, serial TEXT, reads bigint, writes bigint, read_bytes bigint, write_bytes bigint, read_time bigint, write_time bigint, io_time bigint );",
-	"CREATE table disk(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, path TEXT, fstype TEXT, total bigint, free bigint, used bigint, used_percent bigint, inodes_total bigint, inodes_free bigint, inodes_used bigint);",
-	"CREATE table kernel(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, boot_time bigint, interrupts bigint, context_switches bigint, processes_forked bigint, disk_pages_in bigint, disk_pages_out bigint);",
-	"CREATE table mem(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, total bigint, available bigint, used bigint, free bigint, cached bigint, buffered bigint, used_percent float8, available_percent float8, buffered_percent float8);",
-	"CREATE table net(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, interface TEXT, total_connections_received bigint, expired_keys bigint, evicted_keys bigint, keyspace_hits bigint, keyspace_misses bigint, instantaneous_ops_per_sec bigint, instantaneous_input_kbps bigint, instantaneous_output_kbps bigint, bytes_sent bigint, bytes_recv bigint, packets_sent bigint, packets_recv bigint, err_in bigint, err_out bigint, drop_in bigint, drop_out bigint );",
-	"CREATE table nginx(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, port TEXT, server TEXT, accepts bigint, active bigint, handled bigint, reading bigint, requests bigint, waiting bigint, writing bigint );",
-	"CREATE table postgresl(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, numbackends bigint, xact_commit bigint, xact_rollback bigint, blks_read bigint, blks_hit bigint, tup_returned bigint, tup_fetched bigint, tup_inserted bigint, tup_updated bigint, tup_deleted bigint, conflicts bigint, temp_files bigint, temp_bytes bigint, deadlocks bigint, blk_read_time bigint, blk_write_time bigint );",
-	"CREATE table redis(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, port TEXT, server TEXT, uptime_in_seconds bigint, total_connections_received bigint, expired_keys bigint, evicted_keys bigint, keyspace_hits bigint, keyspace_misses bigint, instantaneous_ops_per_sec bigint, instantaneous_input_kbps bigint, instantaneous_output_kbps bigint, connected_clients bigint, used_memory bigint, used_memory_rss bigint, used_memory_peak bigint, used_memory_lua bigint, rdb_changes_since_last_save bigint, sync_full bigint, sync_partial_ok bigint, sync_partial_err bigint, pubsub_channels bigint, pubsub_patterns bigint, latest_fork_usec bigint, connected_slaves bigint, master_repl_offset bigint, repl_backlog_active bigint, repl_backlog_size bigint, repl_backlog_histlen bigint, mem_fragmentation_ratio bigint, used_cpu_sys bigint, used_cpu_user bigint, used_cpu_sys_children bigint, used_cpu_user_children bigint );",
+	"CREATE table cpu(time bigint not null,hostname TEXT,region TEXT,datacenter TEXT,rack TEXT,os TEXT,arch TEXT,team TEXT,service TEXT,service_version TEXT,service_environment TEXT,cluster_id TEXT,usage_user float8,usage_system float8,usage_idle float8,usage_nice float8,usage_iowait float8,usage_irq float8,usage_softirq float8,usage_steal float8,usage_guest float8,usage_guest_nice float8);",
+	"CREATE table diskio(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, serial TEXT, reads bigint, writes bigint, read_bytes bigint, write_bytes bigint, read_time bigint, write_time bigint, io_time bigint );",
+	"CREATE table disk(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, path TEXT, fstype TEXT, total bigint, free bigint, used bigint, used_percent bigint, inodes_total bigint, inodes_free bigint, inodes_used bigint);",
+	"CREATE table kernel(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, boot_time bigint, interrupts bigint, context_switches bigint, processes_forked bigint, disk_pages_in bigint, disk_pages_out bigint);",
+	"CREATE table mem(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, total bigint, available bigint, used bigint, free bigint, cached bigint, buffered bigint, used_percent float8, available_percent float8, buffered_percent float8);",
+	"CREATE table net(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, interface TEXT, total_connections_received bigint, expired_keys bigint, evicted_keys bigint, keyspace_hits bigint, keyspace_misses bigint, instantaneous_ops_per_sec bigint, instantaneous_input_kbps bigint, instantaneous_output_kbps bigint, bytes_sent bigint, bytes_recv bigint, packets_sent bigint, packets_recv bigint, err_in bigint, err_out bigint, drop_in bigint, drop_out bigint );",
+	"CREATE table nginx(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, port TEXT, server TEXT, accepts bigint, active bigint, handled bigint, reading bigint, requests bigint, waiting bigint, writing bigint );",
+	"CREATE table postgresl(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, numbackends bigint, xact_commit bigint, xact_rollback bigint, blks_read bigint, blks_hit bigint, tup_returned bigint, tup_fetched bigint, tup_inserted bigint, tup_updated bigint, tup_deleted bigint, conflicts bigint, temp_files bigint, temp_bytes bigint, deadlocks bigint, blk_read_time bigint, blk_write_time bigint );",
+	"CREATE table redis(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, port TEXT, server TEXT, uptime_in_seconds bigint, total_connections_received bigint, expired_keys bigint, evicted_keys bigint, keyspace_hits bigint, keyspace_misses bigint, instantaneous_ops_per_sec bigint, instantaneous_input_kbps bigint, instantaneous_output_kbps bigint, connected_clients bigint, used_memory bigint, used_memory_rss bigint, used_memory_peak bigint, used_memory_lua bigint, rdb_changes_since_last_save bigint, sync_full bigint, sync_partial_ok bigint, sync_partial_err bigint, pubsub_channels bigint, pubsub_patterns bigint, latest_fork_usec bigint, connected_slaves bigint, master_repl_offset bigint, repl_backlog_active bigint, repl_backlog_size bigint, repl_backlog_histlen bigint, mem_fragmentation_ratio bigint, used_cpu_sys bigint, used_cpu_user bigint, used_cpu_sys_children bigint, used_cpu_user_children bigint );",
+	"CREATE table status(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, service_up bigint );",
+	"CREATE table system(time bigint not null, hostname TEXT, region TEXT, datacenter TEXT, rack TEXT, os TEXT, arch TEXT, team TEXT, service TEXT, service_version TEXT, service_environment TEXT, cluster_id TEXT, n_cpus bigint, load1 float8, load5 float8, load15 float8 );",
 }
 
 var IotCreateTableSql = []string{
@@ -641,6 +754,8 @@ var devopsCreateHypertableSql = []string{
 	"select create_hypertable('nginx','time', chunk_time_interval => %d);",
 	"select create_hypertable('postgresl','time', chunk_time_interval => %d);",
 	"select create_hypertable('redis','time', chunk_time_interval => %d);",
+	"select create_hypertable('status','time', chunk_time_interval => %d);",
+	"select create_hypertable('system','time', chunk_time_interval => %d);",
 }
 
 var iotCreateHypertableSql = []string{
@@ -669,6 +784,8 @@ var devopsCreateIndexSql = []string{
 	"CREATE index nginx_hostname_index on nginx(hostname, time DESC);",
 	"CREATE index postgresl_hostname_index on postgresl(hostname, time DESC);",
 	"CREATE index redis_hostname_index on redis(hostname, time DESC);",
+	"CREATE index status_hostname_index on status(hostname, time DESC);",
+	"CREATE index system_hostname_index on system(hostname, time DESC);",
 }
 
 var iotCreateIndexSql = []string{
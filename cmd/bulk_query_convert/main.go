@@ -0,0 +1,115 @@
+// bulk_query_convert converts the queries generated by bulk_query_gen between
+// the gob and the human-readable JSONL encodings. The input encoding is
+// detected, so gob queries can be inspected, edited and converted back:
+//
+//	bulk_query_gen ... | bulk_query_convert > queries.jsonl
+//	bulk_query_convert -to gob < queries.jsonl > queries.gob
+package main
+
+import (
+	"bufio"
+	"encoding/gob"
+	"flag"
+	"fmt"
+	bulkQueryGen "github.com/influxdata/influxdb-comparisons/bulk_query_gen"
+	"github.com/influxdata/influxdb-comparisons/bulk_query_gen/cassandra"
+	"github.com/influxdata/influxdb-comparisons/bulk_query_gen/mongodb"
+	"github.com/influxdata/influxdb-comparisons/bulk_query_gen/timescaledb"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
+	"io"
+	"log"
+	"os"
+)
+
+// queryTypes maps the query types to constructors of their structs. Gob
+// streams carry no type name, so the query type must be given.
+var queryTypes = map[string]func() interface{}{
+	"http":        func() interface{} { return &bulkQueryGen.HTTPQuery{} },
+	"cassandra":   func() interface{} { return &cassandra.CassandraQuery{} },
+	"mongo":       func() interface{} { return &mongodb.MongoQuery{} },
+	"timescaledb": func() interface{} { return &timescaledb.SQLQuery{} },
+}
+
+// Program option vars:
+var (
+	queryType string
+	to        string
+)
+
+// Parse args:
+func init() {
+	flag.StringVar(&queryType, "type", "http", "Type of the queries: 'http' (influx, es, graphite, opentsdb and splunk formats), 'cassandra', 'mongo' or 'timescaledb'.")
+	flag.StringVar(&to, "to", "jsonl", "Encoding to convert to: 'jsonl' or 'gob'.")
+
+	flag.Parse()
+
+	if _, ok := queryTypes[queryType]; !ok {
+		log.Fatalf("invalid query type specifier: %s", queryType)
+	}
+	if to != "jsonl" && to != "gob" {
+		log.Fatalf("invalid encoding specifier: %s", to)
+	}
+}
+
+func main() {
+	in := bufio.NewReaderSize(os.Stdin, 4*1024*1024)
+	var dec jsonl.QueryDecoder
+	if jsonl.IsJSONL(in) {
+		dec = jsonl.NewDecoder(in)
+	} else {
+		dec = gob.NewDecoder(in)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	var enc jsonl.QueryEncoder
+	if to == "jsonl" {
+		enc = jsonl.NewEncoder(out)
+	} else {
+		enc = gob.NewEncoder(out)
+	}
+
+	n := 0
+	for {
+		// a fresh struct per query, as gob does not transmit empty fields
+		q := queryTypes[queryType]()
+		err := dec.Decode(q)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatalf("decoding query %d: %v", n+1, err)
+		}
+		if mq, ok := q.(*mongodb.MongoQuery); ok {
+			for i, doc := range mq.BsonDoc {
+				mq.BsonDoc[i] = toMongoTypes(doc).(mongodb.M)
+			}
+		}
+		if err = enc.Encode(q); err != nil {
+			log.Fatalf("encoding query %d: %v", n+1, err)
+		}
+		n++
+	}
+	fmt.Fprintf(os.Stderr, "converted %d queries to %s\n", n, to)
+}
+
+// toMongoTypes turns the generic maps and slices decoded from JSON into the
+// mongodb.M and mongodb.S types registered with gob.
+func toMongoTypes(v interface{}) interface{} {
+	switch x := v.(type) {
+	case mongodb.M:
+		for k, e := range x {
+			x[k] = toMongoTypes(e)
+		}
+		return x
+	case map[string]interface{}:
+		return toMongoTypes(mongodb.M(x))
+	case []interface{}:
+		s := make(mongodb.S, len(x))
+		for i, e := range x {
+			s[i] = toMongoTypes(e)
+		}
+		return s
+	}
+	return v
+}
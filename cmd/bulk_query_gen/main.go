@@ -17,10 +17,12 @@ import (
 	"github.com/influxdata/influxdb-comparisons/bulk_query_gen/opentsdb"
 	"github.com/influxdata/influxdb-comparisons/bulk_query_gen/splunk"
 	"github.com/influxdata/influxdb-comparisons/bulk_query_gen/timescaledb"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"log"
 	"math/rand"
 	"os"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -29,7 +31,21 @@ const (
 	DevOpsOneHostTwelveHours        = "1-host-12-hr"
 	DevOpsEightHostsOneHour         = "8-host-1-hr"
 	DevOpsGroupBy                   = "groupby"
+	DevOpsLastPoint                 = "lastpoint"
+	DevOpsHighCPUOneHost            = "high-cpu-1"
+	DevOpsHighCPUAllHosts           = "high-cpu-all"
+	DevOpsCPUMaxAllOneHost          = "cpu-max-all-1"
+	DevOpsCPUMaxAllEightHosts       = "cpu-max-all-8"
+	DevOpsDoubleGroupByOne          = "double-groupby-1"
+	DevOpsDoubleGroupByFive         = "double-groupby-5"
+	DevOpsDoubleGroupByAll          = "double-groupby-all"
+	DevOpsTopFiveHostsByMemory      = "top-5-memory"
 	IotOneHomeTwelveHours           = "1-home-12-hours"
+	IotOpenDoorsWindows             = "open-doors-windows"
+	IotWaterLeakage                 = "water-leakage"
+	IotRadiatorValveVsTemperature   = "radiator-vs-temperature"
+	IotBatteryLow                   = "battery-low"
+	IotLastStatePerRoom             = "last-state-per-room"
 	DashboardAll                    = "dashboard-all"
 	DashboardAvailability           = "availability"
 	DashboardCpuNum                 = "cpu-num"
@@ -51,6 +67,14 @@ const (
 	DashboardThroughput             = "throughput"
 )
 
+// verifiableQueryTypes are the devops query types whose expected results are
+// computed for -verify-manifest.
+var verifiableQueryTypes = map[string]bool{
+	DevOpsOneHostOneHour:     true,
+	DevOpsOneHostTwelveHours: true,
+	DevOpsEightHostsOneHour:  true,
+}
+
 // query generator choices {use-case, query-type, format}
 // (This object is shown to the user when flag.Usage is called.)
 var useCaseMatrix = map[string]map[string]map[string]bulkQueryGen.QueryGeneratorMaker{
@@ -97,6 +121,15 @@ var useCaseMatrix = map[string]map[string]map[string]bulkQueryGen.QueryGenerator
 			"graphite":         graphite.NewGraphiteDevopsGroupBy,
 			"splunk":           splunk.NewSplunkDevopsGroupBy,
 		},
+		DevOpsLastPoint:            devopsQueryMakers(bulkQueryGen.Devops.LastPointPerHost),
+		DevOpsHighCPUOneHost:       devopsQueryMakers(bulkQueryGen.Devops.HighCPUOneHost),
+		DevOpsHighCPUAllHosts:      devopsQueryMakers(bulkQueryGen.Devops.HighCPUAllHosts),
+		DevOpsCPUMaxAllOneHost:     devopsQueryMakers(bulkQueryGen.Devops.CPUMaxAllOneHost),
+		DevOpsCPUMaxAllEightHosts:  devopsQueryMakers(bulkQueryGen.Devops.CPUMaxAllEightHosts),
+		DevOpsDoubleGroupByOne:     devopsQueryMakers(bulkQueryGen.Devops.DoubleGroupByOneMetric),
+		DevOpsDoubleGroupByFive:    devopsQueryMakers(bulkQueryGen.Devops.DoubleGroupByFiveMetrics),
+		DevOpsDoubleGroupByAll:     devopsQueryMakers(bulkQueryGen.Devops.DoubleGroupByAllMetrics),
+		DevOpsTopFiveHostsByMemory: devopsQueryMakers(bulkQueryGen.Devops.TopFiveHostsByMemory),
 	},
 	common.UseCaseIot: {
 		IotOneHomeTwelveHours: {
@@ -106,45 +139,92 @@ var useCaseMatrix = map[string]map[string]map[string]bulkQueryGen.QueryGenerator
 			"cassandra":        cassandra.NewCassandraIotSingleHost,
 			"mongo":            mongodb.NewMongoIotSingleHost,
 		},
+		IotOpenDoorsWindows:           iotQueryMakers(bulkQueryGen.Iot.OpenDoorsWindowsOneHome),
+		IotWaterLeakage:               iotQueryMakers(bulkQueryGen.Iot.WaterLeakageAllHomes),
+		IotRadiatorValveVsTemperature: iotQueryMakers(bulkQueryGen.Iot.RadiatorValveVsTemperatureOneHome),
+		IotBatteryLow:                 iotQueryMakers(bulkQueryGen.Iot.BatteryLowAllHomes),
+		IotLastStatePerRoom:           iotQueryMakers(bulkQueryGen.Iot.LastStatePerRoomOneHome),
 	},
 	common.UseCaseDashboard: {
 		DashboardAll: {
-			"influx-http": influxdb.NewInfluxQLDashboardAll,
-		},
-		DashboardCpuNum: {
-			"influx-http": influxdb.NewInfluxQLDashboardCpuNum,
+			"es-http":          elasticsearch.NewElasticSearchDashboardAll,
+			"influx-flux-http": influxdb.NewFluxDashboardAll,
+			"influx-http":      influxdb.NewInfluxQLDashboardAll,
+			"timescaledb":      timescaledb.NewTimescaleDashboardAll,
 		},
-		DashboardAvailability: {
-			"influx-http": influxdb.NewInfluxQLDashboardAvailability,
-		},
-		DashboardCpuUtilization:         {"influx-http": influxdb.NewInfluxQLDashboardCpuUtilization},
-		DashboardDiskAllocated:          {"influx-http": influxdb.NewInfluxQLDashboardDiskAllocated},
-		DashboardDiskUsage:              {"influx-http": influxdb.NewInfluxQLDashboardDiskUsage},
-		DashboardDiskUtilization:        {"influx-http": influxdb.NewInfluxQLDashboardDiskUtilization},
-		DashboardHttpRequestDuration:    {"influx-http": influxdb.NewInfluxQLDashboardHttpRequestDuration},
-		DashboardHttpRequests:           {"influx-http": influxdb.NewInfluxQLDashboardHttpRequests},
-		DashboardKapaCpu:                {"influx-http": influxdb.NewInfluxQLDashboardKapaCpu},
-		DashboardKapaLoad:               {"influx-http": influxdb.NewInfluxQLDashboardKapaLoad},
-		DashboardKapaRam:                {"influx-http": influxdb.NewInfluxQLDashboardKapaRam},
-		DashboardMemoryTotal:            {"influx-http": influxdb.NewInfluxQLDashboardMemoryTotal},
-		DashboardMemoryUtilization:      {"influx-http": influxdb.NewInfluxQLDashboardMemoryUtilization},
-		DashboardNginxRequests:          {"influx-http": influxdb.NewInfluxQLDashboardNginxRequests},
-		DashboardQueueBytes:             {"influx-http": influxdb.NewInfluxQLDashboardQueueBytes},
-		DashboardRedisMemoryUtilization: {"influx-http": influxdb.NewInfluxQLDashboardRedisMemoryUtilization},
-		DashboardSystemLoad:             {"influx-http": influxdb.NewInfluxQLDashboardSystemLoad},
-		DashboardThroughput:             {"influx-http": influxdb.NewInfluxQLDashboardThroughput},
+		DashboardAvailability:           dashboardQueryMakers(bulkQueryGen.Dashboard.Availability, influxdb.NewInfluxQLDashboardAvailability, influxdb.NewFluxDashboardAvailability),
+		DashboardCpuNum:                 dashboardQueryMakers(bulkQueryGen.Dashboard.CpuNum, influxdb.NewInfluxQLDashboardCpuNum, influxdb.NewFluxDashboardCpuNum),
+		DashboardCpuUtilization:         dashboardQueryMakers(bulkQueryGen.Dashboard.CpuUtilization, influxdb.NewInfluxQLDashboardCpuUtilization, influxdb.NewFluxDashboardCpuUtilization),
+		DashboardDiskAllocated:          dashboardQueryMakers(bulkQueryGen.Dashboard.DiskAllocated, influxdb.NewInfluxQLDashboardDiskAllocated, influxdb.NewFluxDashboardDiskAllocated),
+		DashboardDiskUsage:              dashboardQueryMakers(bulkQueryGen.Dashboard.DiskUsage, influxdb.NewInfluxQLDashboardDiskUsage, influxdb.NewFluxDashboardDiskUsage),
+		DashboardDiskUtilization:        dashboardQueryMakers(bulkQueryGen.Dashboard.DiskUtilization, influxdb.NewInfluxQLDashboardDiskUtilization, influxdb.NewFluxDashboardDiskUtilization),
+		DashboardHttpRequestDuration:    dashboardQueryMakers(bulkQueryGen.Dashboard.HttpRequestDuration, influxdb.NewInfluxQLDashboardHttpRequestDuration, influxdb.NewFluxDashboardHttpRequestDuration),
+		DashboardHttpRequests:           dashboardQueryMakers(bulkQueryGen.Dashboard.HttpRequests, influxdb.NewInfluxQLDashboardHttpRequests, influxdb.NewFluxDashboardHttpRequests),
+		DashboardKapaCpu:                dashboardQueryMakers(bulkQueryGen.Dashboard.KapaCpu, influxdb.NewInfluxQLDashboardKapaCpu, influxdb.NewFluxDashboardKapaCpu),
+		DashboardKapaLoad:               dashboardQueryMakers(bulkQueryGen.Dashboard.KapaLoad, influxdb.NewInfluxQLDashboardKapaLoad, influxdb.NewFluxDashboardKapaLoad),
+		DashboardKapaRam:                dashboardQueryMakers(bulkQueryGen.Dashboard.KapaRam, influxdb.NewInfluxQLDashboardKapaRam, influxdb.NewFluxDashboardKapaRam),
+		DashboardMemoryTotal:            dashboardQueryMakers(bulkQueryGen.Dashboard.MemoryTotal, influxdb.NewInfluxQLDashboardMemoryTotal, influxdb.NewFluxDashboardMemoryTotal),
+		DashboardMemoryUtilization:      dashboardQueryMakers(bulkQueryGen.Dashboard.MemoryUtilization, influxdb.NewInfluxQLDashboardMemoryUtilization, influxdb.NewFluxDashboardMemoryUtilization),
+		DashboardNginxRequests:          dashboardQueryMakers(bulkQueryGen.Dashboard.NginxRequests, influxdb.NewInfluxQLDashboardNginxRequests, influxdb.NewFluxDashboardNginxRequests),
+		DashboardQueueBytes:             dashboardQueryMakers(bulkQueryGen.Dashboard.QueueBytes, influxdb.NewInfluxQLDashboardQueueBytes, influxdb.NewFluxDashboardQueueBytes),
+		DashboardRedisMemoryUtilization: dashboardQueryMakers(bulkQueryGen.Dashboard.RedisMemoryUtilization, influxdb.NewInfluxQLDashboardRedisMemoryUtilization, influxdb.NewFluxDashboardRedisMemoryUtilization),
+		DashboardSystemLoad:             dashboardQueryMakers(bulkQueryGen.Dashboard.SystemLoad, influxdb.NewInfluxQLDashboardSystemLoad, influxdb.NewFluxDashboardSystemLoad),
+		DashboardThroughput:             dashboardQueryMakers(bulkQueryGen.Dashboard.Throughput, influxdb.NewInfluxQLDashboardThroughput, influxdb.NewFluxDashboardThroughput),
 	},
 }
 
+// devopsQueryMakers returns the query generator makers of the formats
+// implementing the devops query filled by fill.
+func devopsQueryMakers(fill bulkQueryGen.DevopsQueryFunc) map[string]bulkQueryGen.QueryGeneratorMaker {
+	return map[string]bulkQueryGen.QueryGeneratorMaker{
+		"cassandra":        cassandra.NewCassandraDevopsQuery(fill),
+		"es-http":          elasticsearch.NewElasticSearchDevopsQuery(fill),
+		"influx-flux-http": influxdb.NewFluxDevopsQuery(fill),
+		"influx-http":      influxdb.NewInfluxQLDevopsQuery(fill),
+		"mongo":            mongodb.NewMongoDevopsQuery(fill),
+		"timescaledb":      timescaledb.NewTimescaleDevopsQuery(fill),
+	}
+}
+
+// iotQueryMakers returns the query generator makers of the formats
+// implementing the iot query filled by fill.
+func iotQueryMakers(fill bulkQueryGen.IotQueryFunc) map[string]bulkQueryGen.QueryGeneratorMaker {
+	return map[string]bulkQueryGen.QueryGeneratorMaker{
+		"cassandra":        cassandra.NewCassandraIotQuery(fill),
+		"influx-flux-http": influxdb.NewFluxIotQuery(fill),
+		"influx-http":      influxdb.NewInfluxQLIotQuery(fill),
+		"mongo":            mongodb.NewMongoIotQuery(fill),
+		"timescaledb":      timescaledb.NewTimescaleIotQuery(fill),
+	}
+}
+
+// dashboardQueryMakers returns the query generator makers of the formats
+// implementing the dashboard query filled by fill, along with the given
+// InfluxQL and Flux ones.
+func dashboardQueryMakers(fill bulkQueryGen.DashboardQueryFunc, influxQL, flux bulkQueryGen.QueryGeneratorMaker) map[string]bulkQueryGen.QueryGeneratorMaker {
+	return map[string]bulkQueryGen.QueryGeneratorMaker{
+		"es-http":          elasticsearch.NewElasticSearchDashboardQuery(fill),
+		"influx-flux-http": flux,
+		"influx-http":      influxQL,
+		"timescaledb":      timescaledb.NewTimescaleDashboardQuery(fill),
+	}
+}
+
 // Program option vars:
 var (
 	useCase        string
 	queryType      string
+	queryMix       string
+	queryTemplate  string
 	format         string
 	documentFormat string
+	encoding       string
+	verifyManifest string
+	manifestFile   string
 
-	scaleVar   int
-	queryCount int
+	scaleVar         int
+	queryCount       int
+	hostDistribution string
 
 	dbName string // TODO(rw): make this a map[string]string -> DatabaseConfig
 
@@ -162,6 +242,9 @@ var (
 
 	interleavedGenerationGroupID uint
 	interleavedGenerationGroups  uint
+
+	mixEntries    []bulkQueryGen.QueryMixEntry
+	templateMaker bulkQueryGen.QueryGeneratorMaker
 )
 
 // Parse args:
@@ -183,12 +266,18 @@ func init() {
 	}
 
 	flag.StringVar(&format, "format", "influx-http", "Format to emit. (Choices are in the use case matrix.)")
+	flag.StringVar(&encoding, "encoding", "gob", "Encoding of the generated queries: 'gob' or 'jsonl' (human-readable JSON lines). The query benchmarkers read both.")
+	flag.StringVar(&manifestFile, "manifest-file", "", "Write JSON query manifest to `file` (generation parameters, including the host distribution), read by the benchmarker's -query-manifest.")
+	flag.StringVar(&verifyManifest, "verify-manifest", "", "Manifest of the loaded dataset (see bulk_data_gen -manifest-file). The dataset is replayed to embed the expected results into the queries, for the benchmarker's -verify-results. (Devops max cpu InfluxQL queries only.)")
 	flag.StringVar(&documentFormat, "document-format", "", "Document format specification. (for mongo format 'simpleArrays'; leave empty for previous behaviour)")
 	flag.StringVar(&useCase, "use-case", common.UseCaseChoices[0], "Use case to model. (Choices are in the use case matrix.)")
 	flag.StringVar(&queryType, "query-type", "", "Query type. (Choices are in the use case matrix.)")
+	flag.StringVar(&queryMix, "query-mix", "", "Weighted mix of query types to interleave, e.g. '1-host-1-hr:50,groupby:10'. Query types of another use case are prefixed by it, e.g. 'iot/battery-low:5'. Overrides query-type.")
+	flag.StringVar(&queryTemplate, "query-template", "", "TOML file of HTTP query templates to generate queries from, instead of the use case matrix. Overrides use-case, query-type, query-mix and format.")
 
 	flag.IntVar(&scaleVar, "scale-var", 1, "Scaling variable (must be the equal to the scale-var used for data generation).")
 	flag.IntVar(&queryCount, "queries", 1000, "Number of queries to generate.")
+	flag.StringVar(&hostDistribution, "host-distribution", "uniform", "Distribution of the hosts picked by queries: 'uniform', 'zipfian[:skew]' or 'hot-set[:hot-percent[:access-percent]]'.")
 	flag.StringVar(&dbName, "db", "benchmark_db", "Database to use (ignored for ElasticSearch).")
 
 	flag.StringVar(&timestampStartStr, "timestamp-start", common.DefaultDateTimeStart, "Beginning timestamp (RFC3339).")
@@ -205,30 +294,81 @@ func init() {
 
 	flag.Parse()
 
-	if queryType == DevOpsEightHostsOneHour && scaleVar < 8 {
-		log.Fatal("\"scale-var\" must be greater than the hosts grouping number")
-	}
-
-	if !(interleavedGenerationGroupID < interleavedGenerationGroups) {
-		log.Fatal("incorrect interleaved groups configuration")
+	var templates []bulkQueryGen.HTTPTemplate
+	if queryTemplate != "" {
+		var err error
+		templates, err = bulkQueryGen.LoadHTTPTemplates(queryTemplate)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else if queryMix != "" {
+		var err error
+		mixEntries, err = bulkQueryGen.ParseQueryMix(queryMix, useCase)
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		mixEntries = []bulkQueryGen.QueryMixEntry{{UseCase: useCase, QueryType: queryType, Weight: 1}}
 	}
 
-	if _, ok := useCaseMatrix[useCase]; !ok {
-		log.Fatal("invalid use case specifier")
+	if encoding != "gob" && encoding != "jsonl" {
+		log.Fatalf("invalid encoding specifier: %s", encoding)
 	}
 
-	if _, ok := useCaseMatrix[useCase][queryType]; !ok {
-		log.Fatal("invalid query type specifier")
+	if verifyManifest != "" {
+		if queryTemplate != "" || format != "influx-http" {
+			log.Fatal("\"verify-manifest\" is supported only for the influx-http format, without query templates")
+		}
+		for _, e := range mixEntries {
+			if e.UseCase != common.UseCaseDevOps || !verifiableQueryTypes[e.QueryType] {
+				log.Fatalf("\"verify-manifest\" is not supported for query type %s of use case %s (supported: %s, %s, %s of %s)", e.QueryType, e.UseCase, DevOpsOneHostOneHour, DevOpsOneHostTwelveHours, DevOpsEightHostsOneHour, common.UseCaseDevOps)
+			}
+		}
 	}
 
-	if _, ok := useCaseMatrix[useCase][queryType][format]; !ok {
-		log.Fatal("invalid format specifier")
+	if !(interleavedGenerationGroupID < interleavedGenerationGroups) {
+		log.Fatal("incorrect interleaved groups configuration")
 	}
 
 	hourGroupInterval := 1
+	dashboardUseCase := false
+
+	for _, e := range mixEntries {
+		if (e.QueryType == DevOpsEightHostsOneHour || e.QueryType == DevOpsCPUMaxAllEightHosts) && scaleVar < 8 {
+			log.Fatal("\"scale-var\" must be greater than the hosts grouping number")
+		}
+
+		if _, ok := useCaseMatrix[e.UseCase]; !ok {
+			log.Fatalf("invalid use case specifier: %s", e.UseCase)
+		}
+
+		if _, ok := useCaseMatrix[e.UseCase][e.QueryType]; !ok {
+			log.Fatalf("invalid query type specifier: %s", e.QueryType)
+		}
 
-	if queryType == DevOpsOneHostTwelveHours {
-		hourGroupInterval = 12
+		if _, ok := useCaseMatrix[e.UseCase][e.QueryType][format]; !ok {
+			formats := make([]string, 0, len(useCaseMatrix[e.UseCase][e.QueryType]))
+			for f := range useCaseMatrix[e.UseCase][e.QueryType] {
+				formats = append(formats, f)
+			}
+			sort.Strings(formats)
+			log.Fatalf("query type %s of use case %s is not implemented for format %s (implemented for: %s)", e.QueryType, e.UseCase, format, strings.Join(formats, ", "))
+		}
+
+		switch e.QueryType {
+		case DevOpsOneHostTwelveHours, IotOpenDoorsWindows, IotRadiatorValveVsTemperature, DevOpsHighCPUOneHost, DevOpsHighCPUAllHosts, DevOpsDoubleGroupByOne, DevOpsDoubleGroupByFive, DevOpsDoubleGroupByAll:
+			if hourGroupInterval < 12 {
+				hourGroupInterval = 12
+			}
+		case DevOpsCPUMaxAllOneHost, DevOpsCPUMaxAllEightHosts:
+			if hourGroupInterval < 8 {
+				hourGroupInterval = 8
+			}
+		}
+
+		if e.UseCase == common.UseCaseDashboard {
+			dashboardUseCase = true
+		}
 	}
 
 	// Parse timestamps:
@@ -244,6 +384,11 @@ func init() {
 	}
 	timestampEnd = timestampEnd.UTC()
 
+	bulkQueryGen.DefaultHostDistribution, err = bulkQueryGen.ParseHostDistribution(hostDistribution)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	duration := timestampEnd.Sub(timestampStart)
 
 	if duration.Nanoseconds() < 0 {
@@ -260,7 +405,7 @@ func init() {
 	bulkQueryGen.QueryIntervalType = queryIntervalType
 	switch queryIntervalType {
 	case "window":
-		if useCase == common.UseCaseDashboard && timeWindowShift <= 0 { // when not set, always use 5s default for dashboard
+		if dashboardUseCase && timeWindowShift <= 0 { // when not set, always use 5s default for dashboard
 			timeWindowShift = 5 * time.Second
 		}
 	case "last":
@@ -274,13 +419,25 @@ func init() {
 		log.Fatalf("Unsupported query interval type: %s\n", queryIntervalType)
 	}
 
+	if templates != nil {
+		interval := bulkQueryGen.NewTimeInterval(timestampStart, timestampEnd)
+		templateMaker, err = bulkQueryGen.NewHTTPTemplateQuery(templates, interval, queryInterval, scaleVar)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	if timeWindowShift > 0 {
 		bulkQueryGen.TimeWindowShift = timeWindowShift // global
-		queryCount = int(timestampEnd.Sub(timestampStart).Seconds() / timeWindowShift.Seconds())
-		if queryType == DashboardAll {
-			queryCount *= 18
+		if queryMix == "" && queryTemplate == "" {
+			queryCount = int(timestampEnd.Sub(timestampStart).Seconds() / timeWindowShift.Seconds())
+			if queryType == DashboardAll {
+				queryCount *= 18
+			}
+			log.Printf("%v queries will be generated to cover time interval using %v shift", queryCount, timeWindowShift)
+		} else {
+			log.Printf("%v queries of the mix will be generated using %v shift", queryCount, timeWindowShift)
 		}
-		log.Printf("%v queries will be generated to cover time interval using %v shift", queryCount, timeWindowShift)
 	}
 
 	if format == "mongo" {
@@ -296,19 +453,49 @@ func init() {
 		seed = int64(time.Now().Nanosecond())
 	}
 	fmt.Fprintf(os.Stderr, "using random seed %d\n", seed)
+	fmt.Fprintf(os.Stderr, "using %s host distribution\n", bulkQueryGen.DefaultHostDistribution)
 }
 
 func main() {
+	if verifyManifest != "" {
+		m, err := common.ReadManifest(verifyManifest)
+		if err != nil {
+			log.Fatalf("cannot read manifest: %v", err)
+		}
+		if m.ScaleVar != int64(scaleVar) || m.ScaleVarOffset != 0 {
+			log.Fatalf("manifest dataset of %d hosts from offset %d does not match \"scale-var\" %d", m.ScaleVar, m.ScaleVarOffset, scaleVar)
+		}
+		log.Printf("Replaying dataset of %s to compute expected results", verifyManifest)
+		bulkQueryGen.Expected, err = bulkQueryGen.ReplayDevops(m)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	rand.Seed(seed)
 
 	dbConfig := bulkQueryGen.DatabaseConfig{
 		bulkQueryGen.DatabaseName: dbName,
 	}
 
-	// Make the query generator:
-	maker := useCaseMatrix[useCase][queryType][format]
+	// Make the query generator, interleaving the query types of a mix:
 	interval := bulkQueryGen.NewTimeInterval(timestampStart, timestampEnd)
-	var generator = maker(dbConfig, interval, queryInterval, scaleVar)
+	var generator bulkQueryGen.QueryGenerator
+	if templateMaker != nil {
+		generator = templateMaker(dbConfig, interval, queryInterval, scaleVar)
+	} else if queryMix == "" {
+		maker := useCaseMatrix[useCase][queryType][format]
+		generator = maker(dbConfig, interval, queryInterval, scaleVar)
+	} else {
+		gens := make([]bulkQueryGen.QueryGenerator, len(mixEntries))
+		weights := make([]int, len(mixEntries))
+		for i, e := range mixEntries {
+			maker := useCaseMatrix[e.UseCase][e.QueryType][format]
+			gens[i] = maker(dbConfig, interval, queryInterval, scaleVar)
+			weights[i] = e.Weight
+		}
+		generator = bulkQueryGen.NewQueryMix(gens, weights)
+	}
 
 	// Set up bookkeeping:
 	stats := make(map[string]int64)
@@ -322,9 +509,15 @@ func main() {
 	// belong to this interleaved group id:
 	var currentInterleavedGroup uint = 0
 
-	enc := gob.NewEncoder(out)
+	var enc jsonl.QueryEncoder
+	if encoding == "jsonl" {
+		enc = jsonl.NewEncoder(out)
+	} else {
+		enc = gob.NewEncoder(out)
+	}
 	for i := 0; i < queryCount; i++ {
 		q := generator.Dispatch(i)
+		bulkQueryGen.LabelHostDistribution(q, bulkQueryGen.DefaultHostDistribution)
 
 		if currentInterleavedGroup == interleavedGenerationGroupID {
 			err := enc.Encode(q)
@@ -364,10 +557,37 @@ func main() {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
+	var written int64
 	for _, k := range keys {
 		_, err := fmt.Fprintf(os.Stderr, "%s: %d points\n", k, stats[k])
 		if err != nil {
 			log.Fatal(err)
 		}
+		written += stats[k]
+	}
+
+	if manifestFile != "" {
+		m := &bulkQueryGen.QueryManifest{
+			Version:                      bulkQueryGen.QueryManifestVersion,
+			Seed:                         seed,
+			UseCase:                      useCase,
+			QueryType:                    queryType,
+			QueryMix:                     queryMix,
+			QueryTemplate:                queryTemplate,
+			Format:                       format,
+			Encoding:                     encoding,
+			ScaleVar:                     scaleVar,
+			HostDistribution:             bulkQueryGen.DefaultHostDistribution.String(),
+			TimestampStart:               timestampStart.Format(time.RFC3339),
+			TimestampEnd:                 timestampEnd.Format(time.RFC3339),
+			QueryInterval:                queryInterval.String(),
+			QueryIntervalType:            queryIntervalType,
+			InterleavedGenerationGroupID: interleavedGenerationGroupID,
+			InterleavedGenerationGroups:  interleavedGenerationGroups,
+			Queries:                      written,
+		}
+		if err := m.WriteFile(manifestFile); err != nil {
+			log.Fatalf("cannot write query manifest: %v", err)
+		}
 	}
 }
@@ -10,11 +10,11 @@
 package main
 
 import (
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"github.com/gocql/gocql"
 	"github.com/influxdata/influxdb-comparisons/bulk_query"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"io"
 	"log"
@@ -96,6 +96,7 @@ func (b *CassandraQueryBenchmarker) Prepare() {
 				FieldName:        make([]byte, 0, 1024),
 				AggregationType:  make([]byte, 0, 1024),
 				TagsCondition:    make([]byte, 0, 1024),
+				RawCQL:           make([]byte, 0, 1024),
 			}
 		},
 	}
@@ -145,7 +146,7 @@ func main() {
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *CassandraQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(r)
+	dec := jsonl.NewQueryDecoder(r)
 
 	n := int64(0)
 
@@ -156,6 +157,10 @@ loop:
 		}
 
 		q := b.queryPool.Get().(*HLQuery)
+		// gob does not transmit empty fields, clear the pooled ones
+		q.TagsCondition = q.TagsCondition[:0]
+		q.RawCQL = q.RawCQL[:0]
+		q.RawCQLTopN = 0
 		err := dec.Decode(q)
 		if err == io.EOF {
 			break
@@ -190,6 +195,7 @@ func (b *CassandraQueryBenchmarker) RunProcess(i int, workersGroup *sync.WaitGro
 	}
 	labels := map[string][][]byte{}
 	for q := range b.hlQueryChan {
+		queueDelay := bulk_query.Benchmarker.WaitArrival()
 		qpLagMs, reqLagMs, err := b.queryExecutor.Do(q, opts)
 
 		// if needed, prepare stat labels:
@@ -205,6 +211,8 @@ func (b *CassandraQueryBenchmarker) RunProcess(i int, workersGroup *sync.WaitGro
 		// total lag stat:
 		stat := statPool.Get().(*bulk_query.Stat)
 		stat.InitWithActual(ls[0], qpLagMs+reqLagMs, true)
+		stat.SetResponse(-1, q.ResponseRows)
+		stat.SetQueueDelay(queueDelay)
 		statChan <- stat
 
 		// qp lag stat:
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,16 @@ type HLQuery struct {
 	TimeEnd         time.Time
 	GroupByDuration time.Duration
 	TagsCondition   []byte
+
+	// RawCQL holds newline separated CQL statements to be executed as they
+	// are, bypassing the aggregation plans.
+	RawCQL []byte
+	// RawCQLTopN, if positive, keeps only the rows of each RawCQL statement
+	// with the N largest values of the last column.
+	RawCQLTopN int64
+
+	// number of result rows, set by HLQueryExecutor.Do, -1 when unknown
+	ResponseRows int64
 }
 
 // String produces a debug-ready description of a Query.
@@ -88,6 +99,20 @@ func (q *HLQuery) ToQueryPlanWithoutServerAggregation() (qp *QueryPlanWithoutSer
 	return
 }
 
+// ToQueryPlanRawCQL makes a QueryPlanRawCQL executing the raw CQL statements
+// of an HLQuery.
+func (q *HLQuery) ToQueryPlanRawCQL() (qp *QueryPlanRawCQL, err error) {
+	cqlQueries := []CQLQuery{}
+	for _, s := range strings.Split(string(q.RawCQL), "\n") {
+		if s == "" {
+			continue
+		}
+		cqlQueries = append(cqlQueries, CQLQuery{PreparableQueryString: s})
+	}
+	qp = &QueryPlanRawCQL{CQLQueries: cqlQueries, TopN: int(q.RawCQLTopN)}
+	return
+}
+
 // Type CQLQuery wraps data needed to execute a gocql.Query.
 type CQLQuery struct {
 	PreparableQueryString string
@@ -45,13 +45,17 @@ func (qe *HLQueryExecutor) Do(q *HLQuery, opts HLQueryExecutorDoOptions) (qpLagM
 		fmt.Printf("[hlqe] Do: %s\n", q)
 	}
 
+	q.ResponseRows = -1
+
 	// build the query plan:
 	var qp QueryPlan
 	qpStart := time.Now()
-	switch opts.AggregationPlan {
-	case AggrPlanTypeWithServerAggregation:
+	switch {
+	case len(q.RawCQL) > 0:
+		qp, err = q.ToQueryPlanRawCQL()
+	case opts.AggregationPlan == AggrPlanTypeWithServerAggregation:
 		qp, err = q.ToQueryPlanWithServerAggregation()
-	case AggrPlanTypeWithoutServerAggregation:
+	case opts.AggregationPlan == AggrPlanTypeWithoutServerAggregation:
 		qp, err = q.ToQueryPlanWithoutServerAggregation()
 	default:
 		panic("logic error: invalid aggregation plan option")
@@ -78,6 +82,7 @@ func (qe *HLQueryExecutor) Do(q *HLQuery, opts HLQueryExecutorDoOptions) (qpLagM
 	if err != nil {
 		return
 	}
+	q.ResponseRows = int64(len(results))
 
 	// optionally, print reponses for query validation:
 	if opts.PrettyPrintResponses {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -170,3 +171,116 @@ func (qp *QueryPlanWithoutServerAggregation) DebugQueries(level int) {
 		}
 	}
 }
+
+// A QueryPlanRawCQL fulfills an HLQuery by executing its raw CQL statements
+// one after another. All rows are fetched, the result of each statement is
+// its row count. With TopN set, only the rows with the TopN largest values of
+// the last column are kept, for the queries Cassandra cannot order by an
+// aggregate.
+type QueryPlanRawCQL struct {
+	CQLQueries []CQLQuery
+	TopN       int
+}
+
+// Execute runs all CQLQueries in the QueryPlan and counts the returned rows.
+func (qp *QueryPlanRawCQL) Execute(session *gocql.Session, debug int) ([]CQLResult, error) {
+	results := make([]CQLResult, 0, len(qp.CQLQueries))
+	for _, q := range qp.CQLQueries {
+		cq := session.Query(q.PreparableQueryString, q.Args...)
+		if debug == 1 {
+			fmt.Printf("[qpr] Query: %s\n", cq)
+		}
+		iter := cq.Iter()
+
+		var top *topRows
+		if qp.TopN > 0 {
+			columns := iter.Columns()
+			if len(columns) == 0 {
+				iter.Close()
+				return nil, fmt.Errorf("top %d of a query without columns", qp.TopN)
+			}
+			top = &topRows{n: qp.TopN, column: columns[len(columns)-1].Name}
+		}
+
+		rows := 0
+		row := map[string]interface{}{}
+		for iter.MapScan(row) {
+			if debug >= 3 {
+				fmt.Printf("[qpr] Row: %v\n", row)
+			}
+			rows++
+			if top != nil {
+				if err := top.add(row); err != nil {
+					iter.Close()
+					return nil, err
+				}
+			}
+			row = map[string]interface{}{}
+		}
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+		if top != nil {
+			if debug >= 2 {
+				for _, r := range top.rows {
+					fmt.Printf("[qpr] Top row: %v\n", r)
+				}
+			}
+			rows = len(top.rows)
+		}
+		results = append(results, CQLResult{Value: float64(rows)})
+	}
+
+	return results, nil
+}
+
+// DebugQueries prints debugging information.
+func (qp *QueryPlanRawCQL) DebugQueries(level int) {
+	if level >= 1 {
+		fmt.Printf("[qpr] raw CQL plan has %d CQLQuery objects\n", len(qp.CQLQueries))
+	}
+
+	if level >= 2 {
+		for i, q := range qp.CQLQueries {
+			fmt.Printf("[qpr] CQL: %d, %s\n", i, q)
+		}
+	}
+}
+
+// topRows keeps the n rows with the largest values of a numeric column.
+type topRows struct {
+	n      int
+	column string
+	rows   []map[string]interface{}
+	values []float64
+}
+
+func (t *topRows) add(row map[string]interface{}) error {
+	var v float64
+	switch x := row[t.column].(type) {
+	case float64:
+		v = x
+	case float32:
+		v = float64(x)
+	case int64:
+		v = float64(x)
+	case int:
+		v = float64(x)
+	default:
+		return fmt.Errorf("column %s of value %v is not numeric", t.column, x)
+	}
+	// insertion into the rows sorted by descending value
+	i := sort.Search(len(t.values), func(i int) bool { return t.values[i] < v })
+	if i >= t.n {
+		return nil
+	}
+	if len(t.rows) < t.n {
+		t.rows = append(t.rows, nil)
+		t.values = append(t.values, 0)
+	}
+	copy(t.rows[i+1:], t.rows[i:])
+	copy(t.values[i+1:], t.values[i:])
+	t.rows[i] = row
+	t.values[i] = v
+	return nil
+}
@@ -8,7 +8,6 @@
 package main
 
 import (
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_query"
@@ -19,6 +18,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 )
 
@@ -126,7 +126,7 @@ func (b *ElasticQueryBenchmarker) UpdateReport(params *report.QueryReportParams,
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *ElasticQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(r)
+	dec := jsonl.NewQueryDecoder(r)
 
 	n := int64(0)
 loop:
@@ -167,12 +167,16 @@ func (b *ElasticQueryBenchmarker) processQueries(w http.HTTPClient, workersGroup
 		ContentType:          "application/json",
 		Debug:                bulk_query.Benchmarker.Debug(),
 		PrettyPrintResponses: bulk_query.Benchmarker.PrettyPrintResponses(),
+		CountRows:            http.CountElasticsearchRows,
 	}
 	var queriesSeen int64
 	for q := range b.queryChan {
+		queueDelay := bulk_query.Benchmarker.WaitArrival()
 		lagMillis, err := w.Do(q, opts)
 		stat := statPool.Get().(*bulk_query.Stat)
 		stat.Init(q.HumanLabel, lagMillis)
+		stat.SetQueueDelay(queueDelay)
+		stat.SetResponse(q.ResponseBytes, q.ResponseRows)
 		statChan <- stat
 		b.queryPool.Put(q)
 		if err != nil {
@@ -6,11 +6,11 @@
 package main
 
 import (
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_query"
 	"github.com/influxdata/influxdb-comparisons/bulk_query/http"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"io"
 	"log"
@@ -123,7 +123,7 @@ var qind int64
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *GraphiteQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(r)
+	dec := jsonl.NewQueryDecoder(r)
 
 	batch := make([]*http.Query, 0, bulk_query.Benchmarker.BatchSize())
 
@@ -172,6 +172,7 @@ func (b *GraphiteQueryBenchmarker) processQueries(w http.HTTPClient, workersGrou
 	opts := &http.HTTPClientDoOptions{
 		Debug:                bulk_query.Benchmarker.Debug(),
 		PrettyPrintResponses: bulk_query.Benchmarker.PrettyPrintResponses(),
+		CountRows:            http.CountGraphiteRows,
 	}
 	var queriesSeen int64
 	for queries := range b.queryChan {
@@ -223,9 +224,12 @@ func (b *GraphiteQueryBenchmarker) processSingleQuery(w http.HTTPClient, q *http
 			doneCh <- 1
 		}
 	}()
+	queueDelay := bulk_query.Benchmarker.WaitArrival()
 	lagMillis, err := w.Do(q, opts)
 	stat := statPool.Get().(*bulk_query.Stat)
 	stat.Init(q.HumanLabel, lagMillis)
+	stat.SetQueueDelay(queueDelay)
+	stat.SetResponse(q.ResponseBytes, q.ResponseRows)
 	statChan <- stat
 	b.queryPool.Put(q)
 	if err != nil {
@@ -6,11 +6,11 @@
 package main
 
 import (
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_query"
 	"github.com/influxdata/influxdb-comparisons/bulk_query/http"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"io"
 	"log"
@@ -42,6 +42,7 @@ var querier = &InfluxQueryBenchmarker{}
 func init() {
 
 	bulk_query.Benchmarker.Init()
+	bulk_query.Benchmarker.InitVerification()
 	querier.Init()
 
 	flag.Parse()
@@ -66,6 +67,9 @@ func (b *InfluxQueryBenchmarker) Validate() {
 		log.Fatal("missing 'urls' flag")
 	}
 	fmt.Printf("daemon URLs: %v\n", b.daemonUrls)
+	if shard := bulk_query.Benchmarker.Shard(); shard != nil {
+		b.clientIndex = shard.ClientIndex
+	}
 
 	if b.httpClientType == "fast" || b.httpClientType == "default" {
 		fmt.Printf("Using HTTP client: %v\n", b.httpClientType)
@@ -133,7 +137,7 @@ var qind int64
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *InfluxQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(r)
+	dec := jsonl.NewQueryDecoder(r)
 
 	batch := make([]*http.Query, 0, bulk_query.Benchmarker.BatchSize())
 
@@ -145,6 +149,8 @@ loop:
 		}
 
 		q := b.queryPool.Get().(*http.Query)
+		// gob does not transmit empty fields, clear the pooled one
+		q.Expected = nil
 		err := dec.Decode(q)
 		if err == io.EOF {
 			break
@@ -182,6 +188,13 @@ func (b *InfluxQueryBenchmarker) processQueries(w http.HTTPClient, workersGroup
 	opts := &http.HTTPClientDoOptions{
 		Debug:                bulk_query.Benchmarker.Debug(),
 		PrettyPrintResponses: bulk_query.Benchmarker.PrettyPrintResponses(),
+		CountRows:            http.CountInfluxRows,
+	}
+	if bulk_query.Benchmarker.VerifyResults() {
+		tolerance := bulk_query.Benchmarker.VerifyTolerance()
+		opts.Verify = func(q *http.Query, body []byte) {
+			bulk_query.Benchmarker.RecordVerification(q.HumanLabel, q.Expected.VerifyInfluxQL(body, tolerance))
+		}
 	}
 	var queriesSeen int64
 	for queries := range b.queryChan {
@@ -236,9 +249,12 @@ func (b *InfluxQueryBenchmarker) processSingleQuery(w http.HTTPClient, q *http.Q
 			doneCh <- 1
 		}
 	}()
+	queueDelay := bulk_query.Benchmarker.WaitArrival()
 	lagMillis, err := w.Do(q, opts)
 	stat := statPool.Get().(*bulk_query.Stat)
 	stat.Init(q.HumanLabel, lagMillis)
+	stat.SetQueueDelay(queueDelay)
+	stat.SetResponse(q.ResponseBytes, q.ResponseRows)
 	statChan <- stat
 	b.queryPool.Put(q)
 	if err != nil {
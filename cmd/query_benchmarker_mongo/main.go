@@ -12,8 +12,10 @@ import (
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_query"
 	"github.com/influxdata/influxdb-comparisons/bulk_query_gen/mongodb"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 	"io"
 	"log"
 	"sync"
@@ -115,10 +117,13 @@ func main() {
 // target server, while tracking latency.
 func (b *MongoQueryBenchmarker) RunProcess(i int, workersGroup *sync.WaitGroup, statPool sync.Pool, statChan chan *bulk_query.Stat) {
 	for q := range b.queryChan {
+		queueDelay := bulk_query.Benchmarker.WaitArrival()
 		lag, err := b.oneQuery(b.session, q)
 
 		stat := statPool.Get().(*bulk_query.Stat)
 		stat.Init(q.HumanLabel, lag)
+		stat.SetQueueDelay(queueDelay)
+		stat.SetResponse(q.ResponseBytes, q.ResponseRows)
 		statChan <- stat
 
 		b.queryPool.Put(q)
@@ -131,7 +136,7 @@ func (b *MongoQueryBenchmarker) RunProcess(i int, workersGroup *sync.WaitGroup,
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *MongoQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(r)
+	dec := jsonl.NewQueryDecoder(r)
 
 	n := int64(0)
 loop:
@@ -170,6 +175,7 @@ loop:
 func (b *MongoQueryBenchmarker) oneQuery(session *mgo.Session, q *Query) (float64, error) {
 	start := time.Now().UnixNano()
 	var err error
+	q.ResponseBytes, q.ResponseRows = -1, -1
 	if b.doQueries {
 		db := session.DB(unsafeBytesToString(q.DatabaseName))
 		//fmt.Printf("db: %#v\n", db)
@@ -185,8 +191,13 @@ func (b *MongoQueryBenchmarker) oneQuery(session *mgo.Session, q *Query) (float6
 		}
 
 		result := Result{}
-		for iter.Next(&result) {
+		raw := bson.Raw{}
+		q.ResponseBytes, q.ResponseRows = 0, 0
+		for iter.Next(&raw) {
+			q.ResponseRows++
+			q.ResponseBytes += int64(len(raw.Data))
 			if bulk_query.Benchmarker.PrettyPrintResponses() {
+				raw.Unmarshal(&result)
 				t := time.Unix(0, result.Id.TimeBucket).UTC()
 				fmt.Printf("ID %d: %s, %f\n", q.ID, t, result.Value)
 			}
@@ -14,6 +14,10 @@ type Query struct {
 	CollectionName   []byte
 	BsonDoc          []mongodb.M
 	ID               int64
+
+	// number of result documents and their size in bytes, -1 when unknown
+	ResponseBytes int64
+	ResponseRows  int64
 }
 
 // String produces a debug-ready description of a Query.
@@ -70,6 +70,12 @@ func (w *HTTPClient) Do(q *Query, opts *HTTPClientDoOptions) (lag float64, err e
 		}
 	}
 
+	q.ResponseBytes, q.ResponseRows = -1, -1
+	if err == nil {
+		q.ResponseBytes = int64(len(resp.Body()))
+		q.ResponseRows = countRows(resp.Body())
+	}
+
 	if opts != nil {
 		// Print debug messages, if applicable:
 		switch opts.Debug {
@@ -157,3 +163,19 @@ func (w *HTTPClient) Do(q *Query, opts *HTTPClientDoOptions) (lag float64, err e
 
 	return lag, err
 }
+
+// countRows counts the datapoints of all the series of an OpenTSDB query
+// response, -1 when unparsable.
+func countRows(body []byte) int64 {
+	var series []struct {
+		Dps map[string]json.RawMessage `json:"dps"`
+	}
+	if err := json.Unmarshal(body, &series); err != nil {
+		return -1
+	}
+	var rows int64
+	for _, s := range series {
+		rows += int64(len(s.Dps))
+	}
+	return rows
+}
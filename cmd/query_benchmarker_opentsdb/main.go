@@ -8,10 +8,10 @@
 package main
 
 import (
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_query"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"io"
 	"log"
@@ -109,7 +109,7 @@ func main() {
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *OpenTsdbQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(r)
+	dec := jsonl.NewQueryDecoder(r)
 
 	n := int64(0)
 loop:
@@ -150,10 +150,13 @@ func (b *OpenTsdbQueryBenchmarker) processQueries(w *HTTPClient, workersGroup *s
 		PrettyPrintResponses: bulk_query.Benchmarker.PrettyPrintResponses(),
 	}
 	for q := range b.queryChan {
+		queueDelay := bulk_query.Benchmarker.WaitArrival()
 		lag, err := w.Do(q, opts)
 
 		stat := statPool.Get().(*bulk_query.Stat)
 		stat.Init(q.HumanLabel, lag)
+		stat.SetQueueDelay(queueDelay)
+		stat.SetResponse(q.ResponseBytes, q.ResponseRows)
 		statChan <- stat
 
 		b.queryPool.Put(q)
@@ -12,6 +12,10 @@ type Query struct {
 	ID               int64
 	StartTimestamp   int64
 	EndTimestamp     int64
+
+	// set by HTTPClient.Do, -1 when unknown
+	ResponseBytes int64
+	ResponseRows  int64
 }
 
 // String produces a debug-ready description of a Query.
@@ -7,11 +7,11 @@ package main
 
 import (
 	"encoding/base64"
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"github.com/influxdata/influxdb-comparisons/bulk_query"
 	"github.com/influxdata/influxdb-comparisons/bulk_query/http"
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"io"
 	"log"
@@ -131,7 +131,7 @@ var qind int64
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *SplunkQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(r)
+	dec := jsonl.NewQueryDecoder(r)
 
 	batch := make([]*http.Query, 0, bulk_query.Benchmarker.BatchSize())
 
@@ -232,9 +232,12 @@ func (b *SplunkQueryBenchmarker) processSingleQuery(w http.HTTPClient, q *http.Q
 			doneCh <- 1
 		}
 	}()
+	queueDelay := bulk_query.Benchmarker.WaitArrival()
 	lagMillis, err := w.Do(q, opts)
 	stat := statPool.Get().(*bulk_query.Stat)
 	stat.Init(q.HumanLabel, lagMillis)
+	stat.SetQueueDelay(queueDelay)
+	stat.SetResponse(q.ResponseBytes, q.ResponseRows)
 	statChan <- stat
 	b.queryPool.Put(q)
 	if err != nil {
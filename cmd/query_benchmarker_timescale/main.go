@@ -7,7 +7,6 @@ package main
 
 import (
 	"bufio"
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"io"
@@ -21,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/influxdata/influxdb-comparisons/util/jsonl"
 	"github.com/influxdata/influxdb-comparisons/util/report"
 	"github.com/jackc/pgx"
 	"github.com/jackc/pgx/pgxpool"
@@ -148,7 +148,7 @@ func main() {
 
 // scan reads encoded Queries and places them onto the workqueue.
 func (b *TimescaleQueryBenchmarker) RunScan(r io.Reader, closeChan chan int) {
-	dec := gob.NewDecoder(bufio.NewReaderSize(r, 4*1024*1014))
+	dec := jsonl.NewQueryDecoder(bufio.NewReaderSize(r, 4*1024*1014))
 
 	n := int64(0)
 	bc := int64(0)
@@ -191,10 +191,14 @@ func (b *TimescaleQueryBenchmarker) processQueries(conn *pgx.Conn, workersGroup
 	var lag float64
 	var err error
 	for qb := range b.queryChan {
+		// a batch is sent as a whole, so the arrival rate applies to batches
+		queueDelay := bulk_query.Benchmarker.WaitArrival()
 		if len(qb) == 1 {
 			lag, err = b.oneQuery(conn, qb[0])
 			stat := statPool.Get().(*bulk_query.Stat)
 			stat.Init(qb[0].HumanLabel, lag)
+			stat.SetQueueDelay(queueDelay)
+			stat.SetResponse(qb[0].ResponseBytes, qb[0].ResponseRows)
 			statChan <- stat
 			b.queryPool.Put(qb[0])
 		} else {
@@ -203,6 +207,8 @@ func (b *TimescaleQueryBenchmarker) processQueries(conn *pgx.Conn, workersGroup
 			for _, q := range qb {
 				stat := statPool.Get().(*bulk_query.Stat)
 				stat.Init(q.HumanLabel, lagPerQuery)
+				stat.SetQueueDelay(queueDelay)
+				stat.SetResponse(q.ResponseBytes, q.ResponseRows)
 				statChan <- stat
 				b.queryPool.Put(q)
 			}
@@ -221,13 +227,19 @@ func (b *TimescaleQueryBenchmarker) oneQuery(conn *pgx.Conn, q *Query) (float64,
 	var err error
 	var timeCol int64
 	var valCol float64
+	q.ResponseBytes, q.ResponseRows = -1, -1
 	if b.doQueries {
 		rows, err := conn.Query(context.Background(), string(q.QuerySQL))
 		if err != nil {
 			log.Println("Error running query: '", string(q.QuerySQL), "'")
 			return 0, err
 		}
+		q.ResponseBytes, q.ResponseRows = 0, 0
 		for rows.Next() {
+			q.ResponseRows++
+			for _, v := range rows.RawValues() {
+				q.ResponseBytes += int64(len(v))
+			}
 			if bulk_query.Benchmarker.PrettyPrintResponses() {
 				rows.Scan(&timeCol, &valCol)
 				t := time.Unix(0, timeCol).UTC()
@@ -250,9 +262,26 @@ func (b *TimescaleQueryBenchmarker) batchQueries(conn *pgx.Conn, batch []*Query)
 	sqlBatch := pgx.Batch{}
 	for _, query := range batch {
 		sqlBatch.Queue(string(query.QuerySQL), nil, nil, []int16{pgx.BinaryFormatCode, pgx.BinaryFormatCode})
+		query.ResponseBytes, query.ResponseRows = -1, -1
 	}
 
 	sqlBatchResults := b.pool.SendBatch(context.Background(), &sqlBatch)
+	for _, query := range batch {
+		rows, err := sqlBatchResults.Query()
+		if err != nil {
+			log.Println("Error running query: '", string(query.QuerySQL), "'")
+			sqlBatchResults.Close()
+			return 0, err
+		}
+		query.ResponseBytes, query.ResponseRows = 0, 0
+		for rows.Next() {
+			query.ResponseRows++
+			for _, v := range rows.RawValues() {
+				query.ResponseBytes += int64(len(v))
+			}
+		}
+		rows.Close()
+	}
 	if err = sqlBatchResults.Close(); err != nil {
 		log.Fatalf("failed to close a batch operation %v", err)
 	}
@@ -9,6 +9,10 @@ type Query struct {
 	HumanDescription []byte
 	QuerySQL         []byte
 	ID               int64
+
+	// set when the query is run alone, -1 when unknown
+	ResponseBytes int64
+	ResponseRows  int64
 }
 
 // String produces a debug-ready description of a Query.
@@ -0,0 +1,60 @@
+package coordinator
+
+import (
+	"net/rpc"
+	"os"
+)
+
+// Agent is the client of a coordinator.
+type Agent struct {
+	Shard Shard
+
+	client   *rpc.Client
+	progress *rpc.Call
+}
+
+// Register connects to a coordinator and registers an agent of a kind,
+// receiving its shard.
+func Register(addr, kind string) (*Agent, error) {
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	a := &Agent{client: client}
+	if err := client.Call("Coordinator.Register", &RegisterArgs{Kind: kind, Hostname: hostname}, &a.Shard); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// WaitStart tells the coordinator the agent is ready and blocks until all the
+// agents are.
+func (a *Agent) WaitStart() error {
+	var reply int
+	return a.client.Call("Coordinator.Start", &a.Shard.ClientIndex, &reply)
+}
+
+// SendProgress sends the progress without waiting for the coordinator. It is
+// skipped while the previous progress is still being sent.
+func (a *Agent) SendProgress(p Progress) {
+	if a.progress != nil {
+		select {
+		case <-a.progress.Done:
+		default:
+			return
+		}
+	}
+	p.ClientIndex = a.Shard.ClientIndex
+	a.progress = a.client.Go("Coordinator.Progress", &p, new(int), make(chan *rpc.Call, 1))
+}
+
+// Finish sends the result and disconnects from the coordinator.
+func (a *Agent) Finish(r *Result) error {
+	r.ClientIndex = a.Shard.ClientIndex
+	var reply int
+	err := a.client.Call("Coordinator.Finish", r, &reply)
+	a.client.Close()
+	return err
+}
@@ -0,0 +1,266 @@
+// Package coordinator runs a benchmark on many client machines: agents (bulk
+// loaders or query benchmarkers) register with a coordinator over RPC,
+// receive their shard, start together once all of them are ready, stream
+// their progress back and send their final results, which the coordinator
+// merges into a single report.
+package coordinator
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// Agent kinds:
+const (
+	KindLoad  = "load"
+	KindQuery = "query"
+)
+
+// Shard is the part of the benchmark assigned to an agent.
+type Shard struct {
+	// ClientIndex is the index of the agent among Clients, used to
+	// distribute load (see -client-index). A load agent reads the
+	// interleaved generation group ClientIndex of Clients groups.
+	ClientIndex int
+	Clients     int
+	// Workers is the number of workers of the agent, 0 to keep its own.
+	Workers int
+}
+
+// RegisterArgs identifies an agent registering with the coordinator.
+type RegisterArgs struct {
+	Kind     string
+	Hostname string
+}
+
+// Progress is the periodic progress of an agent.
+type Progress struct {
+	ClientIndex int
+	// load agents: values loaded so far and moving mean rate (values/sec)
+	Values     float64
+	IngestRate float64
+	// query agents: queries run so far and moving mean response time (ms)
+	Queries          int64
+	MeanResponseTime float64
+}
+
+// QueryStat holds the latency statistics (ms) of the queries of a label.
+type QueryStat struct {
+	Label string
+	Count int64
+	Min   float64
+	Max   float64
+	Sum   float64
+}
+
+// Result is the final result of an agent.
+type Result struct {
+	ClientIndex int
+	Workers     int
+	// Duration is the duration of the run, as measured by the agent.
+	Duration time.Duration
+	// load agents
+	Items  int64
+	Values int64
+	Bytes  int64
+	// query agents
+	Queries []QueryStat
+	Errors  []string
+}
+
+// Coordinator assigns the shards to the agents and collects their progress
+// and results.
+type Coordinator struct {
+	kind    string
+	clients int
+	workers int
+
+	mutex     sync.Mutex
+	hostnames []string
+	ready     []bool
+	waiting   int
+	progress  []Progress
+	results   []*Result
+	finished  int
+	startTime time.Time
+	endTime   time.Time
+
+	started chan struct{}
+	done    chan struct{}
+}
+
+// NewCoordinator creates a coordinator of the given number of agents of a
+// kind, assigning them workers each (0 to keep their own).
+func NewCoordinator(kind string, clients, workers int) *Coordinator {
+	return &Coordinator{
+		kind:      kind,
+		clients:   clients,
+		workers:   workers,
+		hostnames: make([]string, 0, clients),
+		ready:     make([]bool, clients),
+		progress:  make([]Progress, clients),
+		results:   make([]*Result, clients),
+		started:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Listen serves the agents on a TCP address.
+func (c *Coordinator) Listen(addr string) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Coordinator", &Service{c: c}); err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go server.Accept(l)
+	return nil
+}
+
+// Started is closed once all the agents registered and are ready to start.
+func (c *Coordinator) Started() <-chan struct{} {
+	return c.started
+}
+
+// Done is closed once all the agents sent their results.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// Hostnames returns the hostnames of the registered agents, by client index.
+func (c *Coordinator) Hostnames() []string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]string(nil), c.hostnames...)
+}
+
+// Progress returns the last progress of every agent.
+func (c *Coordinator) Progress() []Progress {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]Progress(nil), c.progress...)
+}
+
+// Results returns the results sent so far, nil for the agents which did not
+// finish, and the time from the start to the last result, as measured by
+// the coordinator.
+func (c *Coordinator) Results() ([]*Result, time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	end := c.endTime
+	if c.finished < c.clients {
+		end = time.Now()
+	}
+	return append([]*Result(nil), c.results...), end.Sub(c.startTime)
+}
+
+func (c *Coordinator) register(args *RegisterArgs) (Shard, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if args.Kind != c.kind {
+		return Shard{}, fmt.Errorf("coordinator of %s agents cannot register a %s agent", c.kind, args.Kind)
+	}
+	if len(c.hostnames) == c.clients {
+		return Shard{}, fmt.Errorf("all %d agents already registered", c.clients)
+	}
+	shard := Shard{ClientIndex: len(c.hostnames), Clients: c.clients, Workers: c.workers}
+	c.hostnames = append(c.hostnames, args.Hostname)
+	log.Printf("agent %d of %d registered from %s", shard.ClientIndex+1, c.clients, args.Hostname)
+	if len(c.hostnames) == c.clients {
+		log.Printf("all agents registered, waiting for them to be ready")
+	}
+	return shard, nil
+}
+
+// start marks an agent ready, releasing all of them once the last one is.
+func (c *Coordinator) start(i int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if i < 0 || i >= len(c.hostnames) {
+		return fmt.Errorf("client %d is not registered", i)
+	}
+	if c.ready[i] {
+		return fmt.Errorf("client %d already started", i)
+	}
+	c.ready[i] = true
+	c.waiting++
+	if c.waiting == c.clients {
+		c.startTime = time.Now()
+		log.Printf("all agents ready, starting")
+		close(c.started)
+	}
+	return nil
+}
+
+func (c *Coordinator) validIndex(i int) error {
+	if i < 0 || i >= c.clients {
+		return fmt.Errorf("invalid client index %d", i)
+	}
+	return nil
+}
+
+func (c *Coordinator) setProgress(p *Progress) error {
+	if err := c.validIndex(p.ClientIndex); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	c.progress[p.ClientIndex] = *p
+	c.mutex.Unlock()
+	return nil
+}
+
+func (c *Coordinator) finish(r *Result) error {
+	if err := c.validIndex(r.ClientIndex); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.results[r.ClientIndex] != nil {
+		return fmt.Errorf("client %d already finished", r.ClientIndex)
+	}
+	c.results[r.ClientIndex] = r
+	c.finished++
+	log.Printf("agent %d of %d finished", r.ClientIndex+1, c.clients)
+	if c.finished == c.clients {
+		c.endTime = time.Now()
+		close(c.done)
+	}
+	return nil
+}
+
+// Service is the RPC service of the coordinator.
+type Service struct {
+	c *Coordinator
+}
+
+// Register assigns a shard to a new agent.
+func (s *Service) Register(args *RegisterArgs, shard *Shard) error {
+	var err error
+	*shard, err = s.c.register(args)
+	return err
+}
+
+// Start marks an agent ready and blocks until all the agents are.
+func (s *Service) Start(clientIndex *int, reply *int) error {
+	if err := s.c.start(*clientIndex); err != nil {
+		return err
+	}
+	<-s.c.started
+	return nil
+}
+
+// Progress records the progress of an agent.
+func (s *Service) Progress(args *Progress, reply *int) error {
+	return s.c.setProgress(args)
+}
+
+// Finish records the result of an agent.
+func (s *Service) Finish(args *Result, reply *int) error {
+	return s.c.finish(args)
+}
@@ -0,0 +1,41 @@
+package coordinator
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestStartBarrier(t *testing.T) {
+	c := NewCoordinator(KindLoad, 2, 0)
+	s := &Service{c: c}
+
+	var first, second Shard
+	require.NoError(t, s.Register(&RegisterArgs{Kind: KindLoad, Hostname: "a"}, &first))
+	unregistered := 1
+	require.Error(t, s.Start(&unregistered, new(int)))
+	require.NoError(t, s.Register(&RegisterArgs{Kind: KindLoad, Hostname: "b"}, &second))
+	require.Error(t, s.Register(&RegisterArgs{Kind: KindLoad, Hostname: "c"}, new(Shard)))
+
+	// all the agents registered, but none is ready yet
+	select {
+	case <-c.Started():
+		t.Fatal("started before the agents were ready")
+	default:
+	}
+
+	released := make(chan error, 1)
+	go func() {
+		released <- s.Start(&first.ClientIndex, new(int))
+	}()
+	select {
+	case <-released:
+		t.Fatal("agent released before all the agents were ready")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, s.Start(&second.ClientIndex, new(int)))
+	require.NoError(t, <-released)
+	<-c.Started()
+	require.Error(t, s.Start(&second.ClientIndex, new(int)))
+}
@@ -0,0 +1,248 @@
+// Package jsonl encodes queries as JSON lines, a human-readable alternative
+// to gob: one JSON object per query, keyed by struct field names, with []byte
+// fields written as strings and time.Duration fields as duration strings.
+//
+// Like gob, decoding matches fields by name, so queries can be decoded into a
+// different struct than the one they were encoded from. Keys without a
+// matching field are ignored.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	bytesType    = reflect.TypeOf([]byte(nil))
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Encoder writes structs as JSON lines.
+type Encoder struct {
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// NewEncoder returns an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the struct v, or the struct pointed to by v, as a JSON line.
+func (e *Encoder) Encode(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("jsonl: cannot encode %T", v)
+	}
+	e.buf.Reset()
+	e.buf.WriteByte('{')
+	rt := rv.Type()
+	n := 0
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+		if n > 0 {
+			e.buf.WriteByte(',')
+		}
+		n++
+		if err := e.marshal(field.Name); err != nil {
+			return err
+		}
+		e.buf.WriteByte(':')
+
+		fv := rv.Field(i)
+		var err error
+		switch fv.Type() {
+		case bytesType:
+			err = e.marshal(string(fv.Bytes()))
+		case durationType:
+			err = e.marshal(time.Duration(fv.Int()).String())
+		default:
+			err = e.marshal(fv.Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("jsonl: cannot encode field %s: %v", field.Name, err)
+		}
+	}
+	e.buf.WriteString("}\n")
+	_, err := e.w.Write(e.buf.Bytes())
+	return err
+}
+
+// marshal appends the JSON encoding of v to the buffer, leaving characters
+// like < and & readable as they are common in queries.
+func (e *Encoder) marshal(v interface{}) error {
+	enc := json.NewEncoder(&e.buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	e.buf.Truncate(e.buf.Len() - 1) // json.Encoder appends a newline
+	return nil
+}
+
+// Decoder reads structs written by an Encoder.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder returns a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(r)}
+}
+
+// Decode reads the next JSON line into the struct pointed to by v. Fields
+// missing from the line are reset, reusing the capacity of []byte fields.
+// Numbers held by interface{} values are decoded as int64 when integral, or
+// float64 otherwise. It returns io.EOF at the end of the input.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("jsonl: cannot decode into %T", v)
+	}
+	rv = rv.Elem()
+
+	var object map[string]json.RawMessage
+	if err := d.dec.Decode(&object); err != nil {
+		return err
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fv := rv.Field(i)
+		raw, ok := object[field.Name]
+		if !ok {
+			for k, r := range object {
+				if strings.EqualFold(k, field.Name) {
+					raw, ok = r, true
+					break
+				}
+			}
+		}
+		if !ok {
+			if fv.Type() == bytesType {
+				fv.SetBytes(fv.Bytes()[:0])
+			} else {
+				fv.Set(reflect.Zero(fv.Type()))
+			}
+			continue
+		}
+		if err := decodeField(fv, raw); err != nil {
+			return fmt.Errorf("jsonl: cannot decode field %s: %v", field.Name, err)
+		}
+	}
+	return nil
+}
+
+func decodeField(fv reflect.Value, raw json.RawMessage) error {
+	switch fv.Type() {
+	case bytesType:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		fv.SetBytes(append(fv.Bytes()[:0], s...))
+		return nil
+	case durationType:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			// plain nanoseconds are accepted as well
+			var ns int64
+			if err := json.Unmarshal(raw, &ns); err != nil {
+				return err
+			}
+			fv.SetInt(ns)
+			return nil
+		}
+		dur, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(dur))
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	ptr := reflect.New(fv.Type())
+	if err := dec.Decode(ptr.Interface()); err != nil {
+		return err
+	}
+	fv.Set(normalizeNumbers(ptr.Elem()))
+	return nil
+}
+
+// normalizeNumbers replaces the json.Numbers held by interface{} values
+// within v by int64 or float64 values.
+func normalizeNumbers(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		elem := v.Elem()
+		if n, ok := elem.Interface().(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				elem = reflect.ValueOf(i)
+			} else if f, err := n.Float64(); err == nil {
+				elem = reflect.ValueOf(f)
+			}
+		} else {
+			elem = normalizeNumbers(elem)
+		}
+		normalized := reflect.New(v.Type()).Elem()
+		normalized.Set(elem)
+		return normalized
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			v.SetMapIndex(k, normalizeNumbers(v.MapIndex(k)))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).Set(normalizeNumbers(v.Index(i)))
+		}
+	}
+	return v
+}
+
+// QueryEncoder is implemented by both gob.Encoder and Encoder.
+type QueryEncoder interface {
+	Encode(v interface{}) error
+}
+
+// QueryDecoder is implemented by both gob.Decoder and Decoder.
+type QueryDecoder interface {
+	Decode(v interface{}) error
+}
+
+// NewQueryDecoder returns a decoder for r, which may hold either gob or
+// JSONL encoded queries.
+func NewQueryDecoder(r io.Reader) QueryDecoder {
+	br := bufio.NewReader(r)
+	if IsJSONL(br) {
+		return NewDecoder(br)
+	}
+	return gob.NewDecoder(br)
+}
+
+// IsJSONL tells whether r holds JSON lines rather than a gob stream, without
+// consuming it. A gob stream starts with the definition of a user type, so
+// its second byte is the first byte of a type id of -64 or less: 0x7f or
+// 0xff, neither of which can follow the opening brace of a JSON line.
+func IsJSONL(r *bufio.Reader) bool {
+	prefix, _ := r.Peek(2)
+	return len(prefix) == 2 && prefix[1] != 0x7f && prefix[1] != 0xff
+}
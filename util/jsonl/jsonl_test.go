@@ -0,0 +1,173 @@
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testQuery struct {
+	HumanLabel []byte
+	Method     []byte
+	Body       []byte
+	Timeout    time.Duration
+	Count      int64
+	Expected   interface{}
+	Tags       map[string]interface{}
+	hidden     string
+}
+
+type testQueryV2 struct {
+	HumanLabel []byte
+	Timeout    time.Duration
+	Extra      string
+}
+
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    testQuery
+		expected string
+	}{
+		{
+			name:     "empty",
+			query:    testQuery{},
+			expected: `{"HumanLabel":"","Method":"","Body":"","Timeout":"0s","Count":0,"Expected":null,"Tags":null}`,
+		},
+		{
+			name: "bytes and duration",
+			query: testQuery{
+				HumanLabel: []byte("max cpu <1h> & more"),
+				Method:     []byte("GET"),
+				Body:       []byte("line 1\nline 2"),
+				Timeout:    90 * time.Second,
+				Count:      42,
+			},
+			expected: `{"HumanLabel":"max cpu <1h> & more","Method":"GET","Body":"line 1\nline 2","Timeout":"1m30s","Count":42,"Expected":null,"Tags":null}`,
+		},
+		{
+			name: "numbers in interfaces",
+			query: testQuery{
+				Expected: []interface{}{int64(1), 2.5, "a"},
+				Tags:     map[string]interface{}{"n": int64(7), "f": 0.5, "nested": map[string]interface{}{"m": int64(-3)}},
+			},
+			expected: `{"HumanLabel":"","Method":"","Body":"","Timeout":"0s","Count":0,"Expected":[1,2.5,"a"],"Tags":{"f":0.5,"n":7,"nested":{"m":-3}}}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, NewEncoder(&out).Encode(&c.query))
+			require.Equal(t, c.expected+"\n", out.String())
+
+			var decoded testQuery
+			require.NoError(t, NewDecoder(&out).Decode(&decoded))
+			require.Equal(t, c.query, decoded)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected testQueryV2
+		err      string
+	}{
+		{
+			name:     "other struct",
+			input:    `{"HumanLabel":"q","Method":"GET","Timeout":"1s"}`,
+			expected: testQueryV2{HumanLabel: []byte("q"), Timeout: time.Second},
+		},
+		{
+			name:     "case insensitive keys",
+			input:    `{"humanlabel":"q","TIMEOUT":"2m","extra":"x"}`,
+			expected: testQueryV2{HumanLabel: []byte("q"), Timeout: 2 * time.Minute, Extra: "x"},
+		},
+		{
+			name:     "nanosecond duration",
+			input:    `{"Timeout":1500}`,
+			expected: testQueryV2{HumanLabel: []byte{}, Timeout: 1500},
+		},
+		{
+			name:  "invalid duration",
+			input: `{"Timeout":"1 minute"}`,
+			err:   "cannot decode field Timeout",
+		},
+		{
+			name:  "invalid bytes",
+			input: `{"HumanLabel":1}`,
+			err:   "cannot decode field HumanLabel",
+		},
+		{
+			name:  "invalid json",
+			input: `{"HumanLabel":`,
+			err:   "unexpected EOF",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// fields missing from the line are reset
+			decoded := testQueryV2{HumanLabel: []byte("previous"), Timeout: time.Hour, Extra: "previous"}
+			err := NewDecoder(strings.NewReader(c.input)).Decode(&decoded)
+			if c.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, decoded)
+		})
+	}
+}
+
+func TestNotStruct(t *testing.T) {
+	var out bytes.Buffer
+	require.Error(t, NewEncoder(&out).Encode(42))
+	var s testQuery
+	require.Error(t, NewDecoder(strings.NewReader(`{}`)).Decode(s))
+	var n int
+	require.Error(t, NewDecoder(strings.NewReader(`{}`)).Decode(&n))
+}
+
+func TestNewQueryDecoder(t *testing.T) {
+	queries := []testQuery{
+		{HumanLabel: []byte("first"), Timeout: time.Second},
+		{HumanLabel: []byte("second"), Count: 2},
+	}
+	cases := []struct {
+		name  string
+		jsonl bool
+	}{
+		{name: "gob", jsonl: false},
+		{name: "jsonl", jsonl: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var enc QueryEncoder = gob.NewEncoder(&out)
+			if c.jsonl {
+				enc = NewEncoder(&out)
+			}
+			for i := range queries {
+				require.NoError(t, enc.Encode(&queries[i]))
+			}
+			require.Equal(t, c.jsonl, IsJSONL(bufio.NewReader(bytes.NewReader(out.Bytes()))))
+
+			dec := NewQueryDecoder(&out)
+			for i := range queries {
+				var q testQueryV2
+				require.NoError(t, dec.Decode(&q))
+				require.Equal(t, string(queries[i].HumanLabel), string(q.HumanLabel))
+				require.Equal(t, queries[i].Timeout, q.Timeout)
+			}
+			var q testQueryV2
+			require.Equal(t, io.EOF, dec.Decode(&q))
+		})
+	}
+}
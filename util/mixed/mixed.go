@@ -0,0 +1,128 @@
+// Package mixed holds the helpers of benchmark_mixed to feed the bulk loader
+// and to multiplex the output of the concurrent runs.
+package mixed
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// feedChunk is the number of lines written between rate and deadline checks.
+const feedChunk = 100
+
+// Feed copies the lines of r to w at up to rate lines per second (unlimited
+// if 0) until r is exhausted or the deadline, and returns the number of
+// lines copied.
+func Feed(w io.Writer, r io.Reader, rate float64, deadline time.Time) (int64, error) {
+	br := bufio.NewReaderSize(r, 4<<20)
+	bw := bufio.NewWriterSize(w, 4<<20)
+	start := time.Now()
+	var lines int64
+	for {
+		for i := 0; i < feedChunk; i++ {
+			line, err := br.ReadSlice('\n')
+			if err == bufio.ErrBufferFull {
+				// longer line than the buffer, copy it as it comes
+				if _, err := bw.Write(line); err != nil {
+					return lines, err
+				}
+				i--
+				continue
+			}
+			if len(line) > 0 {
+				if _, err := bw.Write(line); err != nil {
+					return lines, err
+				}
+				lines++
+			}
+			if err == io.EOF {
+				return lines, bw.Flush()
+			}
+			if err != nil {
+				return lines, err
+			}
+		}
+
+		now := time.Now()
+		if rate > 0 {
+			// wait until the lines are due
+			due := start.Add(time.Duration(float64(lines) / rate * float64(time.Second)))
+			if due.After(now) {
+				if err := bw.Flush(); err != nil {
+					return lines, err
+				}
+				if due.After(deadline) {
+					due = deadline
+				}
+				time.Sleep(due.Sub(now))
+				now = due
+			}
+		}
+		if !now.Before(deadline) {
+			return lines, bw.Flush()
+		}
+	}
+}
+
+// PrefixWriter writes the lines of the output of a run prefixed with its
+// name, not to interleave the lines of concurrent runs.
+type PrefixWriter struct {
+	prefix  string
+	w       io.Writer
+	partial []byte
+}
+
+// outputMutex serializes the lines written by all the PrefixWriters.
+var outputMutex sync.Mutex
+
+func NewPrefixWriter(prefix string, w io.Writer) *PrefixWriter {
+	return &PrefixWriter{prefix: prefix, w: w}
+}
+
+func (p *PrefixWriter) Write(data []byte) (int, error) {
+	n := len(data)
+	p.partial = append(p.partial, data...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		p.writeLine(p.partial[:i+1])
+		p.partial = p.partial[i+1:]
+	}
+	return n, nil
+}
+
+// Flush writes the last line, if not terminated.
+func (p *PrefixWriter) Flush() {
+	if len(p.partial) > 0 {
+		p.writeLine(append(p.partial, '\n'))
+		p.partial = nil
+	}
+}
+
+func (p *PrefixWriter) writeLine(line []byte) {
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+	io.WriteString(p.w, p.prefix)
+	p.w.Write(line)
+}
+
+// HasFlag tells whether args set a flag, as -name or --name, with or without
+// a value.
+func HasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		arg = strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if arg == name {
+			return true
+		}
+	}
+	return false
+}
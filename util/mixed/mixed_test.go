@@ -0,0 +1,112 @@
+package mixed
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lines(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestFeed(t *testing.T) {
+	long := strings.Repeat("x", 5<<20) // longer than the read buffer
+	cases := []struct {
+		name   string
+		input  string
+		copied int64
+	}{
+		{name: "empty", input: "", copied: 0},
+		{name: "lines", input: lines(250), copied: 250},
+		{name: "unterminated last line", input: "a\nb", copied: 2},
+		{name: "line longer than the buffer", input: "a\n" + long + "\nb\n", copied: 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			n, err := Feed(&out, strings.NewReader(c.input), 0, time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			require.Equal(t, c.copied, n)
+			require.True(t, c.input == out.String(), "output differs from the input")
+		})
+	}
+}
+
+func TestFeedRate(t *testing.T) {
+	// the lines after the first 100 are due at 2000 lines per second
+	var out bytes.Buffer
+	start := time.Now()
+	n, err := Feed(&out, strings.NewReader(lines(500)), 2000, start.Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(500), n)
+	require.True(t, time.Since(start) >= 200*time.Millisecond, "fed in %s", time.Since(start))
+	require.Equal(t, lines(500), out.String())
+}
+
+func TestFeedDeadline(t *testing.T) {
+	var out bytes.Buffer
+	start := time.Now()
+	n, err := Feed(&out, strings.NewReader(lines(100000)), 1000, start.Add(250*time.Millisecond))
+	require.NoError(t, err)
+	took := time.Since(start)
+	require.True(t, took >= 250*time.Millisecond && took < 2*time.Second, "fed in %s", took)
+	require.True(t, n > 0 && n <= 400, "%d lines fed", n)
+	require.Equal(t, int64(0), n%feedChunk, "lines are fed by chunks")
+	require.Equal(t, lines(int(n)), out.String(), "the lines fed are flushed")
+}
+
+func TestPrefixWriter(t *testing.T) {
+	cases := []struct {
+		name   string
+		writes []string
+		output string
+	}{
+		{name: "lines", writes: []string{"a\nb\n"}, output: "[run] a\n[run] b\n"},
+		{name: "partial lines", writes: []string{"a", "b\nc", "d", "\n"}, output: "[run] ab\n[run] cd\n"},
+		{name: "unterminated last line", writes: []string{"a\nb"}, output: "[run] a\n[run] b\n"},
+		{name: "empty lines", writes: []string{"\n\n"}, output: "[run] \n[run] \n"},
+		{name: "nothing", output: ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := NewPrefixWriter("[run] ", &out)
+			for _, s := range c.writes {
+				n, err := w.Write([]byte(s))
+				require.NoError(t, err)
+				require.Equal(t, len(s), n)
+			}
+			w.Flush()
+			require.Equal(t, c.output, out.String())
+		})
+	}
+}
+
+func TestHasFlag(t *testing.T) {
+	cases := []struct {
+		args []string
+		has  bool
+	}{
+		{args: []string{"bulk_load_influx", "-file", "data.txt"}, has: true},
+		{args: []string{"bulk_load_influx", "--file", "data.txt"}, has: true},
+		{args: []string{"bulk_load_influx", "-file=data.txt"}, has: true},
+		{args: []string{"bulk_load_influx", "--file=data.txt"}, has: true},
+		{args: []string{"bulk_load_influx", "-urls", "http://localhost:8086"}, has: false},
+		{args: []string{"bulk_load_influx", "-files", "data.txt"}, has: false},
+		{args: []string{"bulk_load_influx", "-url", "file"}, has: false},
+		{args: nil, has: false},
+	}
+	for _, c := range cases {
+		t.Run(strings.Join(c.args, " "), func(t *testing.T) {
+			require.Equal(t, c.has, HasFlag(c.args, "file"))
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package report
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResourcesMeasurement is the measurement of the resource usage points.
+const ResourcesMeasurement = "benchmarks_resources"
+
+// clockTicks is the unit of the process CPU times in /proc (USER_HZ).
+const clockTicks = 100
+
+// ResourceSampler samples the resource usage of the client machine and of
+// the benchmark process, and optionally of a local target process, emitting
+// points tagged by scope (host, client or target):
+//
+//	host: cpu_usage_percent (of all the cores), mem_used_bytes,
+//	      mem_available_bytes, net_rx_bytes_rate, net_tx_bytes_rate
+//	client: cpu_usage_percent (of one core), rss_bytes, heap_alloc_bytes,
+//	      gc_count, gc_pause_ms (during the interval), goroutines
+//	target: cpu_usage_percent (of one core), rss_bytes
+//
+// Host and process statistics are read from /proc, so they are available on
+// Linux only.
+type ResourceSampler struct {
+	pid  int
+	tags [][2]string
+
+	last         time.Time
+	hostCPU      cpuTimes
+	netRx, netTx uint64
+	clientTicks  uint64
+	targetTicks  uint64
+	gcCount      uint32
+	gcPauseNs    uint64
+
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+type cpuTimes struct {
+	total, idle uint64
+}
+
+// NewResourceSampler creates a sampler adding tags to its points. A target
+// pid of 0 samples no target process.
+func NewResourceSampler(pid int, tags [][2]string) *ResourceSampler {
+	return &ResourceSampler{pid: pid, tags: tags, stop: make(chan struct{})}
+}
+
+// RunAsync sends the resource usage to sink every interval, until stopped.
+// Points are taken from the GlobalPointPool.
+func (s *ResourceSampler) RunAsync(sink chan *Point, interval time.Duration) {
+	s.sample(nil)
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.sample(sink)
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the sampling, returning once no more points are sent.
+func (s *ResourceSampler) Stop() {
+	close(s.stop)
+	s.done.Wait()
+}
+
+// sample reads the resource counters and sends the usage since the previous
+// sample to sink, unless nil.
+func (s *ResourceSampler) sample(sink chan *Point) {
+	now := time.Now()
+	elapsed := now.Sub(s.last).Seconds()
+	s.last = now
+
+	host := s.newPoint(now, "host")
+	if cpu, err := readHostCPU(); err == nil {
+		if total := cpu.total - s.hostCPU.total; total > 0 && s.hostCPU.total > 0 {
+			host.AddFloat64Field("cpu_usage_percent", 100*(1-float64(cpu.idle-s.hostCPU.idle)/float64(total)))
+		}
+		s.hostCPU = cpu
+	}
+	if total, available, err := readMemInfo(); err == nil {
+		host.AddInt64Field("mem_used_bytes", int64(total-available))
+		host.AddInt64Field("mem_available_bytes", int64(available))
+	}
+	if rx, tx, err := readNetDev(); err == nil {
+		if s.netRx > 0 || s.netTx > 0 {
+			host.AddFloat64Field("net_rx_bytes_rate", float64(rx-s.netRx)/elapsed)
+			host.AddFloat64Field("net_tx_bytes_rate", float64(tx-s.netTx)/elapsed)
+		}
+		s.netRx, s.netTx = rx, tx
+	}
+
+	client := s.newPoint(now, "client")
+	if ticks, rss, err := readProcStat("self"); err == nil {
+		if s.clientTicks > 0 {
+			client.AddFloat64Field("cpu_usage_percent", 100*float64(ticks-s.clientTicks)/clockTicks/elapsed)
+		}
+		client.AddInt64Field("rss_bytes", rss)
+		s.clientTicks = ticks
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	client.AddInt64Field("heap_alloc_bytes", int64(mem.HeapAlloc))
+	client.AddInt64Field("gc_count", int64(mem.NumGC-s.gcCount))
+	client.AddFloat64Field("gc_pause_ms", float64(mem.PauseTotalNs-s.gcPauseNs)/1e6)
+	client.AddIntField("goroutines", runtime.NumGoroutine())
+	s.gcCount, s.gcPauseNs = mem.NumGC, mem.PauseTotalNs
+
+	var target *Point
+	if s.pid > 0 {
+		target = s.newPoint(now, "target")
+		target.AddTag("pid", strconv.Itoa(s.pid))
+		if ticks, rss, err := readProcStat(strconv.Itoa(s.pid)); err == nil {
+			if s.targetTicks > 0 {
+				target.AddFloat64Field("cpu_usage_percent", 100*float64(ticks-s.targetTicks)/clockTicks/elapsed)
+			}
+			target.AddInt64Field("rss_bytes", rss)
+			s.targetTicks = ticks
+		}
+	}
+
+	for _, p := range []*Point{host, client, target} {
+		if p == nil {
+			continue
+		}
+		if sink == nil || len(p.Fields) == 0 {
+			PutPointIntoGlobalPool(p)
+			continue
+		}
+		sink <- p
+	}
+}
+
+func (s *ResourceSampler) newPoint(now time.Time, scope string) *Point {
+	p := GetPointFromGlobalPool()
+	p.Init(ResourcesMeasurement, now.UnixNano())
+	for _, tag := range s.tags {
+		p.AddTag(tag[0], tag[1])
+	}
+	p.AddTag("scope", scope)
+	return p
+}
+
+// readHostCPU reads the aggregated CPU times of /proc/stat.
+func readHostCPU() (cpuTimes, error) {
+	data, err := ioutil.ReadFile("/proc/stat")
+	if err != nil {
+		return cpuTimes{}, err
+	}
+	return parseHostCPU(data)
+}
+
+// parseHostCPU parses the aggregated CPU times, the first line of /proc/stat.
+func parseHostCPU(data []byte) (cpuTimes, error) {
+	line := string(data)
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return cpuTimes{}, fmt.Errorf("unexpected /proc/stat line: %s", line)
+	}
+	var t cpuTimes
+	// user nice system idle iowait irq softirq steal; guest times are
+	// included in user
+	for i := 1; i < len(fields) && i <= 8; i++ {
+		v, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return cpuTimes{}, err
+		}
+		t.total += v
+		if i == 4 || i == 5 {
+			t.idle += v
+		}
+	}
+	return t, nil
+}
+
+// readMemInfo reads the total and available memory of /proc/meminfo, in
+// bytes.
+func readMemInfo() (total, available uint64, err error) {
+	data, err := ioutil.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseMemInfo(data)
+}
+
+// parseMemInfo parses the total and available memory of /proc/meminfo, in
+// bytes.
+func parseMemInfo(data []byte) (total, available uint64, err error) {
+	found := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		var dst *uint64
+		switch fields[0] {
+		case "MemTotal:":
+			dst = &total
+		case "MemAvailable:":
+			dst = &available
+		default:
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		*dst = v * 1024
+		found++
+	}
+	if found < 2 {
+		return 0, 0, fmt.Errorf("incomplete /proc/meminfo")
+	}
+	return total, available, nil
+}
+
+// readNetDev reads the bytes received and transmitted by all the network
+// interfaces but loopback of /proc/net/dev.
+func readNetDev() (rx, tx uint64, err error) {
+	data, err := ioutil.ReadFile("/proc/net/dev")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseNetDev(data)
+}
+
+// parseNetDev parses the bytes received and transmitted by all the network
+// interfaces but loopback of /proc/net/dev.
+func parseNetDev(data []byte) (rx, tx uint64, err error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		i := strings.Index(line, ":")
+		if i < 0 || strings.TrimSpace(line[:i]) == "lo" {
+			continue
+		}
+		fields := strings.Fields(line[i+1:])
+		if len(fields) < 9 {
+			continue
+		}
+		r, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		t, err := strconv.ParseUint(fields[8], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+		rx += r
+		tx += t
+	}
+	return rx, tx, nil
+}
+
+// readProcStat reads the user and system CPU time, in clock ticks, and the
+// resident set size, in bytes, of a process of /proc.
+func readProcStat(pid string) (ticks uint64, rss int64, err error) {
+	data, err := ioutil.ReadFile("/proc/" + pid + "/stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	ticks, pages, err := parseProcStat(data)
+	if err != nil {
+		return 0, 0, fmt.Errorf("/proc/%s/stat: %v", pid, err)
+	}
+	return ticks, pages * int64(os.Getpagesize()), nil
+}
+
+// parseProcStat parses the user and system CPU time, in clock ticks, and the
+// resident set size, in pages, of the stat file of a process.
+func parseProcStat(data []byte) (ticks uint64, pages int64, err error) {
+	// the command name may contain spaces, the fields follow its last ')'
+	i := strings.LastIndex(string(data), ")")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("no command name")
+	}
+	fields := strings.Fields(string(data[i+1:]))
+	if len(fields) < 22 {
+		return 0, 0, fmt.Errorf("%d fields after the command name, expected at least 22", len(fields))
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	pages, err = strconv.ParseInt(fields[21], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return utime + stime, pages, nil
+}
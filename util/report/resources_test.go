@@ -0,0 +1,136 @@
+package report
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseHostCPU(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		cpu  cpuTimes
+		err  bool
+	}{
+		{
+			name: "stat",
+			data: `cpu  4705 150 1120 16250 520 30 45 10 5 0
+cpu0 2352 75 560 8125 260 15 22 5 2 0
+intr 114930548 113199788 3 0 5 263 0 4 [... lots more numbers ...]
+ctxt 1990473
+`,
+			cpu: cpuTimes{total: 4705 + 150 + 1120 + 16250 + 520 + 30 + 45 + 10, idle: 16250 + 520},
+		},
+		{
+			name: "no steal time",
+			data: "cpu 100 0 50 800 50\n",
+			cpu:  cpuTimes{total: 1000, idle: 850},
+		},
+		{name: "not the aggregated cpu", data: "cpu0 100 0 50 800 50\n", err: true},
+		{name: "too few fields", data: "cpu 100 0 50\n", err: true},
+		{name: "invalid time", data: "cpu 100 x 50 800 50\n", err: true},
+		{name: "empty", data: "", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cpu, err := parseHostCPU([]byte(c.data))
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.cpu, cpu)
+		})
+	}
+}
+
+func TestParseMemInfo(t *testing.T) {
+	cases := []struct {
+		name      string
+		data      string
+		total     uint64
+		available uint64
+		err       bool
+	}{
+		{
+			name: "meminfo",
+			data: `MemTotal:       16314172 kB
+MemFree:         8155024 kB
+MemAvailable:   12264948 kB
+Buffers:          404732 kB
+`,
+			total:     16314172 * 1024,
+			available: 12264948 * 1024,
+		},
+		{name: "no available memory", data: "MemTotal:       16314172 kB\nMemFree:         8155024 kB\n", err: true},
+		{name: "invalid total", data: "MemTotal: x kB\nMemAvailable: 1 kB\n", err: true},
+		{name: "empty", data: "", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			total, available, err := parseMemInfo([]byte(c.data))
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.total, total)
+			require.Equal(t, c.available, available)
+		})
+	}
+}
+
+func TestParseNetDev(t *testing.T) {
+	data := `Inter-|   Receive                                                |  Transmit
+ face |bytes    packets errs drop fifo frame compressed multicast|bytes    packets errs drop fifo colls carrier compressed
+    lo: 9876543   12345    0    0    0     0          0         0  9876543   12345    0    0    0     0       0          0
+  eth0: 1000000    2000    0    0    0     0          0         0   300000    1500    0    0    0     0       0          0
+ wlan0:     500      10    0    0    0     0          0         0      250       5    0    0    0     0       0          0
+`
+	rx, tx, err := parseNetDev([]byte(data))
+	require.NoError(t, err)
+	// loopback is excluded
+	require.Equal(t, uint64(1000500), rx)
+	require.Equal(t, uint64(300250), tx)
+
+	_, _, err = parseNetDev([]byte("  eth0: x 2000 0 0 0 0 0 0 300000 1500 0 0 0 0 0 0\n"))
+	require.Error(t, err)
+}
+
+func TestParseProcStat(t *testing.T) {
+	cases := []struct {
+		name  string
+		data  string
+		ticks uint64
+		pages int64
+		err   bool
+	}{
+		{
+			name:  "stat",
+			data:  "4242 (bulk_load_influx) S 1 4242 4242 0 -1 4194560 2506 0 0 0 120 30 0 0 20 0 12 0 98765 1234567890 5120 18446744073709551615\n",
+			ticks: 150,
+			pages: 5120,
+		},
+		{
+			name:  "command name with spaces and parentheses",
+			data:  "77 (my (odd) cmd) R 1 77 77 0 -1 4194560 10 0 0 0 7 3 0 0 20 0 1 0 100 4096 42 18446744073709551615\n",
+			ticks: 10,
+			pages: 42,
+		},
+		{name: "no command name", data: "77 cmd R 1 77 77 0 -1 4194560 10 0 0 0 7 3 0 0 20 0 1 0 100 4096 42\n", err: true},
+		{name: "too few fields", data: "77 (cmd) R 1 77 77 0 -1 4194560 10 0 0 0 7 3\n", err: true},
+		{name: "invalid rss", data: "77 (cmd) R 1 77 77 0 -1 4194560 10 0 0 0 7 3 0 0 20 0 1 0 100 4096 x\n", err: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ticks, pages, err := parseProcStat([]byte(c.data))
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.ticks, ticks)
+			require.Equal(t, c.pages, pages)
+		})
+	}
+}
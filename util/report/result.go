@@ -21,6 +21,9 @@ type ReportParams struct {
 	ReportAuthToken    string
 	Workers            int
 	ItemLimit          int
+	// Sink receives the report; when nil, it is sent to the InfluxDB at
+	// ReportHost
+	Sink Sink
 }
 
 // LoadReportParams is holder of bulk load specific parameters
@@ -43,7 +46,7 @@ type ExtraVal struct {
 	Value interface{}
 }
 
-// ReportLoadResult send results from bulk load to an influxdb according to the given parameters
+// ReportLoadResult send results from bulk load to the report sink (an influxdb by default) according to the given parameters
 func ReportLoadResult(params *LoadReportParams, totalItems int64, valueRate float64, inputSpeed float64, loadDuration time.Duration, extraVals ...ExtraVal) error {
 
 	c, p, err := initReport(&params.ReportParams, "load_benchmarks")
@@ -79,17 +82,19 @@ func ReportLoadResult(params *LoadReportParams, totalItems int64, valueRate floa
 
 }
 
-// initReport prepares a Point and a Collector instance for sending a result report
-func initReport(params *ReportParams, measurement string) (*Collector, *Point, error) {
-	var c *Collector
-	if params.ReportOrgId == "" {
-		c = NewCollector(params.ReportHost, params.ReportDatabaseName, params.ReportUser, params.ReportPassword)
-	} else {
-		c = NewCollectorV2(params.ReportHost, params.ReportOrgId, params.ReportDatabaseName, params.ReportAuthToken)
-	}
-	err := c.CreateDatabase()
-	if err != nil {
-		return nil, nil, err
+// initReport prepares a Point and a Sink instance for sending a result report
+func initReport(params *ReportParams, measurement string) (Sink, *Point, error) {
+	s := params.Sink
+	if s == nil {
+		if params.ReportOrgId == "" {
+			c, err := NewInfluxSink(params.ReportHost, params.ReportDatabaseName, params.ReportUser, params.ReportPassword)
+			if err != nil {
+				return nil, nil, err
+			}
+			s = c
+		} else {
+			s = NewInfluxSinkV2(params.ReportHost, params.ReportOrgId, params.ReportDatabaseName, params.ReportAuthToken)
+		}
 	}
 
 	p := GetPointFromGlobalPool()
@@ -107,15 +112,12 @@ func initReport(params *ReportParams, measurement string) (*Collector, *Point, e
 	p.AddTag("item_limit", strconv.Itoa(params.ItemLimit))
 	p.AddTag("workers", strconv.Itoa(params.Workers))
 
-	return c, p, nil
+	return s, p, nil
 }
 
 //finishReport finalizes sending result report and cleaning data
-func finishReport(c *Collector, p *Point) error {
-	c.Put(p)
-	c.PrepBatch()
-
-	err := c.SendBatch()
+func finishReport(s Sink, p *Point) error {
+	err := s.Write([]*Point{p})
 
 	PutPointIntoGlobalPool(p)
 
@@ -142,7 +144,7 @@ func Escape(s string) string {
 	}
 }
 
-//ReportQueryResult send result from bulk query benchmark to an influxdb according to the given parameters
+//ReportQueryResult send result from bulk query benchmark to the report sink (an influxdb by default) according to the given parameters
 func ReportQueryResult(params *QueryReportParams, queryName string, minQueryTime float64, meanQueryTime float64, maxQueryTime float64, totalQueries int64, queryDuration time.Duration, extraVals ...ExtraVal) error {
 
 	c, p, err := initReport(&params.ReportParams, "query_benchmarks")
@@ -0,0 +1,410 @@
+package report
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink receives result report and telemetry points. Points are owned by the
+// caller and may be reused once Write returns.
+type Sink interface {
+	Write(points []*Point) error
+	// Close flushes the sink and releases its resources.
+	Close() error
+}
+
+// Sink kinds selectable by the -report-sink flag of the benchmarkers, given
+// as kind or kind=argument:
+const (
+	SinkJSON       = "json"       // json=file: JSON object per point and line
+	SinkCSV        = "csv"        // csv=file: row per point field
+	SinkPrometheus = "prometheus" // prometheus=address: text exposition endpoint at /metrics
+	SinkStdout     = "stdout"     // line protocol to the standard output
+)
+
+// NewSinks creates the sinks of a comma separated list of sink specs, e.g.
+// "json=results.json,prometheus=:9273". The prometheus endpoint keeps
+// serving for linger after the sink is closed, so that the final results can
+// be scraped.
+func NewSinks(specs string, linger time.Duration) ([]Sink, error) {
+	var sinks []Sink
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		kind, arg := spec, ""
+		if i := strings.Index(spec, "="); i >= 0 {
+			kind, arg = spec[:i], spec[i+1:]
+		}
+		var s Sink
+		var err error
+		switch kind {
+		case SinkJSON:
+			s, err = NewJSONSink(arg)
+		case SinkCSV:
+			s, err = NewCSVSink(arg)
+		case SinkPrometheus:
+			s, err = NewPrometheusSink(arg, linger)
+		case SinkStdout:
+			s = NewLineProtocolSink(os.Stdout)
+		default:
+			err = fmt.Errorf("unknown report sink: %s", kind)
+		}
+		if err != nil {
+			for _, s := range sinks {
+				s.Close()
+			}
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+// MultiSink writes points to all its sinks.
+type MultiSink []Sink
+
+func (m MultiSink) Write(points []*Point) error {
+	var firstErr error
+	for _, s := range m {
+		if err := s.Write(points); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m MultiSink) Close() error {
+	var firstErr error
+	for _, s := range m {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// InfluxSink sends points to an InfluxDB through a Collector.
+type InfluxSink struct {
+	mutex     sync.Mutex
+	collector *Collector
+}
+
+// NewInfluxSink creates a sink writing to the database of an InfluxDB 1.x,
+// creating the database.
+func NewInfluxSink(host, database, user, password string) (*InfluxSink, error) {
+	c := NewCollector(host, database, user, password)
+	if err := c.CreateDatabase(); err != nil {
+		return nil, err
+	}
+	return &InfluxSink{collector: c}, nil
+}
+
+// NewInfluxSinkV2 creates a sink writing to an existing bucket of an
+// InfluxDB 2.
+func NewInfluxSinkV2(host, orgId, bucketId, authToken string) *InfluxSink {
+	return &InfluxSink{collector: NewCollectorV2(host, orgId, bucketId, authToken)}
+}
+
+func (s *InfluxSink) Write(points []*Point) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	defer s.collector.Reset()
+	for _, p := range points {
+		s.collector.Put(p)
+	}
+	s.collector.PrepBatch()
+	return s.collector.SendBatch()
+}
+
+func (s *InfluxSink) Close() error {
+	return nil
+}
+
+// LineProtocolSink writes points as InfluxDB line protocol.
+type LineProtocolSink struct {
+	mutex sync.Mutex
+	w     io.Writer
+}
+
+func NewLineProtocolSink(w io.Writer) *LineProtocolSink {
+	return &LineProtocolSink{w: w}
+}
+
+func (s *LineProtocolSink) Write(points []*Point) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, p := range points {
+		p.Serialize(s.w)
+		if _, err := fmt.Fprint(s.w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LineProtocolSink) Close() error {
+	return nil
+}
+
+var unescaper = strings.NewReplacer(
+	`\t`, "\t",
+	`\n`, "\n",
+	`\f`, "\f",
+	`\r`, "\r",
+	`\,`, `,`,
+	`\ `, ` `,
+	`\=`, `=`,
+)
+
+// Unescape reverts Escape.
+func Unescape(s string) string {
+	if strings.Contains(s, `\`) {
+		return unescaper.Replace(s)
+	}
+	return s
+}
+
+// value returns the value of a field as int64, float64 or bool.
+func (f *Field) value() interface{} {
+	switch f.mode {
+	case int64ValueKind:
+		return f.int64Value
+	case float64ValueKind:
+		return f.float64Value
+	default:
+		return f.boolValue
+	}
+}
+
+// JSONSink writes a JSON object per point and line to a file.
+type JSONSink struct {
+	mutex sync.Mutex
+	file  *os.File
+	enc   *json.Encoder
+}
+
+type jsonPoint struct {
+	Measurement string                 `json:"measurement"`
+	Time        string                 `json:"time"`
+	Tags        map[string]string      `json:"tags"`
+	Fields      map[string]interface{} `json:"fields"`
+}
+
+func NewJSONSink(path string) (*JSONSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("json report sink needs a file: json=<file>")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONSink{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (s *JSONSink) Write(points []*Point) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, p := range points {
+		jp := jsonPoint{
+			Measurement: p.Measurement,
+			Time:        time.Unix(0, p.TimestampNano).UTC().Format(time.RFC3339Nano),
+			Tags:        make(map[string]string, len(p.Tags)),
+			Fields:      make(map[string]interface{}, len(p.Fields)),
+		}
+		for _, t := range p.Tags {
+			jp.Tags[t.Key] = Unescape(t.Value)
+		}
+		for i := range p.Fields {
+			jp.Fields[p.Fields[i].key] = p.Fields[i].value()
+		}
+		if err := s.enc.Encode(&jp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *JSONSink) Close() error {
+	return s.file.Close()
+}
+
+// CSVSink writes a row per field of every point to a file, with the columns
+// time, measurement, tags (comma separated key=value pairs, escaped as in
+// line protocol), field and value.
+type CSVSink struct {
+	mutex sync.Mutex
+	file  *os.File
+	w     *csv.Writer
+}
+
+func NewCSVSink(path string) (*CSVSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("csv report sink needs a file: csv=<file>")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &CSVSink{file: f, w: csv.NewWriter(f)}
+	if err := s.w.Write([]string{"time", "measurement", "tags", "field", "value"}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *CSVSink) Write(points []*Point) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, p := range points {
+		ts := time.Unix(0, p.TimestampNano).UTC().Format(time.RFC3339Nano)
+		tags := make([]string, 0, len(p.Tags))
+		for _, t := range p.Tags {
+			tags = append(tags, t.Key+"="+t.Value)
+		}
+		joined := strings.Join(tags, ",")
+		for i := range p.Fields {
+			value := fmt.Sprintf("%v", p.Fields[i].value())
+			if err := s.w.Write([]string{ts, p.Measurement, joined, p.Fields[i].key, value}); err != nil {
+				return err
+			}
+		}
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *CSVSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+// PrometheusSink serves the latest value of every field of the points in
+// the Prometheus text exposition format. A field becomes a gauge named
+// <measurement>_<field>, labeled with the tags of its point.
+type PrometheusSink struct {
+	mutex  sync.Mutex
+	series map[string]map[string]float64 // metric name -> labels -> value
+	server *http.Server
+	linger time.Duration
+}
+
+func NewPrometheusSink(address string, linger time.Duration) (*PrometheusSink, error) {
+	if address == "" {
+		return nil, fmt.Errorf("prometheus report sink needs a listen address: prometheus=<address>")
+	}
+	s := &PrometheusSink{
+		series: make(map[string]map[string]float64),
+		linger: linger,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.serveMetrics)
+	s.server = &http.Server{Addr: address, Handler: mux}
+	// listen at once, so that an unusable address fails the sink creation
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("prometheus report sink: %v", err)
+	}
+	go func() {
+		if err := s.server.Serve(l); err != nil && err != http.ErrServerClosed {
+			log.Printf("prometheus report sink error: %v", err)
+		}
+	}()
+	return s, nil
+}
+
+func (s *PrometheusSink) Write(points []*Point) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, p := range points {
+		labels := make([]string, 0, len(p.Tags))
+		for _, t := range p.Tags {
+			labels = append(labels, fmt.Sprintf("%s=\"%s\"", prometheusName(t.Key), prometheusEscaper.Replace(Unescape(t.Value))))
+		}
+		sort.Strings(labels)
+		joined := strings.Join(labels, ",")
+		for i := range p.Fields {
+			var v float64
+			switch x := p.Fields[i].value().(type) {
+			case int64:
+				v = float64(x)
+			case float64:
+				v = x
+			case bool:
+				if x {
+					v = 1
+				}
+			}
+			name := prometheusName(p.Measurement + "_" + p.Fields[i].key)
+			if s.series[name] == nil {
+				s.series[name] = make(map[string]float64)
+			}
+			s.series[name][joined] = v
+		}
+	}
+	return nil
+}
+
+func (s *PrometheusSink) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	names := make([]string, 0, len(s.series))
+	for name := range s.series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+		labelSets := make([]string, 0, len(s.series[name]))
+		for labels := range s.series[name] {
+			labelSets = append(labelSets, labels)
+		}
+		sort.Strings(labelSets)
+		for _, labels := range labelSets {
+			fmt.Fprintf(w, "%s{%s} %s\n", name, labels, strconv.FormatFloat(s.series[name][labels], 'g', -1, 64))
+		}
+	}
+}
+
+func (s *PrometheusSink) Close() error {
+	if s.linger > 0 {
+		log.Printf("serving prometheus metrics at %s for %s", s.server.Addr, s.linger)
+		time.Sleep(s.linger)
+	}
+	return s.server.Shutdown(context.Background())
+}
+
+var prometheusEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// prometheusName replaces the characters not allowed in Prometheus metric
+// and label names by underscores.
+func prometheusName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == ':' || c >= '0' && c <= '9' && i > 0) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
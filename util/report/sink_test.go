@@ -0,0 +1,144 @@
+package report
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testPoints() []*Point {
+	ts := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	p1 := &Point{}
+	p1.Init("query_result", ts)
+	p1.AddTag("query", Escape("max cpu, 1 host"))
+	p1.AddTag("worker-id", "1")
+	p1.AddInt64Field("count", 42)
+	p1.AddFloat64Field("mean_ms", 1.5)
+	p1.AddBoolField("verified", true)
+	p2 := &Point{}
+	p2.Init("query_result", ts+int64(time.Second))
+	p2.AddTag("query", `say "hi"`)
+	p2.AddTag("worker-id", "2")
+	p2.AddInt64Field("count", 7)
+	return []*Point{p1, p2}
+}
+
+func TestNewSinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sinks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	jsonFile := filepath.Join(dir, "results.json")
+	csvFile := filepath.Join(dir, "results.csv")
+
+	// an address in use
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	cases := []struct {
+		name  string
+		specs string
+		sinks []Sink
+		err   string
+	}{
+		{name: "none", specs: ""},
+		{name: "stdout", specs: "stdout", sinks: []Sink{&LineProtocolSink{}}},
+		{name: "several", specs: " json=" + jsonFile + " , csv=" + csvFile + ",", sinks: []Sink{&JSONSink{}, &CSVSink{}}},
+		{name: "prometheus", specs: "prometheus=127.0.0.1:0", sinks: []Sink{&PrometheusSink{}}},
+		{name: "unknown sink", specs: "json=" + jsonFile + ",xml=results.xml", err: "unknown report sink: xml"},
+		{name: "missing file", specs: "json", err: "json report sink needs a file"},
+		{name: "missing csv file", specs: "csv=", err: "csv report sink needs a file"},
+		{name: "missing address", specs: "prometheus", err: "needs a listen address"},
+		{name: "address in use", specs: "prometheus=" + l.Addr().String(), err: "address already in use"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sinks, err := NewSinks(c.specs, 0)
+			if c.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, sinks, len(c.sinks))
+			for i, s := range sinks {
+				require.IsType(t, c.sinks[i], s)
+				require.NoError(t, s.Close())
+			}
+		})
+	}
+}
+
+func TestFileSinks(t *testing.T) {
+	cases := []struct {
+		name     string
+		newSink  func(path string) (Sink, error)
+		expected string
+	}{
+		{
+			name:    "json",
+			newSink: func(path string) (Sink, error) { return NewJSONSink(path) },
+			expected: `{"measurement":"query_result","time":"2018-01-01T00:00:00Z","tags":{"query":"max cpu, 1 host","worker-id":"1"},"fields":{"count":42,"mean_ms":1.5,"verified":true}}
+{"measurement":"query_result","time":"2018-01-01T00:00:01Z","tags":{"query":"say \"hi\"","worker-id":"2"},"fields":{"count":7}}
+`,
+		},
+		{
+			name:    "csv",
+			newSink: func(path string) (Sink, error) { return NewCSVSink(path) },
+			expected: `time,measurement,tags,field,value
+2018-01-01T00:00:00Z,query_result,"query=max\ cpu\,\ 1\ host,worker-id=1",count,42
+2018-01-01T00:00:00Z,query_result,"query=max\ cpu\,\ 1\ host,worker-id=1",mean_ms,1.5
+2018-01-01T00:00:00Z,query_result,"query=max\ cpu\,\ 1\ host,worker-id=1",verified,true
+2018-01-01T00:00:01Z,query_result,"query=say ""hi"",worker-id=2",count,7
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "sink")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "results")
+
+			s, err := c.newSink(path)
+			require.NoError(t, err)
+			points := testPoints()
+			require.NoError(t, s.Write(points[:1]))
+			require.NoError(t, s.Write(points[1:]))
+			require.NoError(t, s.Close())
+
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, c.expected, string(data))
+		})
+	}
+}
+
+func TestPrometheusSink(t *testing.T) {
+	s := &PrometheusSink{series: make(map[string]map[string]float64)}
+	require.NoError(t, s.Write(testPoints()))
+	// the latest value of a series is served
+	p := &Point{}
+	p.Init("query_result", 0)
+	p.AddTag("worker-id", "2")
+	p.AddTag("query", `say "hi"`)
+	p.AddInt64Field("count", 8)
+	require.NoError(t, s.Write([]*Point{p}))
+
+	w := httptest.NewRecorder()
+	s.serveMetrics(w, httptest.NewRequest("GET", "/metrics", nil))
+	require.Equal(t, "text/plain; version=0.0.4", w.Header().Get("Content-Type"))
+	require.Equal(t, `# TYPE query_result_count gauge
+query_result_count{query="max cpu, 1 host",worker_id="1"} 42
+query_result_count{query="say \"hi\"",worker_id="2"} 8
+# TYPE query_result_mean_ms gauge
+query_result_mean_ms{query="max cpu, 1 host",worker_id="1"} 1.5
+# TYPE query_result_verified gauge
+query_result_verified{query="max cpu, 1 host",worker_id="1"} 1
+`, w.Body.String())
+}
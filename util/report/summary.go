@@ -0,0 +1,184 @@
+package report
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// SummaryVersion is the version of the Summary schema. Fields may be added
+// within a version; it is increased when fields are renamed, removed or
+// change their meaning.
+const SummaryVersion = 1
+
+// Summary kinds:
+const (
+	SummaryLoad  = "load"
+	SummaryQuery = "query"
+)
+
+// Summary is the machine-readable final summary of a bulk load or query
+// benchmark run, written as JSON. Durations are in seconds and query times
+// in milliseconds.
+type Summary struct {
+	Version        int                    `json:"version"`
+	Kind           string                 `json:"kind"`
+	Start          time.Time              `json:"start"`
+	End            time.Time              `json:"end"`
+	Duration       float64                `json:"duration"`
+	DBType         string                 `json:"database_type,omitempty"`
+	DestinationUrl string                 `json:"destination_url,omitempty"`
+	Workers        int                    `json:"workers"`
+	Parameters     map[string]string      `json:"parameters"`
+	Tags           map[string]string      `json:"tags"`
+	System         SystemInfo             `json:"system"`
+	Load           *LoadSummary           `json:"load,omitempty"`
+	Queries        []QuerySummary         `json:"queries,omitempty"`
+	Extra          map[string]interface{} `json:"extra,omitempty"`
+	Errors         []string               `json:"errors"`
+	PrematureEnd   string                 `json:"premature_end,omitempty"`
+	ExitCode       int                    `json:"exit_code"`
+}
+
+// SystemInfo describes the client machine.
+type SystemInfo struct {
+	Hostname   string `json:"hostname"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	GoVersion  string `json:"go_version"`
+	NumCPU     int    `json:"num_cpu"`
+	GOMAXPROCS int    `json:"gomaxprocs"`
+}
+
+// LoadSummary holds the throughput of a bulk load.
+type LoadSummary struct {
+	BatchSize  int     `json:"batch_size"`
+	Items      int64   `json:"items"`
+	Values     int64   `json:"values"`
+	Bytes      int64   `json:"bytes"`
+	ItemsRate  float64 `json:"items_rate"`
+	ValuesRate float64 `json:"values_rate"`
+	BytesRate  float64 `json:"bytes_rate"`
+}
+
+// QuerySummary holds the latency statistics of the queries of a label.
+type QuerySummary struct {
+	Label string                 `json:"label"`
+	Count int64                  `json:"count"`
+	Min   float64                `json:"min"`
+	Mean  float64                `json:"mean"`
+	Max   float64                `json:"max"`
+	Sum   float64                `json:"sum"`
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+// NewSummary creates a summary of the given kind, recording the system info
+// and the values of all the command line flags, with passwords and tokens
+// redacted.
+func NewSummary(kind string, start, end time.Time) *Summary {
+	hostname, _ := os.Hostname()
+	s := &Summary{
+		Version:    SummaryVersion,
+		Kind:       kind,
+		Start:      start.UTC(),
+		End:        end.UTC(),
+		Duration:   end.Sub(start).Seconds(),
+		Parameters: make(map[string]string),
+		Tags:       make(map[string]string),
+		System: SystemInfo{
+			Hostname:   hostname,
+			OS:         runtime.GOOS,
+			Arch:       runtime.GOARCH,
+			GoVersion:  runtime.Version(),
+			NumCPU:     runtime.NumCPU(),
+			GOMAXPROCS: runtime.GOMAXPROCS(-1),
+		},
+		Errors: []string{},
+	}
+	flag.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		name := strings.ToLower(f.Name)
+		if value != "" && (strings.Contains(name, "password") || strings.Contains(name, "token")) {
+			value = "***"
+		}
+		s.Parameters[f.Name] = value
+	})
+	return s
+}
+
+// SetParams records the database and tags of report params.
+func (s *Summary) SetParams(params *ReportParams) {
+	s.DBType = params.DBType
+	s.DestinationUrl = params.DestinationUrl
+	s.Workers = params.Workers
+	for _, tag := range params.ReportTags {
+		s.Tags[tag[0]] = Unescape(tag[1])
+	}
+}
+
+// SetExtraVals records extra values of the run.
+func (s *Summary) SetExtraVals(extraVals []ExtraVal) {
+	s.Extra = extraValsMap(extraVals)
+}
+
+// AddQuery records the latency statistics of the queries of a label.
+func (s *Summary) AddQuery(label string, count int64, min, mean, max, sum float64, extraVals []ExtraVal) {
+	s.Queries = append(s.Queries, QuerySummary{
+		Label: label,
+		Count: count,
+		Min:   min,
+		Mean:  mean,
+		Max:   max,
+		Sum:   sum,
+		Extra: extraValsMap(extraVals),
+	})
+}
+
+// AddError records an error of the run.
+func (s *Summary) AddError(err string) {
+	s.Errors = append(s.Errors, err)
+}
+
+// WriteFile writes the summary as indented JSON to path, replacing the file
+// at once so that readers never see a partial summary.
+func (s *Summary) WriteFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func extraValsMap(extraVals []ExtraVal) map[string]interface{} {
+	if len(extraVals) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(extraVals))
+	for _, v := range extraVals {
+		m[v.Name] = v.Value
+	}
+	return m
+}
@@ -1,38 +1,268 @@
 package report
 
 import (
+	"bufio"
+	"fmt"
 	"log"
 	"os"
+	"sync"
+	"time"
 )
 
-// TelemetryRunAsync runs a collection loop with many defaults already set. It will
-// abort the program if an error occurs. Assumes points are owned by the
+// TelemetryOptions configures how TelemetryRunAsync copes with a failing
+// sink.
+type TelemetryOptions struct {
+	// BufferSize is the maximum number of points held in memory while the
+	// sink is failing; the oldest ones are spilled (or dropped) beyond it.
+	BufferSize int
+	// MaxRetries is the number of retries of a batch, with the Backoff
+	// doubled after every retry up to MaxBackoff, before spilling it.
+	MaxRetries int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// SpillFile is appended with the points which could not be delivered,
+	// in line protocol, to be imported later. They are dropped if empty.
+	SpillFile string
+}
+
+var DefaultTelemetryOptions = TelemetryOptions{
+	BufferSize: 100000,
+	MaxRetries: 5,
+	Backoff:    time.Second,
+	MaxBackoff: time.Minute,
+}
+
+// TelemetryStats counts the telemetry points by outcome. Sent points were
+// delivered to all the sinks; the spilled and dropped ones may have reached
+// some of them.
+type TelemetryStats struct {
+	Sent    int64
+	Spilled int64
+	Dropped int64
+}
+
+// Print prints the counts of telemetry points by outcome to stdout, naming
+// the file the points were spilled to.
+func (s TelemetryStats) Print(spillFile string) {
+	fmt.Printf("telemetry: %d points sent", s.Sent)
+	if s.Spilled > 0 {
+		fmt.Printf(", %d spilled to %s", s.Spilled, spillFile)
+	}
+	fmt.Printf(", %d dropped\n", s.Dropped)
+}
+
+// telemetryQueue holds the batches waiting to be sent, up to a number of
+// points.
+type telemetryQueue struct {
+	mutex   sync.Mutex
+	batches [][]*Point
+	points  int
+	closed  bool
+	wake    chan struct{}
+}
+
+func (q *telemetryQueue) push(batch []*Point) {
+	q.mutex.Lock()
+	q.batches = append(q.batches, batch)
+	q.points += len(batch)
+	q.mutex.Unlock()
+	q.notify()
+}
+
+// popOverflow removes the oldest batch if there are more than max points.
+func (q *telemetryQueue) popOverflow(max int) []*Point {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.points <= max || len(q.batches) == 0 {
+		return nil
+	}
+	batch := q.batches[0]
+	q.batches = q.batches[1:]
+	q.points -= len(batch)
+	return batch
+}
+
+// pop removes the oldest batch, waiting for one. It returns nil once the
+// queue is closed and empty.
+func (q *telemetryQueue) pop() (batch []*Point, closed bool) {
+	for {
+		q.mutex.Lock()
+		if len(q.batches) > 0 {
+			batch = q.batches[0]
+			q.batches = q.batches[1:]
+			q.points -= len(batch)
+			closed = q.closed
+			q.mutex.Unlock()
+			return batch, closed
+		}
+		if q.closed {
+			q.mutex.Unlock()
+			return nil, true
+		}
+		q.mutex.Unlock()
+		<-q.wake
+	}
+}
+
+func (q *telemetryQueue) close() {
+	q.mutex.Lock()
+	q.closed = true
+	q.mutex.Unlock()
+	q.notify()
+}
+
+func (q *telemetryQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// telemetrySpill appends undeliverable points to the spill file, if any,
+// counting the dropped ones.
+type telemetrySpill struct {
+	mutex sync.Mutex
+	path  string
+	file  *os.File
+	stats *TelemetryStats
+}
+
+func (s *telemetrySpill) spill(batch []*Point) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	defer func() {
+		for _, p := range batch {
+			PutPointIntoGlobalPool(p)
+		}
+	}()
+	if s.path == "" {
+		s.stats.Dropped += int64(len(batch))
+		return
+	}
+	if s.file == nil {
+		f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Printf("telemetry error: cannot open spill file: %v", err)
+			s.stats.Dropped += int64(len(batch))
+			return
+		}
+		log.Printf("telemetry: spilling undelivered points to %s", s.path)
+		s.file = f
+	}
+	w := bufio.NewWriter(s.file)
+	for _, p := range batch {
+		p.Serialize(w)
+		w.WriteString("\n")
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("telemetry error: cannot write spill file: %v", err)
+		s.stats.Dropped += int64(len(batch))
+		return
+	}
+	s.stats.Spilled += int64(len(batch))
+}
+
+func (s *telemetrySpill) close() {
+	if s.file != nil {
+		s.file.Close()
+	}
+}
+
+// TelemetryRunAsync runs a collection loop with many defaults already set.
+// Sink errors never abort the program: batches are retried with backoff,
+// buffered up to opts.BufferSize points, then spilled to opts.SpillFile or
+// dropped. The counts of points by outcome are sent on done once src is
+// closed and the buffer flushed. Assumes points are owned by the
 // GlobalPointPool.
-func TelemetryRunAsync(c *Collector, batchSize uint64, writeToStderr bool, skipN uint64) (src chan *Point, done chan struct{}) {
+func TelemetryRunAsync(s Sink, batchSize uint64, writeToStderr bool, skipN uint64, opts TelemetryOptions) (src chan *Point, done chan TelemetryStats) {
 	src = make(chan *Point, 100)
-	done = make(chan struct{})
+	done = make(chan TelemetryStats, 1)
 
-	send := func() {
-		c.PrepBatch()
-		if writeToStderr {
-			_, err := os.Stderr.Write(c.buf.Bytes())
+	var stderr Sink
+	if writeToStderr {
+		stderr = NewLineProtocolSink(os.Stderr)
+	}
+	stats := &TelemetryStats{}
+	queue := &telemetryQueue{wake: make(chan struct{}, 1)}
+	spill := &telemetrySpill{path: opts.SpillFile, stats: stats}
+
+	enqueue := func(points []*Point) {
+		if stderr != nil {
+			err := stderr.Write(points)
 			if err != nil {
-				log.Fatalf("collector error (stderr): %v", err.Error())
+				log.Printf("telemetry error (stderr): %v", err.Error())
 			}
 		}
-
-		err := c.SendBatch()
-		if err != nil {
-			log.Fatalf("collector error (http): %v", err.Error())
+		batch := make([]*Point, len(points))
+		copy(batch, points)
+		queue.push(batch)
+		for {
+			overflow := queue.popOverflow(opts.BufferSize)
+			if overflow == nil {
+				break
+			}
+			spill.spill(overflow)
 		}
+	}
 
-		for _, p := range c.Points {
-			PutPointIntoGlobalPool(p)
-		}
+	sinks := []Sink{s}
+	if m, ok := s.(MultiSink); ok {
+		sinks = m
 	}
 
+	// sender delivers the queued batches. Once a batch could not be
+	// delivered, the remaining ones are tried only once on shutdown.
+	senderDone := make(chan struct{})
+	go func() {
+		failing := false
+		for {
+			batch, closed := queue.pop()
+			if batch == nil {
+				break
+			}
+			backoff := opts.Backoff
+			pending := sinks
+			for attempt := 0; ; attempt++ {
+				// retry only the sinks which failed, not to duplicate points
+				var err error
+				failed := make([]Sink, 0, len(pending))
+				for _, sink := range pending {
+					if e := sink.Write(batch); e != nil {
+						err = e
+						failed = append(failed, sink)
+					}
+				}
+				pending = failed
+				if err == nil {
+					spill.mutex.Lock()
+					stats.Sent += int64(len(batch))
+					spill.mutex.Unlock()
+					for _, p := range batch {
+						PutPointIntoGlobalPool(p)
+					}
+					failing = false
+					break
+				}
+				if attempt >= opts.MaxRetries || (closed && failing) {
+					log.Printf("telemetry error: giving up on %d points: %v", len(batch), err.Error())
+					spill.spill(batch)
+					failing = true
+					break
+				}
+				log.Printf("telemetry error: %v, retrying in %s", err.Error(), backoff)
+				time.Sleep(backoff)
+				backoff *= 2
+				if backoff > opts.MaxBackoff {
+					backoff = opts.MaxBackoff
+				}
+			}
+		}
+		close(senderDone)
+	}()
+
 	go func() {
 		var i uint64
+		points := make([]*Point, 0, batchSize)
 		for p := range src {
 			i++
 
@@ -40,18 +270,20 @@ func TelemetryRunAsync(c *Collector, batchSize uint64, writeToStderr bool, skipN
 				continue
 			}
 
-			c.Put(p)
+			points = append(points, p)
 
 			if i%batchSize == 0 {
-				send()
-				c.Reset()
+				enqueue(points)
+				points = points[:0]
 			}
 		}
-		if len(c.Points) > 0 {
-			send()
-			c.Reset()
+		if len(points) > 0 {
+			enqueue(points)
 		}
-		done <- struct{}{}
+		queue.close()
+		<-senderDone
+		spill.close()
+		done <- *stats
 	}()
 
 	return
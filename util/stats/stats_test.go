@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+func TestIncompleteBeta(t *testing.T) {
+	cases := []struct {
+		name     string
+		a, b, x  float64
+		expected float64
+	}{
+		{name: "x at 0", a: 2, b: 3, x: 0, expected: 0},
+		{name: "x below 0", a: 2, b: 3, x: -1, expected: 0},
+		{name: "x at 1", a: 2, b: 3, x: 1, expected: 1},
+		{name: "uniform", a: 1, b: 1, x: 0.3, expected: 0.3},
+		{name: "power", a: 3, b: 1, x: 0.6, expected: math.Pow(0.6, 3)},
+		{name: "one minus power", a: 1, b: 4, x: 0.2, expected: 1 - math.Pow(0.8, 4)},
+		{name: "symmetric at half", a: 7.5, b: 7.5, x: 0.5, expected: 0.5},
+		// I_x(2, 3) = sum over j = 2..4 of C(4, j) x^j (1-x)^(4-j)
+		{name: "binomial sum", a: 2, b: 3, x: 0.3, expected: 0.3483},
+		{name: "arcsine", a: 0.5, b: 0.5, x: 0.1, expected: 2 / math.Pi * math.Asin(math.Sqrt(0.1))},
+		{name: "arcsine upper tail", a: 0.5, b: 0.5, x: 0.95, expected: 2 / math.Pi * math.Asin(math.Sqrt(0.95))},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.InDelta(t, c.expected, incompleteBeta(c.a, c.b, c.x), 1e-10)
+		})
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	cases := []struct {
+		name     string
+		a, b     []float64
+		expected float64
+		ok       bool
+	}{
+		{
+			// t = -2.455, df = 24.99
+			name:     "different means",
+			a:        []float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4},
+			b:        []float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4},
+			expected: 0.021378,
+			ok:       true,
+		},
+		{
+			name:     "same means",
+			a:        []float64{1, 2, 3, 4},
+			b:        []float64{0, 2.5, 5},
+			expected: 1,
+			ok:       true,
+		},
+		{
+			// with as many samples and the same variance, df = 2 and
+			// p = 1 - |t| / sqrt(2 + t^2) with t = -sqrt(8)
+			name:     "two degrees of freedom",
+			a:        []float64{1, 2},
+			b:        []float64{3, 4},
+			expected: 1 - math.Sqrt(8)/math.Sqrt(10),
+			ok:       true,
+		},
+		{name: "single sample", a: []float64{1}, b: []float64{1, 2, 3}},
+		{name: "no sample", a: nil, b: []float64{1, 2, 3}},
+		{name: "no variance", a: []float64{2, 2, 2}, b: []float64{5, 5}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, ok := WelchTTest(c.a, c.b)
+			require.Equal(t, c.ok, ok)
+			if !ok {
+				return
+			}
+			require.InDelta(t, c.expected, p, 1e-6)
+			swapped, _ := WelchTTest(c.b, c.a)
+			require.InDelta(t, p, swapped, 1e-12)
+		})
+	}
+}
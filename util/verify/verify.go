@@ -0,0 +1,92 @@
+// Package verify compares the data loaded in a database with the counts of the
+// dataset manifests written by bulk_data_gen.
+package verify
+
+import (
+	"fmt"
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
+	"strings"
+	"time"
+)
+
+// Counts are the expected and loaded counts of a measurement. Loaded points
+// or values are -1 if not counted, Note then tells why.
+type Counts struct {
+	Expected common.MeasurementStats
+	Points   int64
+	Values   int64
+	Note     string
+}
+
+// Compare describes the difference between the loaded and expected counts,
+// "ok" if there is none.
+func (c *Counts) Compare() string {
+	var diffs []string
+	if c.Points >= 0 {
+		if d := c.Points - c.Expected.Points; d < 0 {
+			diffs = append(diffs, fmt.Sprintf("missing %d points", -d))
+		} else if d > 0 {
+			diffs = append(diffs, fmt.Sprintf("duplicated %d points", d))
+		}
+	}
+	if c.Values >= 0 {
+		if d := c.Values - c.Expected.Values; d < 0 {
+			diffs = append(diffs, fmt.Sprintf("missing %d values", -d))
+		} else if d > 0 {
+			diffs = append(diffs, fmt.Sprintf("duplicated %d values", d))
+		}
+	}
+	if len(diffs) == 0 {
+		return "ok"
+	}
+	return strings.Join(diffs, ", ")
+}
+
+// ReadManifests merges the measurement counts of the manifests of the
+// interleaved groups of a dataset and returns them with the number of points
+// of the whole dataset and its time range.
+func ReadManifests(paths []string) (expected map[string]*Counts, datasetPoints int64, start, end time.Time, err error) {
+	expected = make(map[string]*Counts)
+	for i, path := range paths {
+		m, err := common.ReadManifest(path)
+		if err != nil {
+			return nil, 0, start, end, err
+		}
+		for name, stats := range m.Measurements {
+			c, ok := expected[name]
+			if !ok {
+				c = &Counts{}
+				expected[name] = c
+			}
+			c.Expected.Points += stats.Points
+			c.Expected.Values += stats.Values
+		}
+		if i == 0 {
+			datasetPoints = m.Points
+			if start, end, err = ManifestRange(m); err != nil {
+				return nil, 0, start, end, fmt.Errorf("manifest %s: %v", path, err)
+			}
+		} else if m.Points != datasetPoints {
+			return nil, 0, start, end, fmt.Errorf("manifest %s is not of the same dataset: %d points instead of %d", path, m.Points, datasetPoints)
+		}
+	}
+	return expected, datasetPoints, start, end, nil
+}
+
+// ManifestRange returns the time range of a dataset, widened by the sampling
+// jitter.
+func ManifestRange(m *common.Manifest) (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, m.TimestampStart)
+	if err != nil {
+		return start, start, fmt.Errorf("invalid timestamp start: %v", err)
+	}
+	end, err := time.Parse(time.RFC3339, m.TimestampEnd)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid timestamp end: %v", err)
+	}
+	if jitter, err := time.ParseDuration(m.SamplingJitter); err == nil && jitter > 0 {
+		start = start.Add(-jitter)
+		end = end.Add(jitter)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,176 @@
+package verify
+
+import (
+	"github.com/influxdata/influxdb-comparisons/bulk_data_gen/common"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	expected := common.MeasurementStats{Points: 10, Values: 100}
+	cases := []struct {
+		name   string
+		points int64
+		values int64
+		status string
+	}{
+		{name: "ok", points: 10, values: 100, status: "ok"},
+		{name: "values not counted", points: 10, values: -1, status: "ok"},
+		{name: "points not counted", points: -1, values: -1, status: "ok"},
+		{name: "missing points", points: 7, values: -1, status: "missing 3 points"},
+		{name: "duplicated points", points: 12, values: -1, status: "duplicated 2 points"},
+		{name: "missing values", points: 10, values: 90, status: "missing 10 values"},
+		{name: "duplicated points and values", points: 11, values: 110, status: "duplicated 1 points, duplicated 10 values"},
+		{name: "nothing loaded", points: 0, values: 0, status: "missing 10 points, missing 100 values"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			counts := &Counts{Expected: expected, Points: c.points, Values: c.values}
+			require.Equal(t, c.status, counts.Compare())
+		})
+	}
+}
+
+func TestManifestRange(t *testing.T) {
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	cases := []struct {
+		name     string
+		manifest common.Manifest
+		start    time.Time
+		end      time.Time
+		err      string
+	}{
+		{
+			name:     "no jitter",
+			manifest: common.Manifest{TimestampStart: "2018-01-01T00:00:00Z", TimestampEnd: "2018-01-02T00:00:00Z", SamplingJitter: "0s"},
+			start:    start,
+			end:      end,
+		},
+		{
+			name:     "jitter",
+			manifest: common.Manifest{TimestampStart: "2018-01-01T00:00:00Z", TimestampEnd: "2018-01-02T00:00:00Z", SamplingJitter: "5s"},
+			start:    start.Add(-5 * time.Second),
+			end:      end.Add(5 * time.Second),
+		},
+		{
+			name:     "no sampling jitter",
+			manifest: common.Manifest{TimestampStart: "2018-01-01T00:00:00Z", TimestampEnd: "2018-01-02T00:00:00Z"},
+			start:    start,
+			end:      end,
+		},
+		{
+			name:     "invalid start",
+			manifest: common.Manifest{TimestampStart: "2018-01-01", TimestampEnd: "2018-01-02T00:00:00Z"},
+			err:      "invalid timestamp start",
+		},
+		{
+			name:     "invalid end",
+			manifest: common.Manifest{TimestampStart: "2018-01-01T00:00:00Z", TimestampEnd: "tomorrow"},
+			err:      "invalid timestamp end",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			start, end, err := ManifestRange(&c.manifest)
+			if c.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.start, start)
+			require.Equal(t, c.end, end)
+		})
+	}
+}
+
+func TestReadManifests(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	newManifest := func(name string, points int64, measurements map[string]*common.MeasurementStats) string {
+		m := &common.Manifest{
+			Version:        common.ManifestVersion,
+			TimestampStart: "2018-01-01T00:00:00Z",
+			TimestampEnd:   "2018-01-02T00:00:00Z",
+			SamplingJitter: "1s",
+			Points:         points,
+			Measurements:   measurements,
+		}
+		path := filepath.Join(dir, name)
+		require.NoError(t, m.WriteFile(path))
+		return path
+	}
+	group0 := newManifest("group0.json", 30, map[string]*common.MeasurementStats{
+		"cpu": {Points: 10, Values: 100},
+		"mem": {Points: 5, Values: 45},
+	})
+	group1 := newManifest("group1.json", 30, map[string]*common.MeasurementStats{
+		"cpu":  {Points: 10, Values: 100},
+		"disk": {Points: 5, Values: 35},
+	})
+	otherDataset := newManifest("other.json", 40, map[string]*common.MeasurementStats{
+		"cpu": {Points: 40, Values: 400},
+	})
+
+	start := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC).Add(-time.Second)
+	end := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC).Add(time.Second)
+	cases := []struct {
+		name     string
+		paths    []string
+		expected map[string]common.MeasurementStats
+		err      string
+	}{
+		{
+			name:  "single manifest",
+			paths: []string{group0},
+			expected: map[string]common.MeasurementStats{
+				"cpu": {Points: 10, Values: 100},
+				"mem": {Points: 5, Values: 45},
+			},
+		},
+		{
+			name:  "interleaved groups",
+			paths: []string{group0, group1},
+			expected: map[string]common.MeasurementStats{
+				"cpu":  {Points: 20, Values: 200},
+				"mem":  {Points: 5, Values: 45},
+				"disk": {Points: 5, Values: 35},
+			},
+		},
+		{
+			name:  "other dataset",
+			paths: []string{group0, otherDataset},
+			err:   "is not of the same dataset",
+		},
+		{
+			name:  "missing manifest",
+			paths: []string{filepath.Join(dir, "missing.json")},
+			err:   "no such file",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			counts, datasetPoints, s, e, err := ReadManifests(c.paths)
+			if c.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(30), datasetPoints)
+			require.Equal(t, start, s)
+			require.Equal(t, end, e)
+			require.Len(t, counts, len(c.expected))
+			for name, stats := range c.expected {
+				require.Equal(t, stats, counts[name].Expected, name)
+			}
+		})
+	}
+}